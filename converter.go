@@ -1,9 +1,8 @@
 package toolbox
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
-	"math"
 	"reflect"
 	"strconv"
 	"strings"
@@ -41,6 +40,8 @@ func AsString(input interface{}) string {
 		return *value
 	case []byte:
 		return string(value)
+	case json.Number:
+		return string(value)
 	case []interface{}:
 		if len(value) == 0 {
 			return ""
@@ -102,6 +103,8 @@ func ToFloat(value interface{}) (float64, error) {
 	switch actualValue := value.(type) {
 	case float64:
 		return actualValue, nil
+	case json.Number:
+		return actualValue.Float64()
 	case *float64:
 		if actualValue == nil {
 			return 0, nil
@@ -238,6 +241,12 @@ func toInt(value interface{}) (int, error) {
 		return int(actual), nil
 	case float64:
 		return int(actual), nil
+	case json.Number:
+		if intValue, err := actual.Int64(); err == nil {
+			return int(intValue), nil
+		}
+		floatValue, err := actual.Float64()
+		return int(floatValue), err
 	case bool:
 		if actual {
 			return 1, nil
@@ -265,89 +274,6 @@ func toInt(value interface{}) (int, error) {
 	return int(result), err
 }
 
-func unitToTime(timestamp int64) *time.Time {
-	var timeValue time.Time
-	if timestamp > math.MaxUint32 {
-		var timestampInMs = timestamp / 1000000
-		if timestampInMs > math.MaxUint32 {
-			timeValue = time.Unix(0, timestamp)
-		} else {
-			timeValue = time.Unix(0, timestamp*1000000)
-		}
-	} else {
-		timeValue = time.Unix(timestamp, 0)
-	}
-	return &timeValue
-}
-
-func textToTime(value, dateLayout string) (*time.Time, error) {
-	floatValue, err := ToFloat(value)
-	if err == nil {
-		return unitToTime(int64(floatValue)), nil
-	}
-	rawValue := value
-	timeValue, err := ParseTime(value, dateLayout)
-	if err != nil {
-		if dateLayout != "" {
-			updated := value
-			if len(value) > len(dateLayout) {
-				updated = string(value[:len(dateLayout)])
-			}
-			timeValue, err = ParseTime(updated, dateLayout)
-		}
-
-		if err != nil { //JSON default time format fallback
-			if timeValue, err = ParseTime(value, time.RFC3339); err == nil {
-				return &timeValue, err
-			}
-
-			if msIndex := strings.LastIndex(rawValue, "."); msIndex != -1 && msIndex < len(value)-1 {
-				ms := value[msIndex+1:]
-				i := 0
-				for ; i < len(ms); i++ {
-					if ms[i] < '0' || ms[i] > '9' {
-						break
-					}
-				}
-				msLayout := fmt.Sprintf("2006-01-02T15:04:05.%sZ07:00", strings.Repeat("9", i))
-				if timeValue, err = ParseTime(rawValue, msLayout); err == nil {
-					return &timeValue, err
-				}
-			}
-			return nil, err
-		}
-
-	}
-	return &timeValue, nil
-}
-
-// ToTime converts value to time, optionally uses layout if value if of string type
-func ToTime(value interface{}, dateLayout string) (*time.Time, error) {
-	if value == nil {
-		return nil, errors.New("values was empty")
-	}
-	switch actual := value.(type) {
-	case time.Time:
-		return &actual, nil
-	case *time.Time:
-		return actual, nil
-	case float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		var floatValue, _ = ToFloat(value)
-		return unitToTime(int64(floatValue)), nil
-	case *float32, *float64, *int, *int8, *int16, *int32, *int64, *uint, *uint8, *uint16, *uint32, *uint64:
-		actual = DereferenceValue(actual)
-		return ToTime(actual, dateLayout)
-	case string:
-		return textToTime(actual, dateLayout)
-	case map[string]interface{}:
-		if len(actual) == 0 {
-			return nil, nil
-		}
-	}
-	textValue := AsString(DereferenceValue(value))
-	return textToTime(textValue, dateLayout)
-}
-
 // AsTime converts an input to time, it takes time input,  dateLaout as parameters.
 func AsTime(value interface{}, dateLayout string) *time.Time {
 	result, err := ToTime(value, dateLayout)
@@ -612,26 +538,21 @@ func (c *Converter) assignConvertedStruct(target interface{}, inputMap map[strin
 				delete(defaultValueMap, fieldName)
 			}
 			previousLayout := c.DateLayout
-			if HasTimeLayout(mapping) {
-				c.DateLayout = GetTimeLayout(mapping)
-				c.DateLayout = previousLayout
+			if layout, err := TryGetTimeLayout(mapping); err == nil {
+				c.DateLayout = layout
+			} else if StrictTimeLayout && hasTimeLayoutSetting(mapping) {
+				return fmt.Errorf("failed to convert %v to %v due to %v", value, field, err)
 			}
 
+			var err error
 			if (!field.CanAddr()) && field.Kind() == reflect.Ptr {
-				if err := c.AssignConverted(field.Interface(), value); err != nil {
-					return fmt.Errorf("failed to convert %v to %v due to %v", value, field, err)
-				}
-
-			} else {
-				if value == nil {
-					continue
-				}
-				if err := c.AssignConverted(field.Addr().Interface(), value); err != nil {
-					return fmt.Errorf("failed to convert %v to %v due to %v", value, field, err)
-				}
+				err = c.AssignConverted(field.Interface(), value)
+			} else if value != nil {
+				err = c.AssignConverted(field.Addr().Interface(), value)
 			}
-			if HasTimeLayout(mapping) {
-				c.DateLayout = previousLayout
+			c.DateLayout = previousLayout
+			if err != nil {
+				return fmt.Errorf("failed to convert %v to %v due to %v", value, field, err)
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package toolbox_test
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/toolbox"
 	"reflect"
@@ -703,6 +704,25 @@ func TestConverter_AsInt(t *testing.T) {
 
 }
 
+func TestConverter_JSONNumber(t *testing.T) {
+	assert.Equal(t, 9007199254740993, toolbox.AsInt(json.Number("9007199254740993")))
+	assert.Equal(t, 3, toolbox.AsInt(json.Number("3.7")))
+	assert.Equal(t, 3.7, toolbox.AsFloat(json.Number("3.7")))
+	assert.Equal(t, "9007199254740993", toolbox.AsString(json.Number("9007199254740993")))
+
+	var aStruct = struct {
+		ID    int64
+		Ratio float64
+	}{}
+	err := toolbox.DefaultConverter.AssignConverted(&aStruct, map[string]interface{}{"ID": json.Number("9007199254740993"), "Ratio": json.Number("0.5")})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 9007199254740993, aStruct.ID)
+	assert.EqualValues(t, 0.5, aStruct.Ratio)
+
+	normalized := toolbox.NormalizeJSONNumbers(map[string]interface{}{"id": json.Number("9007199254740993"), "values": []interface{}{json.Number("1.5")}})
+	assert.EqualValues(t, map[string]interface{}{"id": int64(9007199254740993), "values": []interface{}{1.5}}, normalized)
+}
+
 func TestConvertedMapFromStruct(t *testing.T) {
 	var aStruct = struct {
 		ID          int    `json:"id"`
@@ -42,6 +42,9 @@ type Config struct {
 	AccountID string `json:",omitempty"`
 	Token     string `json:",omitempty"`
 	RoleARN   string `json:",omitempty"` //to asume role
+	//custom s3 compatible endpoint (i.e. MinIO) options
+	DisableSSL       bool `json:",omitempty"`
+	S3ForcePathStyle bool `json:",omitempty"`
 
 	//google cloud credential
 	ClientEmail             string `json:"client_email,omitempty"`
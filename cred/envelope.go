@@ -0,0 +1,174 @@
+package cred
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/viant/toolbox"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+//EnvelopeAlgorithm represents encrypted credential envelope algorithm
+const EnvelopeAlgorithm = "AES-256-GCM"
+
+//EnvKeySourcePrefix represents key source prefix reading key from environment variable, i.e. env:CREDENTIAL_KEY
+const EnvKeySourcePrefix = "env:"
+
+//ErrAuthenticationFailed represents envelope decryption failure due to wrong key or tampered ciphertext
+var ErrAuthenticationFailed = errors.New("credential authentication failed: invalid key or tampered ciphertext")
+
+//Envelope represents an encrypted credential file
+type Envelope struct {
+	Encrypted  bool   `json:"encrypted"`  //true for encrypted envelope
+	Algorithm  string `json:"algorithm"`  //encryption algorithm
+	Nonce      string `json:"nonce"`      //base64 GCM nonce
+	Ciphertext string `json:"ciphertext"` //base64 sealed config JSON
+}
+
+//IsEnvelope returns true if supplied data is an encrypted credential envelope
+func IsEnvelope(data []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return false
+	}
+	envelope := &Envelope{}
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return false
+	}
+	return envelope.Encrypted && envelope.Ciphertext != ""
+}
+
+//LoadKey loads 32 bytes AES-256 key from key source: env:NAME reads environment variable, otherwise key file path, key can be raw, base64 or hex encoded
+func LoadKey(keySource string) ([]byte, error) {
+	if keySource == "" {
+		return nil, fmt.Errorf("key source was empty")
+	}
+	var material []byte
+	if strings.HasPrefix(keySource, EnvKeySourcePrefix) {
+		name := keySource[len(EnvKeySourcePrefix):]
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("key environment variable %v was empty", name)
+		}
+		material = []byte(value)
+	} else {
+		var err error
+		if material, err = ioutil.ReadFile(keySource); err != nil {
+			return nil, fmt.Errorf("failed to read key file %v, %v", keySource, err)
+		}
+	}
+	if len(material) == 32 {
+		return material, nil
+	}
+	text := strings.TrimSpace(string(material))
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if len(text) == 32 {
+		return []byte(text), nil
+	}
+	return nil, fmt.Errorf("invalid key from %v: expected 32 bytes raw, base64 or hex encoded key", keySource)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//Encrypt returns JSON encrypted envelope for the config sealed with AES-256-GCM key
+func (c *Config) Encrypt(key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	var config = *c
+	if config.Password != "" {
+		config.EncryptedPassword = ""
+	}
+	plaintext, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	envelope := &Envelope{
+		Encrypted:  true,
+		Algorithm:  EnvelopeAlgorithm,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, []byte(EnvelopeAlgorithm))),
+	}
+	return json.Marshal(envelope)
+}
+
+//Decrypt loads config from JSON encrypted envelope, wrong key returns ErrAuthenticationFailed
+func (c *Config) Decrypt(data []byte, key []byte) error {
+	envelope := &Envelope{}
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return fmt.Errorf("failed to decode credential envelope, %v", err)
+	}
+	if envelope.Algorithm != "" && envelope.Algorithm != EnvelopeAlgorithm {
+		return fmt.Errorf("unsupported credential envelope algorithm: %v", envelope.Algorithm)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(envelope.Nonce)
+	if err != nil {
+		return fmt.Errorf("invalid credential envelope nonce, %v", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return fmt.Errorf("invalid credential envelope ciphertext, %v", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return ErrAuthenticationFailed
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(EnvelopeAlgorithm))
+	if err != nil {
+		return ErrAuthenticationFailed
+	}
+	return c.LoadFromReader(bytes.NewReader(plaintext), ".json")
+}
+
+//NewConfigWithKey create a new config for supplied file name, encrypted envelope is decrypted with key from key source
+func NewConfigWithKey(filename, keySource string) (*Config, error) {
+	reader, err := toolbox.OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEnvelope(data) {
+		return NewConfig(filename)
+	}
+	key, err := LoadKey(keySource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key for encrypted credential %v, %v", filename, err)
+	}
+	var config = &Config{}
+	if err = config.Decrypt(data, key); err != nil {
+		return nil, err
+	}
+	config.applyDefaultIfNeeded()
+	return config, nil
+}
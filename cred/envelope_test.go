@@ -0,0 +1,59 @@
+package cred_test
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/cred"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestConfig_Encrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	config := &cred.Config{Username: "adrian", Password: "abc"}
+	data, err := config.Encrypt(key)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, cred.IsEnvelope(data))
+	assert.NotContains(t, string(data), "adrian")
+
+	decrypted := &cred.Config{}
+	if assert.Nil(t, decrypted.Decrypt(data, key)) {
+		assert.Equal(t, "adrian", decrypted.Username)
+		assert.Equal(t, "abc", decrypted.Password)
+	}
+	wrongKey := []byte("fedcba9876543210fedcba9876543210")
+	err = (&cred.Config{}).Decrypt(data, wrongKey)
+	assert.Equal(t, cred.ErrAuthenticationFailed, err)
+	assert.False(t, cred.IsEnvelope([]byte(`{"Username":"adrian","Password":"abc"}`)))
+}
+
+func TestNewConfigWithKey(t *testing.T) {
+	parent := path.Join(os.TempDir(), "cred_envelope")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	key := []byte("0123456789abcdef0123456789abcdef")
+	keyFile := path.Join(parent, "key")
+	assert.Nil(t, ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+	_ = os.Setenv("CRED_ENVELOPE_TEST_KEY", string(key))
+	defer os.Unsetenv("CRED_ENVELOPE_TEST_KEY")
+
+	data, err := (&cred.Config{Username: "adrian", Password: "abc"}).Encrypt(key)
+	assert.Nil(t, err)
+	filename := path.Join(parent, "secret.json")
+	assert.Nil(t, ioutil.WriteFile(filename, data, 0600))
+	for _, keySource := range []string{keyFile, "env:CRED_ENVELOPE_TEST_KEY"} {
+		config, err := cred.NewConfigWithKey(filename, keySource)
+		if assert.Nil(t, err, keySource) {
+			assert.Equal(t, "adrian", config.Username)
+			assert.Equal(t, "abc", config.Password)
+		}
+	}
+	_, err = cred.NewConfigWithKey(filename, "")
+	assert.NotNil(t, err)
+	_, err = cred.NewConfigWithKey(filename, "env:CRED_ENVELOPE_MISSING_KEY")
+	assert.NotNil(t, err)
+}
@@ -255,3 +255,50 @@ func (d *flexYamlDecoder) Decode(target interface{}) error {
 func NewFlexYamlDecoderFactory() DecoderFactory {
 	return &flexYamlDecoderFactory{}
 }
+
+type propertiesDecoderFactory struct{}
+
+func (f propertiesDecoderFactory) Create(reader io.Reader) Decoder {
+	return &propertiesDecoder{Reader: reader}
+}
+
+type propertiesDecoder struct {
+	io.Reader
+}
+
+//Decode decodes java style properties (key=value or key:value, # and ! comments, \ line continuation) into target map or struct
+func (d *propertiesDecoder) Decode(target interface{}) error {
+	var data, err = ioutil.ReadAll(d.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to read data: %T %v", d.Reader, err)
+	}
+	var aMap = make(map[string]interface{})
+	var line = ""
+	for _, fragment := range strings.Split(string(data), "\n") {
+		fragment = strings.TrimLeft(strings.TrimRight(fragment, "\r"), " \t")
+		if line == "" && (fragment == "" || strings.HasPrefix(fragment, "#") || strings.HasPrefix(fragment, "!")) {
+			continue
+		}
+		if strings.HasSuffix(fragment, "\\") {
+			line += strings.TrimSuffix(fragment, "\\")
+			continue
+		}
+		line += fragment
+		index := strings.IndexAny(line, "=:")
+		if index == -1 {
+			aMap[strings.TrimSpace(line)] = ""
+		} else {
+			aMap[strings.TrimSpace(line[:index])] = strings.TrimSpace(line[index+1:])
+		}
+		line = ""
+	}
+	if line != "" {
+		return fmt.Errorf("failed to decode properties: unterminated line continuation: %v", line)
+	}
+	return DefaultConverter.AssignConverted(target, aMap)
+}
+
+//NewPropertiesDecoderFactory create a new java style properties decoder factory
+func NewPropertiesDecoderFactory() DecoderFactory {
+	return &propertiesDecoderFactory{}
+}
@@ -1,7 +1,13 @@
 package toolbox
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"time"
 )
 
@@ -14,6 +20,70 @@ type Iterator interface {
 	Next(itemPointer interface{}) error
 }
 
+//SafeIterator represents iterator which Next reports item pointer mismatch as an error instead of panicking, package iterators are safe.
+type SafeIterator interface {
+	//HasNext returns true if iterator has next element.
+	HasNext() bool
+
+	//Next sets item pointer with next element converted with DefaultConverter if needed, error names element and item pointer types.
+	Next(itemPointer interface{}) error
+
+	//safe marks package iterator implementations reporting item pointer mismatch as an error
+	safe()
+}
+
+type safeIterator struct {
+	Iterator
+}
+
+func (i *safeIterator) Next(itemPointer interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to set next element into %T item pointer, %v", itemPointer, r)
+		}
+	}()
+	return i.Iterator.Next(itemPointer)
+}
+
+func (i *safeIterator) safe() {}
+
+//AsSafeIterator returns package iterators as they are, other iterators are adapted to report Next panic as an error.
+func AsSafeIterator(iterator Iterator) SafeIterator {
+	if iterator == nil {
+		return nil
+	}
+	if result, ok := iterator.(SafeIterator); ok {
+		return result
+	}
+	return &safeIterator{Iterator: iterator}
+}
+
+//assignIteratorItem sets item pointer with value, value is converted with DefaultConverter if not assignable
+func assignIteratorItem(itemPointer interface{}, value interface{}) (err error) {
+	itemPointerValue := reflect.ValueOf(itemPointer)
+	if itemPointerValue.Kind() != reflect.Ptr || itemPointerValue.IsNil() {
+		return fmt.Errorf("unable to set %T element, unsupported item pointer type: %T", value, itemPointer)
+	}
+	target := itemPointerValue.Elem()
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	if source := reflect.ValueOf(value); source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to set %T element into %T item pointer, %v", value, itemPointer, r)
+		}
+	}()
+	if err = DefaultConverter.AssignConverted(itemPointer, value); err != nil {
+		return fmt.Errorf("unable to set %T element into %T item pointer, %v", value, itemPointer, err)
+	}
+	return nil
+}
+
 type sliceIterator struct {
 	sliceValue reflect.Value
 	index      int
@@ -24,13 +94,16 @@ func (i *sliceIterator) HasNext() bool {
 }
 
 func (i *sliceIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("slice iterator has no more elements")
+	}
 	value := i.sliceValue.Index(i.index)
 	i.index++
-	itemPointerValue := reflect.ValueOf(itemPointer)
-	itemPointerValue.Elem().Set(value)
-	return nil
+	return assignIteratorItem(itemPointer, value.Interface())
 }
 
+func (i *sliceIterator) safe() {}
+
 type stringSliceIterator struct {
 	sliceValue []string
 	index      int
@@ -41,17 +114,20 @@ func (i *stringSliceIterator) HasNext() bool {
 }
 
 func (i *stringSliceIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("slice iterator has no more elements")
+	}
 	value := i.sliceValue[i.index]
 	i.index++
 	if stringPointer, ok := itemPointer.(*string); ok {
 		*stringPointer = value
 		return nil
 	}
-	interfacePointer := itemPointer.(*interface{})
-	*interfacePointer = value
-	return nil
+	return assignIteratorItem(itemPointer, value)
 }
 
+func (i *stringSliceIterator) safe() {}
+
 type interfaceSliceIterator struct {
 	sliceValue []interface{}
 	index      int
@@ -62,9 +138,18 @@ func (i *interfaceSliceIterator) HasNext() bool {
 }
 
 func (i *interfaceSliceIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("slice iterator has no more elements")
+	}
 	value := i.sliceValue[i.index]
 	i.index++
+	return setIteratorItem(itemPointer, value)
+}
 
+func (i *interfaceSliceIterator) safe() {}
+
+//setIteratorItem sets item pointer with value, converting it for common item pointer types
+func setIteratorItem(itemPointer interface{}, value interface{}) error {
 	switch actual := itemPointer.(type) {
 	case *interface{}:
 		*actual = value
@@ -86,14 +171,7 @@ func (i *interfaceSliceIterator) Next(itemPointer interface{}) error {
 		return nil
 
 	}
-	itemPointerValue := reflect.ValueOf(itemPointer)
-	if value != nil {
-		itemPointerValue.Elem().Set(reflect.ValueOf(value))
-	} else {
-		itemPointerValue.Elem().Set(reflect.Zero(reflect.TypeOf(itemPointer).Elem()))
-
-	}
-	return nil
+	return assignIteratorItem(itemPointer, value)
 }
 
 //NewSliceIterator creates a new slice iterator.
@@ -107,3 +185,874 @@ func NewSliceIterator(slice interface{}) Iterator {
 	sliceValue := DiscoverValueByKind(reflect.ValueOf(slice), reflect.Slice)
 	return &sliceIterator{sliceValue: sliceValue}
 }
+
+type reverseSliceIterator struct {
+	sliceValue reflect.Value
+	index      int
+}
+
+func (i *reverseSliceIterator) HasNext() bool {
+	return i.index >= 0
+}
+
+func (i *reverseSliceIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("slice iterator has no more elements")
+	}
+	value := i.sliceValue.Index(i.index)
+	i.index--
+	return assignIteratorItem(itemPointer, value.Interface())
+}
+
+func (i *reverseSliceIterator) safe() {}
+
+//NewReverseSliceIterator creates a new slice iterator iterating from the last to the first element.
+func NewReverseSliceIterator(slice interface{}) Iterator {
+	sliceValue := DiscoverValueByKind(reflect.ValueOf(slice), reflect.Slice)
+	return &reverseSliceIterator{sliceValue: sliceValue, index: sliceValue.Len() - 1}
+}
+
+type rangeIterator struct {
+	next int
+	to   int
+	step int
+}
+
+func (i *rangeIterator) HasNext() bool {
+	if i.step > 0 {
+		return i.next < i.to
+	}
+	return i.step < 0 && i.next > i.to
+}
+
+func (i *rangeIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("range iterator has no more elements")
+	}
+	value := i.next
+	i.next += i.step
+	return assignIteratorItem(itemPointer, value)
+}
+
+func (i *rangeIterator) safe() {}
+
+//NewRangeIterator creates a new iterator of ints from inclusive from to exclusive to, negative step counts down, range is empty if it is inverted relative to step or step is zero.
+func NewRangeIterator(from, to, step int) Iterator {
+	return &rangeIterator{next: from, to: to, step: step}
+}
+
+type channelIterator struct {
+	ctx        context.Context
+	channel    reflect.Value
+	anyChannel chan interface{}
+	pending    interface{}
+	hasPending bool
+	exhausted  bool
+	err        error
+}
+
+//HasNext blocks until channel value is received, channel is closed or context is canceled
+func (i *channelIterator) HasNext() bool {
+	if i.hasPending {
+		return true
+	}
+	if i.exhausted {
+		return false
+	}
+	if i.anyChannel != nil {
+		select {
+		case value, ok := <-i.anyChannel:
+			i.receive(value, ok)
+		case <-i.ctx.Done():
+			i.cancel()
+		}
+		return i.hasPending
+	}
+	var cases = []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: i.channel}}
+	if done := i.ctx.Done(); done != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+	}
+	chosen, value, ok := reflect.Select(cases)
+	if chosen > 0 {
+		i.cancel()
+		return false
+	}
+	if ok {
+		i.receive(value.Interface(), ok)
+	} else {
+		i.receive(nil, ok)
+	}
+	return i.hasPending
+}
+
+func (i *channelIterator) receive(value interface{}, ok bool) {
+	if !ok {
+		i.exhausted = true
+		return
+	}
+	i.pending = value
+	i.hasPending = true
+}
+
+func (i *channelIterator) cancel() {
+	i.exhausted = true
+	i.err = i.ctx.Err()
+}
+
+//Next sets item pointer with value received by HasNext
+func (i *channelIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		if i.err != nil {
+			return fmt.Errorf("channel iterator canceled, %v", i.err)
+		}
+		return fmt.Errorf("channel iterator has no more elements")
+	}
+	value := i.pending
+	i.pending = nil
+	i.hasPending = false
+	return setIteratorItem(itemPointer, value)
+}
+
+func (i *channelIterator) safe() {}
+
+//NewChannelIterator creates a new channel iterator for any receive channel, iterator is exhausted once channel is closed
+func NewChannelIterator(channel interface{}) Iterator {
+	return NewChannelIteratorWithContext(context.Background(), channel)
+}
+
+//NewChannelIteratorWithContext creates a new channel iterator, context cancellation unblocks HasNext and exhausts iterator
+func NewChannelIteratorWithContext(ctx context.Context, channel interface{}) Iterator {
+	if anyChannel, ok := channel.(chan interface{}); ok {
+		return &channelIterator{ctx: ctx, anyChannel: anyChannel}
+	}
+	channelValue := DiscoverValueByKind(reflect.ValueOf(channel), reflect.Chan)
+	if channelValue.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic(fmt.Sprintf("unsupported send only channel %T", channel))
+	}
+	return &channelIterator{ctx: ctx, channel: channelValue}
+}
+
+//MapEntry represents map iterator key value pair
+type MapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+type mapIterator struct {
+	mapValue reflect.Value
+	keys     []reflect.Value
+	index    int
+}
+
+func (i *mapIterator) HasNext() bool {
+	return i.index < len(i.keys)
+}
+
+//Next sets *MapEntry or pointer to a struct with Key and Value fields with next map entry
+func (i *mapIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("map iterator has no more entries")
+	}
+	key := i.keys[i.index]
+	value := i.mapValue.MapIndex(key)
+	i.index++
+	if entry, ok := itemPointer.(*MapEntry); ok {
+		entry.Key = key.Interface()
+		entry.Value = value.Interface()
+		return nil
+	}
+	itemPointerValue := reflect.ValueOf(itemPointer)
+	if itemPointerValue.Kind() != reflect.Ptr || itemPointerValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unable to set %v entry into %T item pointer, expected *MapEntry or pointer to struct with Key and Value fields", i.mapValue.Type(), itemPointer)
+	}
+	structValue := itemPointerValue.Elem()
+	if err := setMapEntryField(structValue, "Key", key); err != nil {
+		return err
+	}
+	return setMapEntryField(structValue, "Value", value)
+}
+
+func (i *mapIterator) safe() {}
+
+func setMapEntryField(structValue reflect.Value, name string, value reflect.Value) error {
+	field := structValue.FieldByName(name)
+	if !field.IsValid() || !field.CanSet() {
+		return fmt.Errorf("unsupported map iterator item %v, missing exported %v field", structValue.Type(), name)
+	}
+	if value.Kind() == reflect.Interface && field.Kind() != reflect.Interface {
+		if value.IsNil() {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Type().AssignableTo(field.Type()) {
+		field.Set(value)
+		return nil
+	}
+	if value.Type().ConvertibleTo(field.Type()) {
+		field.Set(value.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("unable to set %v.%v %v with %v", structValue.Type(), name, field.Type(), value.Type())
+}
+
+//NewMapIterator creates a new map iterator, if sorted is true keys are iterated in natural order for strings and numbers, fmt.Sprint order otherwise
+func NewMapIterator(aMap interface{}, sorted bool) Iterator {
+	if aMap == nil {
+		return &mapIterator{}
+	}
+	mapValue := DiscoverValueByKind(reflect.ValueOf(aMap), reflect.Map)
+	keys := mapValue.MapKeys()
+	if sorted {
+		sort.SliceStable(keys, func(i, j int) bool {
+			return lessMapKey(keys[i], keys[j])
+		})
+	}
+	return &mapIterator{mapValue: mapValue, keys: keys}
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	return isIntKind(kind) || isUintKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func lessMapKey(left, right reflect.Value) bool {
+	if left.Kind() == reflect.Interface && !left.IsNil() {
+		left = left.Elem()
+	}
+	if right.Kind() == reflect.Interface && !right.IsNil() {
+		right = right.Elem()
+	}
+	leftKind, rightKind := left.Kind(), right.Kind()
+	switch {
+	case leftKind == reflect.String && rightKind == reflect.String:
+		return left.String() < right.String()
+	case isIntKind(leftKind) && isIntKind(rightKind):
+		return left.Int() < right.Int()
+	case isUintKind(leftKind) && isUintKind(rightKind):
+		return left.Uint() < right.Uint()
+	case isNumericKind(leftKind) && isNumericKind(rightKind):
+		return AsFloat(left.Interface()) < AsFloat(right.Interface())
+	}
+	return fmt.Sprint(left.Interface()) < fmt.Sprint(right.Interface())
+}
+
+//BatchIterator represents iterator delivering source elements in batches.
+type BatchIterator interface {
+	//HasNext returns true if source has any remaining element.
+	HasNext() bool
+
+	//Next sets slice pointer with next batch, see NextBatch.
+	Next(slicePointer interface{}) error
+
+	//NextBatch resets slice pointer length and appends up to batch size converted elements, it returns number of appended elements.
+	NextBatch(slicePointer interface{}) (int, error)
+}
+
+type batchingIterator struct {
+	source    SafeIterator
+	batchSize int
+}
+
+func (i *batchingIterator) HasNext() bool {
+	return i.source.HasNext()
+}
+
+func (i *batchingIterator) Next(slicePointer interface{}) error {
+	_, err := i.NextBatch(slicePointer)
+	return err
+}
+
+func (i *batchingIterator) safe() {}
+
+func (i *batchingIterator) NextBatch(slicePointer interface{}) (int, error) {
+	if i.batchSize < 1 {
+		return 0, fmt.Errorf("invalid batch size: %v", i.batchSize)
+	}
+	slicePointerValue := reflect.ValueOf(slicePointer)
+	if slicePointerValue.Kind() != reflect.Ptr || slicePointerValue.IsNil() || slicePointerValue.Elem().Kind() != reflect.Slice {
+		return 0, fmt.Errorf("unsupported batch pointer type: %T, expected pointer to slice", slicePointer)
+	}
+	if !i.HasNext() {
+		return 0, fmt.Errorf("batching iterator has no more elements")
+	}
+	sliceValue := slicePointerValue.Elem()
+	batch := sliceValue.Slice(0, 0)
+	elementType := sliceValue.Type().Elem()
+	var err error
+	for batch.Len() < i.batchSize && i.source.HasNext() {
+		element := reflect.New(elementType)
+		if err = i.source.Next(element.Interface()); err != nil {
+			break
+		}
+		batch = reflect.Append(batch, element.Elem())
+	}
+	sliceValue.Set(batch)
+	return batch.Len(), err
+}
+
+//NewBatchingIterator creates a new iterator delivering source elements in batches of up to batchSize elements, the last batch can be shorter.
+func NewBatchingIterator(source Iterator, batchSize int) BatchIterator {
+	return &batchingIterator{source: AsSafeIterator(source), batchSize: batchSize}
+}
+
+//PeekableIterator represents iterator with one element lookahead.
+type PeekableIterator interface {
+	Iterator
+
+	//Peek sets item pointer with next element without consuming it, it returns false if there is no next element, source failed or element can not be converted.
+	Peek(itemPointer interface{}) bool
+}
+
+type peekableIterator struct {
+	source     SafeIterator
+	pending    interface{}
+	hasPending bool
+	err        error
+}
+
+//HasNext reads ahead and buffers the next source element
+func (i *peekableIterator) HasNext() bool {
+	if i.hasPending || i.err != nil {
+		return true
+	}
+	if !i.source.HasNext() {
+		return false
+	}
+	var item interface{}
+	if i.err = i.source.Next(&item); i.err != nil {
+		return true
+	}
+	i.pending = item
+	i.hasPending = true
+	return true
+}
+
+func (i *peekableIterator) Peek(itemPointer interface{}) bool {
+	if !i.HasNext() || i.err != nil {
+		return false
+	}
+	return assignIteratorItem(itemPointer, i.pending) == nil
+}
+
+func (i *peekableIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("iterator has no more elements")
+	}
+	if err := i.err; err != nil {
+		i.err = nil
+		return err
+	}
+	item := i.pending
+	i.pending = nil
+	i.hasPending = false
+	return assignIteratorItem(itemPointer, item)
+}
+
+func (i *peekableIterator) safe() {}
+
+//NewPeekableIterator creates a new iterator with Peek lookahead.
+func NewPeekableIterator(source Iterator) PeekableIterator {
+	if peekable, ok := source.(*peekableIterator); ok {
+		return peekable
+	}
+	return &peekableIterator{source: AsSafeIterator(source)}
+}
+
+type filteredIterator struct {
+	source    PeekableIterator
+	predicate func(item interface{}) bool
+	accepted  bool
+}
+
+//HasNext peeks source elements and skips these rejected by predicate
+func (i *filteredIterator) HasNext() bool {
+	if i.accepted {
+		return true
+	}
+	for i.source.HasNext() {
+		var item interface{}
+		if !i.source.Peek(&item) {
+			return true //source error is returned by Next
+		}
+		if i.predicate(item) {
+			i.accepted = true
+			return true
+		}
+		_ = i.source.Next(&item)
+	}
+	return false
+}
+
+func (i *filteredIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("filtered iterator has no more elements")
+	}
+	i.accepted = false
+	return i.source.Next(itemPointer)
+}
+
+func (i *filteredIterator) safe() {}
+
+//NewFilteredIterator creates a new iterator of source elements accepted by predicate.
+func NewFilteredIterator(source Iterator, predicate func(item interface{}) bool) Iterator {
+	return &filteredIterator{source: NewPeekableIterator(source), predicate: predicate}
+}
+
+type transformingIterator struct {
+	source    SafeIterator
+	transform func(item interface{}) (interface{}, error)
+}
+
+func (i *transformingIterator) HasNext() bool {
+	return i.source.HasNext()
+}
+
+func (i *transformingIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("transforming iterator has no more elements")
+	}
+	var item interface{}
+	if err := i.source.Next(&item); err != nil {
+		return err
+	}
+	transformed, err := i.transform(item)
+	if err != nil {
+		return err
+	}
+	return assignIteratorItem(itemPointer, transformed)
+}
+
+func (i *transformingIterator) safe() {}
+
+//NewTransformingIterator creates a new iterator of transformed source elements, transform error is returned by Next.
+func NewTransformingIterator(source Iterator, transform func(item interface{}) (interface{}, error)) Iterator {
+	return &transformingIterator{source: AsSafeIterator(source), transform: transform}
+}
+
+//LineIterator represents reader line iterator.
+type LineIterator interface {
+	Iterator
+
+	//LastError returns read error other than io.EOF that ended iteration.
+	LastError() error
+}
+
+type lineIterator struct {
+	reader     *bufio.Reader
+	pending    []byte
+	hasPending bool
+	done       bool
+	err        error
+}
+
+//HasNext reads ahead the next line, read error ends iteration and is kept as LastError
+func (i *lineIterator) HasNext() bool {
+	if i.hasPending {
+		return true
+	}
+	if i.done {
+		return false
+	}
+	line, err := i.reader.ReadBytes('\n')
+	if err != nil {
+		i.done = true
+		if err != io.EOF {
+			i.err = err
+		}
+	}
+	if len(line) == 0 {
+		return false
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	i.pending = bytes.TrimSuffix(line, []byte("\r"))
+	i.hasPending = true
+	return true
+}
+
+//Next sets *string, *[]byte or other converted item pointer with next line without line terminator
+func (i *lineIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		if i.err != nil {
+			return i.err
+		}
+		return fmt.Errorf("line iterator has no more lines")
+	}
+	line := i.pending
+	i.pending = nil
+	i.hasPending = false
+	switch actual := itemPointer.(type) {
+	case *[]byte:
+		*actual = line
+		return nil
+	case *string:
+		*actual = string(line)
+		return nil
+	}
+	return assignIteratorItem(itemPointer, string(line))
+}
+
+func (i *lineIterator) safe() {}
+
+func (i *lineIterator) LastError() error {
+	return i.err
+}
+
+//NewLineIterator creates a new iterator of reader lines terminated by \n or \r\n, line length is not limited.
+func NewLineIterator(reader io.Reader) LineIterator {
+	return &lineIterator{reader: bufio.NewReader(reader)}
+}
+
+type chainedIterator struct {
+	iterators []SafeIterator
+	index     int
+}
+
+//HasNext skips drained iterators
+func (i *chainedIterator) HasNext() bool {
+	for ; i.index < len(i.iterators); i.index++ {
+		if i.iterators[i.index].HasNext() {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *chainedIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("chained iterator has no more elements")
+	}
+	return i.iterators[i.index].Next(itemPointer)
+}
+
+func (i *chainedIterator) safe() {}
+
+//NewChainedIterator creates a new iterator draining iterators in order.
+func NewChainedIterator(iterators ...Iterator) Iterator {
+	var result = &chainedIterator{iterators: make([]SafeIterator, 0, len(iterators))}
+	for _, iterator := range iterators {
+		if iterator != nil {
+			result.iterators = append(result.iterators, AsSafeIterator(iterator))
+		}
+	}
+	return result
+}
+
+type flatteningIterator struct {
+	stack      []SafeIterator
+	depth      int
+	pending    interface{}
+	hasPending bool
+	err        error
+}
+
+//HasNext reads ahead the next element, slice and Iterator elements are expanded up to iterator depth
+func (i *flatteningIterator) HasNext() bool {
+	if i.hasPending || i.err != nil {
+		return true
+	}
+	for len(i.stack) > 0 {
+		current := i.stack[len(i.stack)-1]
+		if !current.HasNext() {
+			i.stack = i.stack[:len(i.stack)-1]
+			continue
+		}
+		var item interface{}
+		if i.err = current.Next(&item); i.err != nil {
+			return true
+		}
+		if len(i.stack) <= i.depth {
+			if nested := flattenedIterator(item); nested != nil {
+				i.stack = append(i.stack, nested)
+				continue
+			}
+		}
+		i.pending = item
+		i.hasPending = true
+		return true
+	}
+	return false
+}
+
+func (i *flatteningIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("flattening iterator has no more elements")
+	}
+	if err := i.err; err != nil {
+		i.err = nil
+		return err
+	}
+	item := i.pending
+	i.pending = nil
+	i.hasPending = false
+	return assignIteratorItem(itemPointer, item)
+}
+
+func (i *flatteningIterator) safe() {}
+
+//flattenedIterator returns iterator for Iterator or slice other than []byte item, nil otherwise
+func flattenedIterator(item interface{}) SafeIterator {
+	switch actual := item.(type) {
+	case nil, []byte:
+		return nil
+	case Iterator:
+		return AsSafeIterator(actual)
+	}
+	if reflect.TypeOf(item).Kind() == reflect.Slice {
+		return AsSafeIterator(NewSliceIterator(item))
+	}
+	return nil
+}
+
+//NewFlatteningIterator creates a new iterator expanding source slice and Iterator elements inline, nested elements are not expanded.
+func NewFlatteningIterator(source Iterator) Iterator {
+	return NewFlatteningIteratorWithDepth(source, 1)
+}
+
+//NewFlatteningIteratorWithDepth creates a new iterator expanding source slice and Iterator elements inline up to depth nesting levels.
+func NewFlatteningIteratorWithDepth(source Iterator, depth int) Iterator {
+	return &flatteningIterator{stack: []SafeIterator{AsSafeIterator(source)}, depth: depth}
+}
+
+//IteratorToSlice appends all remaining iterator elements to target slice pointer, elements are converted to slice element type.
+func IteratorToSlice(iterator Iterator, targetSlicePointer interface{}) error {
+	targetValue := reflect.ValueOf(targetSlicePointer)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported target type: %T, expected pointer to slice", targetSlicePointer)
+	}
+	sliceValue := targetValue.Elem()
+	elementType := sliceValue.Type().Elem()
+	safeIterator := AsSafeIterator(iterator)
+	for index := 0; safeIterator.HasNext(); index++ {
+		element := reflect.New(elementType)
+		if err := safeIterator.Next(element.Interface()); err != nil {
+			return fmt.Errorf("failed to collect element at index %v, %v", index, err)
+		}
+		sliceValue.Set(reflect.Append(sliceValue, element.Elem()))
+	}
+	return nil
+}
+
+//IteratorToMap puts all remaining iterator elements into target map pointer under keyFunc key, keys and elements are converted to map key and element types.
+func IteratorToMap(iterator Iterator, keyFunc func(item interface{}) interface{}, targetMapPointer interface{}) error {
+	targetValue := reflect.ValueOf(targetMapPointer)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("unsupported target type: %T, expected pointer to map", targetMapPointer)
+	}
+	mapValue := targetValue.Elem()
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapValue.Type()))
+	}
+	safeIterator := AsSafeIterator(iterator)
+	for index := 0; safeIterator.HasNext(); index++ {
+		var item interface{}
+		if err := safeIterator.Next(&item); err != nil {
+			return fmt.Errorf("failed to collect element at index %v, %v", index, err)
+		}
+		key := reflect.New(mapValue.Type().Key())
+		if err := assignIteratorItem(key.Interface(), keyFunc(item)); err != nil {
+			return fmt.Errorf("failed to collect element key at index %v, %v", index, err)
+		}
+		value := reflect.New(mapValue.Type().Elem())
+		if err := assignIteratorItem(value.Interface(), item); err != nil {
+			return fmt.Errorf("failed to collect element at index %v, %v", index, err)
+		}
+		mapValue.SetMapIndex(key.Elem(), value.Elem())
+	}
+	return nil
+}
+
+//IteratorCount drains iterator and returns number of remaining elements.
+func IteratorCount(iterator Iterator) int {
+	var result = 0
+	safeIterator := AsSafeIterator(iterator)
+	for ; safeIterator.HasNext(); result++ {
+		var item interface{}
+		_ = safeIterator.Next(&item)
+	}
+	return result
+}
+
+type fetchedPage struct {
+	items     []interface{}
+	nextToken string
+	err       error
+}
+
+type pagedIterator struct {
+	fetch      func(pageToken string) (items []interface{}, nextToken string, err error)
+	prefetch   bool
+	prefetched chan *fetchedPage
+	items      []interface{}
+	index      int
+	nextToken  string
+	done       bool
+	err        error
+}
+
+//HasNext fetches pages until one has elements or page token is empty, fetch error is returned by Next
+func (i *pagedIterator) HasNext() bool {
+	for {
+		if i.err != nil || i.index < len(i.items) {
+			return true
+		}
+		if i.done {
+			return false
+		}
+		i.load()
+	}
+}
+
+func (i *pagedIterator) load() {
+	var next *fetchedPage
+	if i.prefetched != nil {
+		next = <-i.prefetched
+		i.prefetched = nil
+	} else {
+		next = i.fetchPage(i.nextToken)
+	}
+	i.items, i.index = nil, 0
+	if next.err != nil {
+		i.err = next.err
+		i.done = true
+		return
+	}
+	i.items = next.items
+	i.nextToken = next.nextToken
+	if i.nextToken == "" {
+		i.done = true
+		return
+	}
+	if i.prefetch {
+		i.prefetched = make(chan *fetchedPage, 1)
+		go func(prefetched chan *fetchedPage, token string) {
+			prefetched <- i.fetchPage(token)
+		}(i.prefetched, i.nextToken)
+	}
+}
+
+func (i *pagedIterator) fetchPage(token string) *fetchedPage {
+	items, nextToken, err := i.fetch(token)
+	if err != nil {
+		err = fmt.Errorf("failed to fetch page %q, %v", token, err)
+	}
+	return &fetchedPage{items: items, nextToken: nextToken, err: err}
+}
+
+func (i *pagedIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("paged iterator has no more elements")
+	}
+	if err := i.err; err != nil {
+		i.err = nil
+		return err
+	}
+	item := i.items[i.index]
+	i.index++
+	return assignIteratorItem(itemPointer, item)
+}
+
+func (i *pagedIterator) safe() {}
+
+//NewPagedIterator creates a new iterator lazily fetching the next page when the current one is exhausted, the first page is fetched with empty page token, empty next token ends iteration.
+func NewPagedIterator(fetch func(pageToken string) (items []interface{}, nextToken string, err error)) Iterator {
+	return &pagedIterator{fetch: fetch}
+}
+
+//NewPagedIteratorWithPrefetch creates a new paged iterator fetching the next page concurrently while the current one is consumed.
+func NewPagedIteratorWithPrefetch(fetch func(pageToken string) (items []interface{}, nextToken string, err error)) Iterator {
+	return &pagedIterator{fetch: fetch, prefetch: true}
+}
+
+//FieldEntry represents struct field iterator entry
+type FieldEntry struct {
+	Name  string
+	Tag   reflect.StructTag
+	Value interface{}
+}
+
+type structFieldIterator struct {
+	entries []*FieldEntry
+	index   int
+	err     error
+}
+
+func (i *structFieldIterator) HasNext() bool {
+	return i.err != nil || i.index < len(i.entries)
+}
+
+//Next sets *FieldEntry, **FieldEntry or *interface{} item pointer with next field entry
+func (i *structFieldIterator) Next(itemPointer interface{}) error {
+	if err := i.err; err != nil {
+		i.err = nil
+		return err
+	}
+	if !i.HasNext() {
+		return fmt.Errorf("struct field iterator has no more fields")
+	}
+	entry := i.entries[i.index]
+	i.index++
+	if actual, ok := itemPointer.(*FieldEntry); ok {
+		*actual = *entry
+		return nil
+	}
+	return assignIteratorItem(itemPointer, entry)
+}
+
+func (i *structFieldIterator) safe() {}
+
+//NewStructFieldIterator creates a new iterator of struct fields processed by ProcessStruct, nil pointer fields are included with nil Value.
+func NewStructFieldIterator(aStruct interface{}) Iterator {
+	return NewStructFieldIteratorWithNilPointers(aStruct, true)
+}
+
+//NewStructFieldIteratorWithNilPointers creates a new iterator of struct fields processed by ProcessStruct, fields are ordered by declaration
+//with anonymous struct fields flattened at embedding position, nil pointer fields are included with nil Value or skipped.
+func NewStructFieldIteratorWithNilPointers(aStruct interface{}, includeNilPointers bool) Iterator {
+	var result = &structFieldIterator{entries: make([]*FieldEntry, 0)}
+	var indexes = make(map[string][]int)
+	structType := DereferenceType(aStruct)
+	result.err = ProcessStruct(aStruct, func(fieldType reflect.StructField, field reflect.Value) error {
+		if !field.CanInterface() {
+			return nil
+		}
+		entry := &FieldEntry{Name: fieldType.Name, Tag: fieldType.Tag}
+		if field.Kind() == reflect.Ptr && field.IsNil() {
+			if !includeNilPointers {
+				return nil
+			}
+		} else {
+			entry.Value = field.Interface()
+		}
+		if declared, ok := structType.FieldByName(fieldType.Name); ok {
+			indexes[entry.Name] = declared.Index
+		}
+		result.entries = append(result.entries, entry)
+		return nil
+	})
+	sort.SliceStable(result.entries, func(i, j int) bool {
+		return lessFieldIndex(indexes[result.entries[i].Name], indexes[result.entries[j].Name])
+	})
+	return result
+}
+
+//lessFieldIndex compares field index paths, fields without index path are ordered last
+func lessFieldIndex(left, right []int) bool {
+	if len(left) == 0 || len(right) == 0 {
+		return len(right) == 0 && len(left) > 0
+	}
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if left[i] != right[i] {
+			return left[i] < right[i]
+		}
+	}
+	return len(left) < len(right)
+}
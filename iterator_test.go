@@ -1,7 +1,13 @@
 package toolbox_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/toolbox"
@@ -98,3 +104,670 @@ func TestSliceIterator(t *testing.T) {
 	}
 
 }
+
+func TestMapIterator(t *testing.T) {
+	{
+		iterator := toolbox.NewMapIterator(map[string]interface{}{}, true)
+		assert.False(t, iterator.HasNext())
+		iterator = toolbox.NewMapIterator(nil, true)
+		assert.False(t, iterator.HasNext())
+	}
+	{
+		iterator := toolbox.NewMapIterator(map[int]string{10: "j", 2: "b", -1: "z", 3: "c"}, true)
+		var keys = make([]interface{}, 0)
+		var values = make([]interface{}, 0)
+		for iterator.HasNext() {
+			entry := &toolbox.MapEntry{}
+			assert.Nil(t, iterator.Next(entry))
+			keys = append(keys, entry.Key)
+			values = append(values, entry.Value)
+		}
+		assert.EqualValues(t, []interface{}{-1, 2, 3, 10}, keys)
+		assert.EqualValues(t, []interface{}{"z", "b", "c", "j"}, values)
+	}
+	{
+		aMap := map[interface{}]interface{}{"name": "abc", 2: "two", 1.5: "one and half", "id": 1, true: "yes"}
+		iterator := toolbox.NewMapIterator(aMap, true)
+		var keys = make([]interface{}, 0)
+		for iterator.HasNext() {
+			entry := &toolbox.MapEntry{}
+			assert.Nil(t, iterator.Next(entry))
+			keys = append(keys, entry.Key)
+			assert.Equal(t, aMap[entry.Key], entry.Value)
+		}
+		assert.EqualValues(t, []interface{}{1.5, 2, "id", "name", true}, keys)
+	}
+	{
+		type pair struct {
+			Key   string
+			Value int
+		}
+		iterator := toolbox.NewMapIterator(map[string]interface{}{"b": 2, "a": 1}, true)
+		var pairs = make([]pair, 0)
+		for iterator.HasNext() {
+			item := pair{}
+			assert.Nil(t, iterator.Next(&item))
+			pairs = append(pairs, item)
+		}
+		assert.EqualValues(t, []pair{{"a", 1}, {"b", 2}}, pairs)
+
+		iterator = toolbox.NewMapIterator(map[string]int{"a": 1}, false)
+		var value string
+		assert.NotNil(t, iterator.Next(&value))
+	}
+}
+
+func TestChannelIterator(t *testing.T) {
+	{
+		channel := make(chan interface{})
+		close(channel)
+		iterator := toolbox.NewChannelIterator(channel)
+		assert.False(t, iterator.HasNext())
+		var value interface{}
+		assert.NotNil(t, iterator.Next(&value))
+	}
+	{
+		channel := make(chan interface{}, 3)
+		go func() {
+			channel <- "a"
+			channel <- 2
+			close(channel)
+		}()
+		iterator := toolbox.NewChannelIterator(channel)
+		var values = make([]interface{}, 0)
+		for iterator.HasNext() {
+			var value interface{}
+			assert.Nil(t, iterator.Next(&value))
+			values = append(values, value)
+		}
+		assert.EqualValues(t, []interface{}{"a", 2}, values)
+	}
+	{
+		type event struct {
+			ID   int
+			Name string
+		}
+		channel := make(chan event)
+		go func() {
+			for i := 1; i <= 3; i++ {
+				channel <- event{ID: i, Name: fmt.Sprintf("event%v", i)}
+			}
+			close(channel)
+		}()
+		iterator := toolbox.NewChannelIterator((<-chan event)(channel))
+		var events = make([]event, 0)
+		for iterator.HasNext() {
+			assert.True(t, iterator.HasNext(), "HasNext keeps received value")
+			item := event{}
+			assert.Nil(t, iterator.Next(&item))
+			events = append(events, item)
+		}
+		assert.EqualValues(t, []event{{1, "event1"}, {2, "event2"}, {3, "event3"}}, events)
+	}
+	{
+		channel := make(chan int)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			channel <- 1
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		iterator := toolbox.NewChannelIteratorWithContext(ctx, channel)
+		assert.True(t, iterator.HasNext())
+		value := 0
+		assert.Nil(t, iterator.Next(&value))
+		assert.Equal(t, 1, value)
+		assert.False(t, iterator.HasNext())
+		err := iterator.Next(&value)
+		if assert.NotNil(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "canceled"), err.Error())
+		}
+	}
+}
+
+type panickingIterator struct{}
+
+func (i *panickingIterator) HasNext() bool {
+	return true
+}
+
+func (i *panickingIterator) Next(itemPointer interface{}) error {
+	*(itemPointer.(*string)) = "value"
+	return nil
+}
+
+func TestAsSafeIterator(t *testing.T) {
+	{
+		iterator := toolbox.AsSafeIterator(toolbox.NewSliceIterator([]string{"abc", "12"}))
+		value := 0
+		err := iterator.Next(&value)
+		if assert.NotNil(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "string"), err.Error())
+			assert.True(t, strings.Contains(err.Error(), "*int"), err.Error())
+		}
+		assert.Nil(t, iterator.Next(&value))
+		assert.Equal(t, 12, value)
+		assert.False(t, iterator.HasNext())
+		assert.NotNil(t, iterator.Next(&value))
+	}
+	{
+		iterator := toolbox.NewSliceIterator([]int{1, 2})
+		var text string
+		assert.Nil(t, iterator.Next(&text))
+		assert.Equal(t, "1", text)
+		var count int64
+		assert.Nil(t, iterator.Next(&count))
+		assert.EqualValues(t, 2, count)
+		err := toolbox.NewSliceIterator([]int{1}).Next(1)
+		if assert.NotNil(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "unsupported item pointer type: int"), err.Error())
+		}
+	}
+	{
+		iterator := toolbox.AsSafeIterator(&panickingIterator{})
+		value := 0
+		assert.NotNil(t, iterator.Next(&value))
+		text := ""
+		assert.Nil(t, iterator.Next(&text))
+		assert.Equal(t, "value", text)
+		assert.Nil(t, toolbox.AsSafeIterator(nil))
+	}
+	windowIterator, err := toolbox.NewTimeWindowIterator(time.Now(), time.Now(), time.Second)
+	assert.Nil(t, err)
+	for _, iterator := range []toolbox.Iterator{ //package iterators are returned as they are
+		toolbox.NewSliceIterator([]int{1}),
+		toolbox.NewStructFieldIterator(struct{ Name string }{"abc"}),
+		windowIterator,
+		toolbox.AsSafeIterator(&panickingIterator{}),
+	} {
+		assert.True(t, toolbox.AsSafeIterator(iterator) == iterator, fmt.Sprintf("%T", iterator))
+	}
+}
+
+func TestReverseSliceIterator(t *testing.T) {
+	var useCases = []struct {
+		description string
+		slice       interface{}
+		expect      []interface{}
+	}{
+		{
+			description: "empty slice",
+			slice:       []int{},
+			expect:      []interface{}{},
+		},
+		{
+			description: "single element slice",
+			slice:       []string{"a"},
+			expect:      []interface{}{"a"},
+		},
+		{
+			description: "interface slice",
+			slice:       []interface{}{1, "b", nil, 3.5},
+			expect:      []interface{}{3.5, nil, "b", 1},
+		},
+	}
+	for _, useCase := range useCases {
+		iterator := toolbox.NewReverseSliceIterator(useCase.slice)
+		var actual = make([]interface{}, 0)
+		for iterator.HasNext() {
+			var item interface{}
+			assert.Nil(t, iterator.Next(&item), useCase.description)
+			actual = append(actual, item)
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+	iterator := toolbox.NewReverseSliceIterator([]int{1, 2})
+	var text string
+	assert.Nil(t, iterator.Next(&text))
+	assert.Equal(t, "2", text)
+}
+
+func TestRangeIterator(t *testing.T) {
+	var useCases = []struct {
+		description string
+		from        int
+		to          int
+		step        int
+		expect      []int
+	}{
+		{description: "ascending", from: 0, to: 5, step: 2, expect: []int{0, 2, 4}},
+		{description: "negative step", from: 5, to: -1, step: -2, expect: []int{5, 3, 1}},
+		{description: "step larger than range", from: 1, to: 3, step: 10, expect: []int{1}},
+		{description: "negative step larger than range", from: 3, to: 1, step: -10, expect: []int{3}},
+		{description: "inverted range", from: 5, to: 0, step: 1, expect: []int{}},
+		{description: "inverted range with negative step", from: 0, to: 5, step: -1, expect: []int{}},
+		{description: "zero step", from: 0, to: 5, step: 0, expect: []int{}},
+	}
+	for _, useCase := range useCases {
+		iterator := toolbox.NewRangeIterator(useCase.from, useCase.to, useCase.step)
+		var actual = make([]int, 0)
+		for iterator.HasNext() {
+			value := 0
+			assert.Nil(t, iterator.Next(&value), useCase.description)
+			actual = append(actual, value)
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+	iterator := toolbox.NewRangeIterator(1, 2, 1)
+	var item interface{}
+	assert.Nil(t, iterator.Next(&item))
+	assert.Equal(t, 1, item)
+	assert.NotNil(t, iterator.Next(&item))
+}
+
+func TestBatchingIterator(t *testing.T) {
+	var source = make([]int, 10)
+	for i := range source {
+		source[i] = i + 1
+	}
+	iterator := toolbox.NewBatchingIterator(toolbox.NewSliceIterator(source), 3)
+	var expect = [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}, {10}}
+	var batch = make([]int, 0, 3)
+	for _, expected := range expect {
+		assert.True(t, iterator.HasNext())
+		count, err := iterator.NextBatch(&batch)
+		assert.Nil(t, err)
+		assert.Equal(t, len(expected), count)
+		assert.EqualValues(t, expected, batch)
+	}
+	assert.False(t, iterator.HasNext())
+	count, err := iterator.NextBatch(&batch)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, count)
+
+	iterator = toolbox.NewBatchingIterator(toolbox.NewSliceIterator([]interface{}{1, "2", 3}), 2)
+	var texts []string
+	assert.Nil(t, iterator.Next(&texts))
+	assert.EqualValues(t, []string{"1", "2"}, texts)
+	assert.Nil(t, iterator.Next(&texts))
+	assert.EqualValues(t, []string{"3"}, texts)
+
+	iterator = toolbox.NewBatchingIterator(toolbox.NewSliceIterator([]string{"1", "x"}), 2)
+	var numbers []int
+	count, err = iterator.NextBatch(&numbers)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, count)
+	assert.EqualValues(t, []int{1}, numbers)
+
+	_, err = toolbox.NewBatchingIterator(toolbox.NewSliceIterator(source), 3).NextBatch(batch)
+	assert.NotNil(t, err)
+}
+
+func TestFilteredTransformingIterator(t *testing.T) {
+	type user struct {
+		Name   string
+		Age    int
+		Active bool
+	}
+	var users = []user{
+		{Name: "adam", Age: 30, Active: true},
+		{Name: "bob", Age: 17, Active: false},
+		{Name: "cindy", Age: 42, Active: true},
+		{Name: "dave", Age: 25, Active: false},
+	}
+	isActive := func(item interface{}) bool {
+		return item.(user).Active
+	}
+	toName := func(item interface{}) (interface{}, error) {
+		return strings.ToUpper(item.(user).Name), nil
+	}
+	{
+		iterator := toolbox.NewTransformingIterator(toolbox.NewFilteredIterator(toolbox.NewSliceIterator(users), isActive), toName)
+		var names = make([]string, 0)
+		for iterator.HasNext() {
+			var name string
+			assert.Nil(t, iterator.Next(&name))
+			names = append(names, name)
+		}
+		assert.EqualValues(t, []string{"ADAM", "CINDY"}, names)
+	}
+	{
+		toAge := func(item interface{}) (interface{}, error) {
+			return item.(user).Age, nil
+		}
+		isAdult := func(item interface{}) bool {
+			return item.(int) >= 18
+		}
+		iterator := toolbox.NewFilteredIterator(toolbox.NewTransformingIterator(toolbox.NewSliceIterator(users), toAge), isAdult)
+		var ages = make([]int, 0)
+		for iterator.HasNext() {
+			age := 0
+			assert.Nil(t, iterator.Next(&age))
+			ages = append(ages, age)
+		}
+		assert.EqualValues(t, []int{30, 42, 25}, ages)
+	}
+	{
+		iterator := toolbox.NewFilteredIterator(toolbox.NewSliceIterator(users[1:2]), isActive)
+		assert.False(t, iterator.HasNext(), "only rejected elements remain")
+		var item user
+		assert.NotNil(t, iterator.Next(&item))
+	}
+	{
+		iterator := toolbox.NewTransformingIterator(toolbox.NewSliceIterator(users), func(item interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("failed to transform %v", item.(user).Name)
+		})
+		assert.True(t, iterator.HasNext())
+		var item interface{}
+		err := iterator.Next(&item)
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "failed to transform adam", err.Error())
+		}
+	}
+}
+
+type failingReader struct{}
+
+func (r *failingReader) Read(data []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestLineIterator(t *testing.T) {
+	{
+		iterator := toolbox.NewLineIterator(strings.NewReader(""))
+		assert.False(t, iterator.HasNext())
+		assert.Nil(t, iterator.LastError())
+		var line string
+		assert.NotNil(t, iterator.Next(&line))
+	}
+	{
+		iterator := toolbox.NewLineIterator(strings.NewReader("first\r\nsecond\n\r\nlast"))
+		var lines = make([]string, 0)
+		for iterator.HasNext() {
+			var line string
+			assert.Nil(t, iterator.Next(&line))
+			lines = append(lines, line)
+		}
+		assert.EqualValues(t, []string{"first", "second", "", "last"}, lines)
+		assert.Nil(t, iterator.LastError())
+	}
+	{
+		long := strings.Repeat("x", 2*1024*1024)
+		iterator := toolbox.NewLineIterator(strings.NewReader(long + "\nshort\n"))
+		var line []byte
+		assert.True(t, iterator.HasNext())
+		assert.Nil(t, iterator.Next(&line))
+		assert.Equal(t, len(long), len(line))
+		var item interface{}
+		assert.Nil(t, iterator.Next(&item))
+		assert.Equal(t, "short", item)
+		assert.False(t, iterator.HasNext())
+	}
+	{
+		iterator := toolbox.NewLineIterator(io.MultiReader(strings.NewReader("partial\nline"), &failingReader{}))
+		var lines = make([]string, 0)
+		for iterator.HasNext() {
+			var line string
+			assert.Nil(t, iterator.Next(&line))
+			lines = append(lines, line)
+		}
+		assert.EqualValues(t, []string{"partial", "line"}, lines)
+		if assert.NotNil(t, iterator.LastError()) {
+			assert.Equal(t, "connection reset", iterator.LastError().Error())
+		}
+	}
+}
+
+func TestChainedIterator(t *testing.T) {
+	iterator := toolbox.NewChainedIterator(
+		toolbox.NewSliceIterator([]string{"a", "b"}),
+		toolbox.NewSliceIterator([]interface{}{}),
+		toolbox.NewSliceIterator([]interface{}{1, "c"}),
+		toolbox.NewSliceIterator([]string{}),
+	)
+	var values = make([]interface{}, 0)
+	for iterator.HasNext() {
+		var value interface{}
+		assert.Nil(t, iterator.Next(&value))
+		values = append(values, value)
+	}
+	assert.EqualValues(t, []interface{}{"a", "b", 1, "c"}, values)
+	var value interface{}
+	assert.NotNil(t, iterator.Next(&value))
+	assert.False(t, toolbox.NewChainedIterator().HasNext())
+}
+
+func TestFlatteningIterator(t *testing.T) {
+	newSource := func() toolbox.Iterator {
+		return toolbox.NewSliceIterator([]interface{}{
+			"a",
+			[]string{"b", "c"},
+			toolbox.NewSliceIterator([]interface{}{}),
+			toolbox.NewSliceIterator([]interface{}{1, []int{2, 3}}),
+			[]byte("raw"),
+		})
+	}
+	var useCases = []struct {
+		description string
+		iterator    toolbox.Iterator
+		expect      []interface{}
+	}{
+		{
+			description: "one level",
+			iterator:    toolbox.NewFlatteningIterator(newSource()),
+			expect:      []interface{}{"a", "b", "c", 1, []int{2, 3}, []byte("raw")},
+		},
+		{
+			description: "two levels",
+			iterator:    toolbox.NewFlatteningIteratorWithDepth(newSource(), 2),
+			expect:      []interface{}{"a", "b", "c", 1, 2, 3, []byte("raw")},
+		},
+		{
+			description: "chained sources",
+			iterator: toolbox.NewFlatteningIterator(toolbox.NewChainedIterator(
+				toolbox.NewSliceIterator([]string{"x"}),
+				toolbox.NewSliceIterator([]interface{}{}),
+				toolbox.NewSliceIterator([]interface{}{[]interface{}{"y", "z"}}),
+			)),
+			expect: []interface{}{"x", "y", "z"},
+		},
+	}
+	for _, useCase := range useCases {
+		var values = make([]interface{}, 0)
+		for useCase.iterator.HasNext() {
+			var value interface{}
+			assert.Nil(t, useCase.iterator.Next(&value), useCase.description)
+			values = append(values, value)
+		}
+		assert.EqualValues(t, useCase.expect, values, useCase.description)
+	}
+}
+
+func TestIteratorToSlice(t *testing.T) {
+	var texts = []string{"existing"}
+	err := toolbox.IteratorToSlice(toolbox.NewSliceIterator([]int{1, 2, 3}), &texts)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"existing", "1", "2", "3"}, texts)
+
+	var numbers []int
+	err = toolbox.IteratorToSlice(toolbox.NewChainedIterator(toolbox.NewRangeIterator(0, 2, 1), toolbox.NewSliceIterator([]interface{}{"5"})), &numbers)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []int{0, 1, 5}, numbers)
+
+	numbers = nil
+	err = toolbox.IteratorToSlice(toolbox.NewSliceIterator([]string{"1", "2", "x"}), &numbers)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "index 2"), err.Error())
+	}
+	assert.EqualValues(t, []int{1, 2}, numbers)
+	assert.NotNil(t, toolbox.IteratorToSlice(toolbox.NewSliceIterator([]int{1}), numbers))
+}
+
+func TestIteratorToMap(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	users := []user{{1, "adam"}, {2, "bob"}}
+	var byID map[string]user
+	err := toolbox.IteratorToMap(toolbox.NewSliceIterator(users), func(item interface{}) interface{} {
+		return item.(user).ID
+	}, &byID)
+	assert.Nil(t, err)
+	assert.EqualValues(t, map[string]user{"1": users[0], "2": users[1]}, byID)
+
+	var byName = map[string]int{}
+	err = toolbox.IteratorToMap(toolbox.NewSliceIterator([]string{"1", "x"}), func(item interface{}) interface{} {
+		return item
+	}, &byName)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "index 1"), err.Error())
+	}
+	assert.EqualValues(t, map[string]int{"1": 1}, byName)
+}
+
+func TestIteratorCount(t *testing.T) {
+	assert.Equal(t, 0, toolbox.IteratorCount(toolbox.NewSliceIterator([]int{})))
+	assert.Equal(t, 3, toolbox.IteratorCount(toolbox.NewSliceIterator([]int{1, 2, 3})))
+	assert.Equal(t, 2, toolbox.IteratorCount(toolbox.NewMapIterator(map[string]int{"a": 1, "b": 2}, false)))
+	iterator := toolbox.NewRangeIterator(0, 10, 3)
+	value := 0
+	assert.Nil(t, iterator.Next(&value))
+	assert.Equal(t, 3, toolbox.IteratorCount(iterator))
+}
+
+func TestPeekableIterator(t *testing.T) {
+	iterator := toolbox.NewPeekableIterator(toolbox.NewSliceIterator([]string{"a", "b", "c"}))
+	var values = make([]string, 0)
+	for iterator.HasNext() {
+		var first, second, next string
+		assert.True(t, iterator.Peek(&first))
+		assert.True(t, iterator.Peek(&second))
+		assert.Equal(t, first, second)
+		assert.Nil(t, iterator.Next(&next))
+		assert.Equal(t, first, next)
+		values = append(values, next)
+	}
+	assert.EqualValues(t, []string{"a", "b", "c"}, values)
+	var value string
+	assert.False(t, iterator.Peek(&value))
+	assert.NotNil(t, iterator.Next(&value))
+
+	iterator = toolbox.NewPeekableIterator(toolbox.NewSliceIterator([]string{"1", "x"}))
+	number := 0
+	assert.True(t, iterator.Peek(&number))
+	assert.Equal(t, 1, number)
+	assert.Nil(t, iterator.Next(&number))
+	assert.False(t, iterator.Peek(&number), "not convertible element")
+	assert.True(t, iterator.HasNext())
+	assert.Nil(t, iterator.Next(&value))
+	assert.Equal(t, "x", value)
+}
+
+func TestPagedIterator(t *testing.T) {
+	var pages = map[string][]interface{}{
+		"":   {1, 2},
+		"p2": {},
+		"p3": {"3", 4},
+	}
+	var nextTokens = map[string]string{"": "p2", "p2": "p3", "p3": ""}
+	newFetcher := func(failOn string, fetched chan string) func(pageToken string) ([]interface{}, string, error) {
+		return func(pageToken string) ([]interface{}, string, error) {
+			if fetched != nil {
+				fetched <- pageToken
+			}
+			if pageToken == failOn {
+				return nil, "", errors.New("service unavailable")
+			}
+			return pages[pageToken], nextTokens[pageToken], nil
+		}
+	}
+	{
+		var values []int
+		assert.Nil(t, toolbox.IteratorToSlice(toolbox.NewPagedIterator(newFetcher("none", nil)), &values))
+		assert.EqualValues(t, []int{1, 2, 3, 4}, values)
+	}
+	{
+		iterator := toolbox.NewPagedIterator(newFetcher("p2", nil))
+		var values = make([]int, 0)
+		var errs = make([]error, 0)
+		for iterator.HasNext() {
+			value := 0
+			if err := iterator.Next(&value); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			values = append(values, value)
+		}
+		assert.EqualValues(t, []int{1, 2}, values)
+		if assert.Equal(t, 1, len(errs)) {
+			assert.True(t, strings.Contains(errs[0].Error(), "service unavailable"), errs[0].Error())
+			assert.True(t, strings.Contains(errs[0].Error(), "p2"), errs[0].Error())
+		}
+	}
+	{
+		fetched := make(chan string, 3)
+		iterator := toolbox.NewPagedIteratorWithPrefetch(newFetcher("none", fetched))
+		value := 0
+		assert.True(t, iterator.HasNext())
+		assert.Equal(t, "", <-fetched)
+		select {
+		case token := <-fetched:
+			assert.Equal(t, "p2", token, "next page is fetched while the first one is consumed")
+		case <-time.After(time.Second):
+			assert.True(t, false, "next page was not prefetched")
+		}
+		var values = make([]int, 0)
+		for iterator.HasNext() {
+			assert.Nil(t, iterator.Next(&value))
+			values = append(values, value)
+		}
+		assert.EqualValues(t, []int{1, 2, 3, 4}, values)
+	}
+}
+
+type IteratorAudit struct {
+	Created string `json:"created"`
+	Updated string `json:"updated"`
+}
+
+type iteratorAccount struct {
+	ID int `json:"id"`
+	*IteratorAudit
+	Name    string `json:"name" required:"true"`
+	Owner   *iteratorAccount
+	Balance float64
+}
+
+func TestStructFieldIterator(t *testing.T) {
+	account := &iteratorAccount{ID: 1, IteratorAudit: &IteratorAudit{Created: "2020-01-01"}, Name: "main", Balance: 2.5}
+	{
+		iterator := toolbox.NewStructFieldIterator(account)
+		var names = make([]string, 0)
+		var values = make(map[string]interface{})
+		for iterator.HasNext() {
+			entry := &toolbox.FieldEntry{}
+			assert.Nil(t, iterator.Next(entry))
+			names = append(names, entry.Name)
+			values[entry.Name] = entry.Value
+			if entry.Name == "Name" {
+				assert.Equal(t, "name", entry.Tag.Get("json"))
+				assert.Equal(t, "true", entry.Tag.Get("required"))
+			}
+		}
+		assert.EqualValues(t, []string{"ID", "Created", "Updated", "Name", "Owner", "Balance"}, names)
+		assert.Equal(t, "2020-01-01", values["Created"])
+		assert.Nil(t, values["Owner"])
+		assert.Equal(t, 2.5, values["Balance"])
+	}
+	{
+		iterator := toolbox.NewStructFieldIteratorWithNilPointers(account, false)
+		tagged := toolbox.NewFilteredIterator(iterator, func(item interface{}) bool {
+			return item.(*toolbox.FieldEntry).Tag.Get("json") != ""
+		})
+		jsonNames := toolbox.NewTransformingIterator(tagged, func(item interface{}) (interface{}, error) {
+			return item.(*toolbox.FieldEntry).Tag.Get("json"), nil
+		})
+		var names []string
+		assert.Nil(t, toolbox.IteratorToSlice(jsonNames, &names))
+		assert.EqualValues(t, []string{"id", "created", "updated", "name"}, names)
+		assert.Equal(t, 5, toolbox.IteratorCount(toolbox.NewStructFieldIteratorWithNilPointers(account, false)))
+	}
+	{
+		iterator := toolbox.NewStructFieldIterator("not a struct")
+		assert.True(t, iterator.HasNext())
+		var entry toolbox.FieldEntry
+		assert.NotNil(t, iterator.Next(&entry))
+		assert.False(t, iterator.HasNext())
+	}
+}
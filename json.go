@@ -182,3 +182,29 @@ func (s AnyJSONType) Value() (interface{}, error) {
 	var result interface{}
 	return result, json.Unmarshal([]byte(s), &result)
 }
+
+//NormalizeJSONNumbers converts json.Number values in maps and slices to int64 when it fits, or to float64 otherwise, maps and slices are updated in place
+func NormalizeJSONNumbers(source interface{}) interface{} {
+	switch value := source.(type) {
+	case json.Number:
+		if intValue, err := value.Int64(); err == nil {
+			return intValue
+		}
+		if floatValue, err := value.Float64(); err == nil {
+			return floatValue
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = NormalizeJSONNumbers(v)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range value {
+			value[k] = NormalizeJSONNumbers(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = NormalizeJSONNumbers(v)
+		}
+	}
+	return source
+}
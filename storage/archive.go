@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//ArchiveOptions represents zip archive options
+type ArchiveOptions struct {
+	SortEntries        bool       //writes entries sorted by name
+	FixedTimestamp     *time.Time //overrides all entries modification time
+	CompressionLevel   *int       //flate compression level, nil uses flate.DefaultCompression
+	IncludeDirectories bool       //writes explicit directory entries
+}
+
+//archiveEntry represents an archive entry source object
+type archiveEntry struct {
+	Object
+	name string
+}
+
+//archiveEntryName returns forward slash relative archive entry name without leading slash
+func archiveEntryName(name string) string {
+	return strings.TrimPrefix(strings.Replace(name, "\\", "/", -1), "/")
+}
+
+//ArchiveWithOptions archives supplied URL assets into zip writer with supplied options, it registers deflate compressor with options compression level on the writer
+func ArchiveWithOptions(service Service, URL string, writer *zip.Writer, options *ArchiveOptions) error {
+	if options == nil {
+		options = &ArchiveOptions{}
+	}
+	var level = flate.DefaultCompression
+	if options.CompressionLevel != nil {
+		level = *options.CompressionLevel
+	}
+	writer.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, level)
+	})
+	objects, err := ListWithPattern(service, URL, "**")
+	if err != nil {
+		return err
+	}
+	var basePath = urlPath(URL)
+	var entries = make([]*archiveEntry, 0, len(objects))
+	for _, object := range objects {
+		if object.IsFolder() && !options.IncludeDirectories {
+			continue
+		}
+		entries = append(entries, &archiveEntry{Object: object, name: archiveEntryName(strings.TrimPrefix(urlPath(object.URL()), basePath))})
+	}
+	if options.SortEntries {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	}
+	for _, entry := range entries {
+		if err = archiveObject(service, entry.Object, entry.name, writer, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//newZipHeader returns zip header for supplied object, header carries object size, archive/zip emits zip64 records for objects over 4GB or archives over 65535 entries
+func newZipHeader(object Object, name string, method uint16) (*zip.FileHeader, error) {
+	fileInfo := object.FileInfo()
+	if fileInfo == nil {
+		return &zip.FileHeader{Name: name, Method: method}, nil
+	}
+	header, err := zip.FileInfoHeader(fileInfo)
+	if err != nil {
+		return nil, err
+	}
+	header.Name = name
+	header.Method = method
+	return header, nil
+}
+
+func archiveObject(service Service, object Object, name string, writer *zip.Writer, options *ArchiveOptions) error {
+	header, err := newZipHeader(object, name, zip.Deflate)
+	if err != nil {
+		return err
+	}
+	if object.IsFolder() {
+		header.Name += "/"
+		header.Method = zip.Store
+	}
+	if options.FixedTimestamp != nil {
+		header.Modified = options.FixedTimestamp.UTC()
+	}
+	entryWriter, err := writer.CreateHeader(header)
+	if err != nil || object.IsFolder() {
+		return err
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(entryWriter, reader)
+	return err
+}
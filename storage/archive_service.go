@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//ArchiveScheme represents archive service object URL scheme
+const ArchiveScheme = "archive"
+
+var errReadOnlyArchive = errors.New("unsupported operation: archive storage service is read-only")
+
+//archiveService represents a read-only service exposing zip archive entries, URL path is resolved against archive root regardless of URL scheme
+type archiveService struct {
+	archiveURL string
+	files      map[string]*zip.File
+	folders    map[string]map[string]bool
+}
+
+const (
+	rangeReadBlockSize = 4 * 1024 * 1024 //rangeReadBlockSize size of aligned block downloaded with a single range request
+	rangeReadMaxBlocks = 4               //rangeReadMaxBlocks number of recently used blocks kept in memory
+)
+
+//rangeReaderAt represents a reader at downloading and caching aligned object blocks, so small zip reads do not issue a range request each
+type rangeReaderAt struct {
+	service Service
+	object  Object
+	size    int64
+	mutex   *sync.Mutex
+	blocks  map[int64][]byte
+	recent  []int64
+}
+
+func (r *rangeReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	var read = 0
+	for read < len(p) {
+		position := offset + int64(read)
+		if position >= r.size {
+			return read, io.EOF
+		}
+		block, err := r.block(position / rangeReadBlockSize)
+		if err != nil {
+			return read, err
+		}
+		start := position % rangeReadBlockSize
+		if start >= int64(len(block)) {
+			return read, io.EOF
+		}
+		read += copy(p[read:], block[start:])
+	}
+	return read, nil
+}
+
+//block returns cached or downloaded block with supplied index, the least recently used block is evicted
+func (r *rangeReaderAt) block(index int64) ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if data, ok := r.blocks[index]; ok {
+		r.touch(index)
+		return data, nil
+	}
+	from := index * rangeReadBlockSize
+	to := from + rangeReadBlockSize
+	if to > r.size {
+		to = r.size
+	}
+	reader, err := DownloadWithRange(r.service, r.object, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.recent) >= rangeReadMaxBlocks {
+		delete(r.blocks, r.recent[0])
+		r.recent = r.recent[1:]
+	}
+	r.blocks[index] = data
+	r.recent = append(r.recent, index)
+	return data, nil
+}
+
+func (r *rangeReaderAt) touch(index int64) {
+	for i, candidate := range r.recent {
+		if candidate == index {
+			r.recent = append(append(r.recent[:i:i], r.recent[i+1:]...), index)
+			return
+		}
+	}
+}
+
+func newRangeReaderAt(service Service, object Object) *rangeReaderAt {
+	return &rangeReaderAt{
+		service: service,
+		object:  object,
+		size:    object.FileInfo().Size(),
+		mutex:   &sync.Mutex{},
+		blocks:  make(map[int64][]byte),
+	}
+}
+
+//openArchiveReaderAt returns archive reader at with archive size, archive is range read if service implements Ranger,
+//otherwise it is spooled to a temp file removed by returned cleanup function
+func openArchiveReaderAt(service Service, archiveURL string) (io.ReaderAt, int64, func(), error) {
+	object, err := service.StorageObject(archiveURL)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("unable download archive %v, %v", archiveURL, err)
+	}
+	if _, ok := resolveService(service, archiveURL).(Ranger); ok && object.FileInfo() != nil {
+		return newRangeReaderAt(service, object), object.FileInfo().Size(), func() {}, nil
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("unable download archive %v, %v", archiveURL, err)
+	}
+	defer reader.Close()
+	file, err := ioutil.TempFile("", "archive")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup := func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, fmt.Errorf("unable download archive %v, %v", archiveURL, err)
+	}
+	return file, size, cleanup, nil
+}
+
+//archiveEntryPath returns absolute entry path for supplied URL
+func archiveEntryPath(URL string) string {
+	return "/" + strings.Trim(urlPath(URL), "/")
+}
+
+func (s *archiveService) addFolder(folderPath string) {
+	for folderPath != "/" {
+		parent := path.Dir(folderPath)
+		if _, ok := s.folders[folderPath]; !ok {
+			s.folders[folderPath] = make(map[string]bool)
+		}
+		if s.folders[parent] == nil {
+			s.folders[parent] = make(map[string]bool)
+		}
+		s.folders[parent][folderPath] = true
+		folderPath = parent
+	}
+}
+
+func (s *archiveService) newObject(entryPath string) (Object, error) {
+	var URL = ArchiveScheme + "://" + entryPath
+	if file, ok := s.files[entryPath]; ok {
+		return newArchiveEntryObject(URL, file, file.FileInfo()), nil
+	}
+	if _, ok := s.folders[entryPath]; ok {
+		var fileInfo = NewFileInfo(path.Base(entryPath), 102, folderMode, time.Time{}, true)
+		return newArchiveEntryObject(URL, nil, fileInfo), nil
+	}
+	return nil, fmt.Errorf("%v not found in archive %v: %v", entryPath, s.archiveURL, noSuchFileOrDirectoryError)
+}
+
+//List returns a list of archive entries for supplied URL, folders are synthesized from entry paths
+func (s *archiveService) List(URL string) ([]Object, error) {
+	var entryPath = archiveEntryPath(URL)
+	object, err := s.newObject(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	var result = []Object{object}
+	if object.IsContent() {
+		return result, nil
+	}
+	var children = make([]string, 0, len(s.folders[entryPath]))
+	for child := range s.folders[entryPath] {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		object, err := s.newObject(child)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, object)
+	}
+	return result, nil
+}
+
+//Exists returns true if archive entry or synthesized folder exists
+func (s *archiveService) Exists(URL string) (bool, error) {
+	var entryPath = archiveEntryPath(URL)
+	_, isFile := s.files[entryPath]
+	_, isFolder := s.folders[entryPath]
+	return isFile || isFolder, nil
+}
+
+//StorageObject returns archive entry object with zip header size and modification time
+func (s *archiveService) StorageObject(URL string) (Object, error) {
+	return s.newObject(archiveEntryPath(URL))
+}
+
+//Download returns reader streaming decompressed entry content
+func (s *archiveService) Download(object Object) (io.ReadCloser, error) {
+	file, ok := s.files[archiveEntryPath(object.URL())]
+	if !ok {
+		return nil, fmt.Errorf("%v is not archive %v file entry", object.URL(), s.archiveURL)
+	}
+	return file.Open()
+}
+
+//DownloadWithURL returns reader streaming decompressed entry content for supplied URL
+func (s *archiveService) DownloadWithURL(URL string) (io.ReadCloser, error) {
+	object, err := s.StorageObject(URL)
+	if err != nil {
+		return nil, err
+	}
+	return s.Download(object)
+}
+
+func (s *archiveService) Upload(URL string, reader io.Reader) error {
+	return errReadOnlyArchive
+}
+
+func (s *archiveService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	return errReadOnlyArchive
+}
+
+func (s *archiveService) Delete(object Object) error {
+	return errReadOnlyArchive
+}
+
+func (s *archiveService) Register(schema string, service Service) error {
+	return errReadOnlyArchive
+}
+
+func (s *archiveService) Close() error {
+	return nil
+}
+
+//NewArchiveService returns a read-only service exposing supplied zip archive entries,
+//archive is range read if service implements Ranger, otherwise it is downloaded into memory
+func NewArchiveService(service Service, archiveURL string) (Service, error) {
+	object, err := service.StorageObject(archiveURL)
+	if err != nil {
+		return nil, err
+	}
+	var readerAt io.ReaderAt
+	var size int64
+	if _, ok := resolveService(service, archiveURL).(Ranger); ok && object.FileInfo() != nil {
+		readerAt = newRangeReaderAt(service, object)
+		size = object.FileInfo().Size()
+	} else {
+		reader, err := service.Download(object)
+		if err != nil {
+			return nil, fmt.Errorf("unable download archive %v, %v", archiveURL, err)
+		}
+		content, err := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable download archive %v, %v", archiveURL, err)
+		}
+		readerAt = bytes.NewReader(content)
+		size = int64(len(content))
+	}
+	zipReader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, fmt.Errorf("unable read archive %v, %v", archiveURL, err)
+	}
+	var result = &archiveService{
+		archiveURL: archiveURL,
+		files:      make(map[string]*zip.File),
+		folders:    map[string]map[string]bool{"/": {}},
+	}
+	for _, file := range zipReader.File {
+		var entryPath = "/" + strings.Trim(archiveEntryName(file.Name), "/")
+		if entryPath == "/" {
+			continue
+		}
+		if strings.HasSuffix(file.Name, "/") {
+			result.addFolder(entryPath)
+			continue
+		}
+		result.files[entryPath] = file
+		result.addFolder(path.Dir(entryPath))
+		result.folders[path.Dir(entryPath)][entryPath] = true
+	}
+	return result, nil
+}
+
+//archiveEntryObject represents an archive entry object
+type archiveEntryObject struct {
+	*AbstractObject
+}
+
+func (o *archiveEntryObject) Unwrap(target interface{}) error {
+	if file, casted := target.(**zip.File); casted {
+		source, ok := o.Source.(*zip.File)
+		if !ok {
+			return fmt.Errorf("failed to cast %T into %T", o.Source, target)
+		}
+		*file = source
+		return nil
+	}
+	return fmt.Errorf("unsuported target %T", target)
+}
+
+func newArchiveEntryObject(URL string, file *zip.File, fileInfo os.FileInfo) Object {
+	var source interface{}
+	if file != nil {
+		source = file
+	}
+	abstract := NewAbstractStorageObject(URL, source, fileInfo)
+	result := &archiveEntryObject{
+		AbstractObject: abstract,
+	}
+	result.AbstractObject.Object = result
+	return result
+}
@@ -0,0 +1,136 @@
+package storage_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewArchiveService(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	var files = map[string]string{
+		"readme.md":             "readme",
+		"config/app.json":       "{}",
+		"config/env/prod.yaml":  "prod",
+		"bin/app":               "binary",
+		"config/env/stage.yaml": "stage",
+	}
+	for name, content := range files {
+		_ = memService.Upload("mem:///bundle/"+name, strings.NewReader(content))
+	}
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	assert.Nil(t, storage.Archive(memService, "mem:///bundle", archive))
+	assert.Nil(t, archive.Close())
+	_ = memService.Upload("mem:///release/bundle.zip", bytes.NewReader(buffer.Bytes()))
+
+	parent := path.Join(os.TempDir(), "storage_archive_service")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	_ = os.MkdirAll(parent, 0755)
+	assert.Nil(t, ioutil.WriteFile(path.Join(parent, "bundle.zip"), buffer.Bytes(), 0644))
+
+	var useCases = []struct {
+		description string
+		service     storage.Service
+		archiveURL  string
+	}{
+		{
+			description: "downloaded archive",
+			service:     memService,
+			archiveURL:  "mem:///release/bundle.zip",
+		},
+		{
+			description: "range read archive",
+			service:     storage.NewFileStorage(),
+			archiveURL:  "file://" + path.Join(parent, "bundle.zip"),
+		},
+	}
+	for _, useCase := range useCases {
+		archiveService, err := storage.NewArchiveService(useCase.service, useCase.archiveURL)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		objects, err := archiveService.List("archive:///config")
+		if assert.Nil(t, err, useCase.description) && assert.Equal(t, 3, len(objects), useCase.description) {
+			assert.True(t, objects[0].IsFolder(), useCase.description)
+			assert.Equal(t, "archive:///config/app.json", objects[1].URL(), useCase.description)
+			assert.True(t, objects[2].IsFolder(), useCase.description)
+		}
+		object, err := archiveService.StorageObject("archive:///config/env/prod.yaml")
+		if assert.Nil(t, err, useCase.description) {
+			assert.EqualValues(t, 4, object.FileInfo().Size(), useCase.description)
+		}
+		exists, _ := archiveService.Exists("archive:///config/missing.json")
+		assert.False(t, exists, useCase.description)
+		assert.NotNil(t, archiveService.Upload("archive:///file.txt", strings.NewReader("abc")), useCase.description)
+
+		targetURL := "mem:///extracted/" + strings.Replace(useCase.description, " ", "_", -1)
+		err = storage.Copy(archiveService, "archive:///config/", memService, targetURL, nil, nil)
+		assert.Nil(t, err, useCase.description)
+		for name, expected := range files {
+			exists, _ := memService.Exists(targetURL + "/" + strings.TrimPrefix(name, "config/"))
+			assert.Equal(t, strings.HasPrefix(name, "config/"), exists, useCase.description+": "+name)
+			if !exists {
+				continue
+			}
+			content, err := storage.DownloadText(memService, targetURL+"/"+strings.TrimPrefix(name, "config/"))
+			assert.Nil(t, err, useCase.description)
+			assert.Equal(t, expected, content, useCase.description)
+		}
+	}
+}
+
+//countingRanger represents a service counting range downloads
+type countingRanger struct {
+	storage.Service
+	ranges int32
+}
+
+func (s *countingRanger) DownloadWithRange(object storage.Object, from, to int64) (io.ReadCloser, error) {
+	atomic.AddInt32(&s.ranges, 1)
+	return storage.DownloadWithRange(s.Service, object, from, to)
+}
+
+func TestNewArchiveService_RangeBlocks(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_archive_range_blocks")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	_ = os.MkdirAll(parent, 0755)
+	content := make([]byte, 10*1024*1024)
+	_, _ = rand.New(rand.NewSource(1)).Read(content)
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	writer, err := archive.Create("data.bin")
+	assert.Nil(t, err)
+	_, err = writer.Write(content)
+	assert.Nil(t, err)
+	assert.Nil(t, archive.Close())
+	assert.Nil(t, ioutil.WriteFile(path.Join(parent, "data.zip"), buffer.Bytes(), 0644))
+
+	service := &countingRanger{Service: storage.NewFileStorage()}
+	archiveService, err := storage.NewArchiveService(service, "file://"+path.Join(parent, "data.zip"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	reader, err := archiveService.DownloadWithURL("archive:///data.bin")
+	if !assert.Nil(t, err) {
+		return
+	}
+	actual, err := ioutil.ReadAll(reader)
+	_ = reader.Close()
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(content, actual))
+	ranges := atomic.LoadInt32(&service.ranges)
+	assert.True(t, ranges <= 6, fmt.Sprintf("range requests: %v", ranges))
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+//contextReader represents a reader failing with context error once context is done, it closes underlying reader on cancellation to abort pending reads
+type contextReader struct {
+	io.ReadCloser
+	ctx  context.Context
+	done chan bool
+	once *sync.Once
+}
+
+//Read reads from underlying reader unless context is done
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && r.ctx.Err() != nil {
+		return n, r.ctx.Err()
+	}
+	return n, err
+}
+
+//Close closes underlying reader once
+func (r *contextReader) Close() error {
+	var err error
+	r.once.Do(func() {
+		close(r.done)
+		err = r.ReadCloser.Close()
+	})
+	return err
+}
+
+func (r *contextReader) watch() {
+	select {
+	case <-r.ctx.Done():
+		_ = r.Close()
+	case <-r.done:
+	}
+}
+
+//newContextReader returns supplied reader bound to context, background context reader is returned untouched
+func newContextReader(ctx context.Context, reader io.ReadCloser) io.ReadCloser {
+	if ctx.Done() == nil {
+		return reader
+	}
+	result := &contextReader{ReadCloser: reader, ctx: ctx, done: make(chan bool), once: &sync.Once{}}
+	go result.watch()
+	return result
+}
+
+//ContextDownloader represents an optional service extension downloading content bound to context, cancellation aborts pending body reads
+type ContextDownloader interface {
+	//DownloadWithContext returns reader for supplied URL content
+	DownloadWithContext(ctx context.Context, URL string) (io.ReadCloser, error)
+}
+
+//DownloadWithContext returns reader for supplied URL bound to context, it uses service ContextDownloader if available,
+//otherwise downloaded reader is closed once context is done
+func DownloadWithContext(ctx context.Context, service Service, URL string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if downloader, ok := resolveService(service, URL).(ContextDownloader); ok {
+		return downloader.DownloadWithContext(ctx, URL)
+	}
+	reader, err := Download(service, URL)
+	if err != nil {
+		return nil, err
+	}
+	return newContextReader(ctx, reader), nil
+}
+
+//ContextUploader represents an optional service extension uploading content bound to context
+type ContextUploader interface {
+	//UploadWithContext uploads reader content for supplied URL
+	UploadWithContext(ctx context.Context, URL string, reader io.Reader) error
+}
+
+//UploadWithContext uploads reader content for supplied URL bound to context, it uses service ContextUploader if available,
+//otherwise reader fails with context error once context is done
+func UploadWithContext(ctx context.Context, service Service, URL string, reader io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if uploader, ok := resolveService(service, URL).(ContextUploader); ok {
+		return uploader.UploadWithContext(ctx, URL, reader)
+	}
+	wrapped := newContextReader(ctx, ioutil.NopCloser(reader))
+	defer wrapped.Close()
+	return service.Upload(URL, wrapped)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+const (
+	//MD5 represents md5 checksum algorithm
+	MD5 = "md5"
+	//SHA1 represents sha1 checksum algorithm
+	SHA1 = "sha1"
+	//SHA256 represents sha256 checksum algorithm
+	SHA256 = "sha256"
+)
+
+//Checksummer represents an optional service extension that returns stored object checksum without downloading content
+type Checksummer interface {
+	//Checksum returns hex encoded object checksum or empty string if checksum for supplied algorithm is not available
+	Checksum(object Object, algo string) (string, error)
+}
+
+//newHash returns a hash for supplied algorithm
+func newHash(algo string) (hash.Hash, error) {
+	switch strings.ToLower(algo) {
+	case MD5:
+		return md5.New(), nil
+	case SHA1:
+		return sha1.New(), nil
+	case SHA256:
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported checksum algorithm: %v", algo)
+}
+
+//computeChecksum returns hex encoded checksum of reader content
+func computeChecksum(reader io.Reader, algo string) (string, error) {
+	hasher, err := newHash(algo)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(hasher, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+//Checksum returns hex encoded object checksum for supplied algorithm (md5, sha1, sha256),
+//it uses service Checksummer if available, otherwise or if stored checksum is not available it streams object content
+func Checksum(service Service, object Object, algo string) (string, error) {
+	if _, err := newHash(algo); err != nil {
+		return "", err
+	}
+	if checksummer, ok := resolveService(service, object.URL()).(Checksummer); ok {
+		checksum, err := checksummer.Checksum(object, strings.ToLower(algo))
+		if err != nil || checksum != "" {
+			return checksum, err
+		}
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	checksum, err := computeChecksum(reader, algo)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute %v checksum %v: %v", algo, object.URL(), err)
+	}
+	return checksum, nil
+}
@@ -0,0 +1,131 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+//checksumService represents a service with stored checksums, empty checksum simulates multipart upload ETag
+type checksumService struct {
+	storage.Service
+	checksum  string
+	calls     int32
+	downloads int32
+}
+
+func (s *checksumService) Checksum(object storage.Object, algo string) (string, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return s.checksum, nil
+}
+
+func (s *checksumService) Download(object storage.Object) (io.ReadCloser, error) {
+	atomic.AddInt32(&s.downloads, 1)
+	return s.Service.Download(object)
+}
+
+func TestChecksum(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	_ = memService.Upload("mem:///checksum/abc.txt", strings.NewReader("abc"))
+	object, err := memService.StorageObject("mem:///checksum/abc.txt")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		description     string
+		service         storage.Service
+		algo            string
+		expect          string
+		expectDownloads int32
+		hasError        bool
+	}{
+		{
+			description: "memory md5",
+			service:     memService,
+			algo:        storage.MD5,
+			expect:      "900150983cd24fb0d6963f7d28e17f72",
+		},
+		{
+			description: "memory sha1",
+			service:     memService,
+			algo:        storage.SHA1,
+			expect:      "a9993e364706816aba3e25717850c26c9cd0d89d",
+		},
+		{
+			description: "memory sha256",
+			service:     memService,
+			algo:        "SHA256",
+			expect:      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		},
+		{
+			description:     "provider supplied",
+			service:         &checksumService{Service: memService, checksum: "stored"},
+			algo:            storage.MD5,
+			expect:          "stored",
+			expectDownloads: 0,
+		},
+		{
+			description:     "multipart etag streaming fallback",
+			service:         &checksumService{Service: memService},
+			algo:            storage.MD5,
+			expect:          "900150983cd24fb0d6963f7d28e17f72",
+			expectDownloads: 1,
+		},
+		{
+			description: "unsupported algorithm",
+			service:     memService,
+			algo:        "crc32",
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		checksum, err := storage.Checksum(useCase.service, object, useCase.algo)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expect, checksum, useCase.description)
+		if service, ok := useCase.service.(*checksumService); ok {
+			assert.EqualValues(t, 1, service.calls, useCase.description)
+			assert.EqualValues(t, useCase.expectDownloads, service.downloads, useCase.description)
+		}
+	}
+}
+
+//countingService represents a service counting uploads
+type countingService struct {
+	storage.Service
+	uploads int32
+}
+
+func (s *countingService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	atomic.AddInt32(&s.uploads, 1)
+	return s.Service.UploadWithMode(URL, mode, reader)
+}
+
+func TestCopyWithOptions_SkipSameChecksum(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	_ = service.Upload("mem:///source/same.txt", strings.NewReader("abc"))
+	_ = service.Upload("mem:///source/changed.txt", strings.NewReader("xyz"))
+	_ = service.Upload("mem:///source/new.txt", strings.NewReader("new"))
+	_ = service.Upload("mem:///target/same.txt", strings.NewReader("abc"))
+	_ = service.Upload("mem:///target/changed.txt", strings.NewReader("xyy"))
+	destination := &countingService{Service: service}
+	err := storage.CopyWithOptions(service, "mem:///source", destination, "mem:///target", &storage.CopyOptions{SkipSameChecksum: storage.SHA256})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, destination.uploads)
+	reader, err := storage.Download(service, "mem:///target/changed.txt")
+	if assert.Nil(t, err) {
+		content, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, "xyz", string(content))
+	}
+	if err := storage.CopyWithOptions(service, "mem:///source", destination, "mem:///target", &storage.CopyOptions{SkipSameChecksum: "crc32"}); assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unsupported checksum algorithm")
+	}
+}
@@ -4,17 +4,29 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/viant/toolbox"
 	"io"
 	"io/ioutil"
+	"os"
 	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type CopyHandler func(sourceObject Object, source io.Reader, destinationService Service, destinationURL string) error
 type ModificationHandler func(reader io.ReadCloser) (io.ReadCloser, error)
 
+//ObjectModificationHandler represents a content modification handler receiving source object, it may return supplied reader to stream content untouched
+type ObjectModificationHandler func(object Object, reader io.ReadCloser) (io.ReadCloser, error)
+
+//DeletionListener represents a mirror deletion listener
+type DeletionListener func(object Object)
+
 func urlPath(URL string) string {
 	var result = URL
 	schemaPosition := strings.Index(URL, "://")
@@ -42,27 +54,193 @@ func truncatePath(path string) string {
 	return path
 }
 
-func copyStorageContent(sourceService Service, sourceURL string, destinationService Service, destinationURL string, modifyContentHandler ModificationHandler, subPath string, copyHandler CopyHandler) error {
-	sourceListURL := sourceURL
-	if subPath != "" {
-		sourceListURL = toolbox.URLPathJoin(sourceURL, subPath)
+//CopyOptions represents copy options
+type CopyOptions struct {
+	Concurrency           int                        //number of concurrent object transfers, 0 or 1 copies objects sequentially
+	ModificationHandler   ModificationHandler        //optional content modification handler
+	ObjectModifier        ObjectModificationHandler  //optional source object aware content modification handler, takes precedence over ModificationHandler
+	CopyHandler           CopyHandler                //optional copy handler, uploads to destination service by default
+	Mirror                bool                       //removes destination objects without corresponding source object
+	DryRun                bool                       //reports mirror deletions without deleting destination objects
+	DeletionListener      DeletionListener           //optional listener notified with each deleted (or with DryRun to be deleted) object
+	Retry                 *RetryPolicy               //optional retry policy applied to each object transfer
+	Filter                func(object Object) bool   //optional filter, filtered out folders are not descended into
+	PreserveAttributes    bool                       //applies source mode and modification time if destination service implements AttributeSetter
+	DetectContentType     bool                       //uploads content type guessed from object extension or sniffed from content if destination service implements MetadataUploader
+	ContentTypes          map[string]string          //optional extension to content type overrides used with DetectContentType, i.e. ".wasm": "application/wasm"
+	Resume                bool                       //appends only missing bytes to smaller existing destination object if destination service implements Appender
+	SymlinkMode           SymlinkMode                //file storage source symbolic link handling mode, links are followed by default
+	MaxBytesPerSecond     int64                      //global transfer rate limit shared by all workers, zero means unlimited
+	RateLimiter           RateLimiter                //optional custom rate limiter, takes precedence over MaxBytesPerSecond
+	SkipSameChecksum      string                     //checksum algorithm (md5, sha1, sha256) used to skip objects with identical destination content
+	ContinueOnError       bool                       //records object transfer failures and continues, copy returns *CopyErrors listing them
+	FailureListener       func(failure *CopyFailure) //optional listener notified with each object transfer failure
+	MaxDepth              int                        //maximum folder depth to copy, 1 copies only source URL direct children, zero means unlimited
+	SkipListener          SkipListener               //optional listener notified with each folder not descended into
+	DestinationIsFolder   bool                       //copies source content object into destination URL folder even if the folder does not exist yet
+	SourceCredential      string                     //optional CopyURL source credential file, takes precedence over credential registered with UseCredential
+	DestinationCredential string                     //optional CopyURL destination credential file, takes precedence over credential registered with UseCredential
+	ProgressListener      ProgressListener           //optional listener notified with each copied object and a final cancellation event
+	CreateDestination     bool                       //creates destination URL folder with missing parent folders before copying
+}
+
+//SkipReasonDepth represents a reason of skipping folder deeper than CopyOptions.MaxDepth
+const SkipReasonDepth = "skipped (depth)"
+
+//SkipListener represents a copy skip listener
+type SkipListener func(object Object, reason string)
+
+//CopyProgress represents a copy progress event
+type CopyProgress struct {
+	Object    Object //copied object, nil for cancellation event
+	Completed int    //number of objects copied so far
+	Cancelled bool   //true for the final event of a copy cancelled by context
+	Err       error  //context error for cancellation event
+}
+
+//ProgressListener represents a copy progress listener
+type ProgressListener func(progress *CopyProgress)
+
+//copyTask represents a content object transfer
+type copyTask struct {
+	object         Object
+	destinationURL string
+}
+
+//copier represents a copy process state
+type copier struct {
+	ctx                context.Context
+	sourceService      Service
+	sourceURL          string
+	destinationService Service
+	destinationURL     string
+	options            *CopyOptions
+	tasks              chan *copyTask
+	group              *sync.WaitGroup
+	mutex              *sync.Mutex
+	err                error
+	copied             map[string]bool
+	resumable          bool
+	serverSide         bool
+	limiter            RateLimiter
+	failures           []*CopyFailure
+	completed          int32
+	interrupted        int32
+}
+
+//interruptedError represents copy work stopped by context cancellation
+type interruptedError struct {
+	err error
+}
+
+//Error returns error message
+func (e *interruptedError) Error() string {
+	return e.err.Error()
+}
+
+//Unwrap returns underlying error
+func (e *interruptedError) Unwrap() error {
+	return e.err
+}
+
+//interrupt records that copy work was stopped by context cancellation, it returns supplied error as interruption error
+func (c *copier) interrupt(err error) error {
+	atomic.StoreInt32(&c.interrupted, 1)
+	return &interruptedError{err: err}
+}
+
+//isInterrupted returns true if any copy work was stopped by context cancellation
+func (c *copier) isInterrupted() bool {
+	return atomic.LoadInt32(&c.interrupted) == 1
+}
+
+func (c *copier) setError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.err == nil {
+		c.err = err
 	}
-	objects, err := sourceService.List(sourceListURL)
-	if err != nil {
+}
+
+func (c *copier) error() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+func (c *copier) startWorkers() {
+	if c.options.Concurrency <= 1 {
+		return
+	}
+	c.tasks = make(chan *copyTask, c.options.Concurrency)
+	for i := 0; i < c.options.Concurrency; i++ {
+		c.group.Add(1)
+		go func() {
+			defer c.group.Done()
+			for task := range c.tasks {
+				if c.error() != nil {
+					continue
+				}
+				if err := c.ctx.Err(); err != nil {
+					_ = c.interrupt(err)
+					continue
+				}
+				err := c.process(task.object, task.destinationURL)
+				if _, interrupted := err.(*interruptedError); err != nil && !interrupted {
+					c.setError(fmt.Errorf("failed to copy %v: %v", task.object.URL(), err))
+				}
+			}
+		}()
+	}
+}
+
+func (c *copier) stopWorkers() {
+	if c.tasks == nil {
+		return
+	}
+	close(c.tasks)
+	c.group.Wait()
+}
+
+//schedule transfers supplied object inline or with worker pool
+func (c *copier) schedule(object Object, destinationURL string) error {
+	if c.options.Mirror {
+		c.copied[truncatePath(urlPath(destinationURL))] = true
+	}
+	if c.tasks == nil {
+		return c.process(object, destinationURL)
+	}
+	if err := c.error(); err != nil {
 		return err
 	}
+	select {
+	case c.tasks <- &copyTask{object: object, destinationURL: destinationURL}:
+		return nil
+	case <-c.ctx.Done():
+		return c.interrupt(c.ctx.Err())
+	}
+}
 
-	for _, object := range objects {
-		if err = copyObject(object, sourceService, sourceURL, destinationService, destinationURL, modifyContentHandler, subPath, copyHandler); err != nil {
-			return err
-		}
+//copyStorageContent copies objects listed under sub path, depth represents listed objects depth relative to source URL
+func (c *copier) copyStorageContent(subPath string, depth int) error {
+	sourceListURL := c.sourceURL
+	if subPath != "" {
+		sourceListURL = toolbox.URLPathJoin(c.sourceURL, subPath)
 	}
-	return nil
+	return ListStream(c.sourceService, sourceListURL, func(object Object) (bool, error) {
+		if err := c.ctx.Err(); err != nil {
+			return false, c.interrupt(err)
+		}
+		if err := c.copyObject(object, subPath, depth); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
 }
 
-func copyObject(object Object, sourceService Service, sourceURL string, destinationService Service, destinationURL string, modifyContentHandler ModificationHandler, subPath string, copyHandler CopyHandler) error {
+func (c *copier) copyObject(object Object, subPath string, depth int) error {
 	var objectRelativePath string
-	sourceURLPath := urlPath(sourceURL)
+	sourceURLPath := urlPath(c.sourceURL)
 
 	var objectURLPath = urlPath(object.URL())
 	if object.IsFolder() {
@@ -73,64 +251,301 @@ func copyObject(object Object, sourceService Service, sourceURL string, destinat
 			return nil
 		}
 	}
+	if c.options.Filter != nil && !c.options.Filter(object) {
+		return nil
+	}
+	if c.options.SymlinkMode == SymlinkSkip && isSymlink(object) {
+		return nil
+	}
 	if len(objectURLPath) > len(sourceURLPath) {
 		objectRelativePath = objectURLPath[len(sourceURLPath):]
 		if strings.HasPrefix(objectRelativePath, "/") {
 			objectRelativePath = string(objectRelativePath[1:])
 		}
 	}
-	var destinationObjectURL = destinationURL
+	var destinationObjectURL = c.destinationURL
 	if objectRelativePath != "" {
-		destinationObjectURL = toolbox.URLPathJoin(destinationURL, objectRelativePath)
+		destinationObjectURL = toolbox.URLPathJoin(c.destinationURL, objectRelativePath)
 	}
 
 	if object.IsContent() {
-		reader, err := sourceService.Download(object)
-		if err != nil {
-			err = fmt.Errorf("unable download, %v -> %v, %v", object.URL(), destinationObjectURL, err)
-			return err
+		if objectRelativePath == "" && c.isDestinationFolder() {
+			_, sourceName := path.Split(object.URL())
+			destinationObjectURL = toolbox.URLPathJoin(c.destinationURL, sourceName)
 		}
-		defer reader.Close()
+		return c.schedule(object, destinationObjectURL)
+	}
+	if c.options.MaxDepth > 0 && depth >= c.options.MaxDepth {
+		c.skip(object, destinationObjectURL, SkipReasonDepth)
+		return nil
+	}
+	return c.copyStorageContent(objectRelativePath, depth+1)
+}
 
-		if modifyContentHandler != nil {
-			content, err := ioutil.ReadAll(reader)
-			if err != nil {
-				return err
+//isDestinationFolder returns true if source content object should be copied into destination URL folder,
+//that is if destination URL has trailing slash, DestinationIsFolder option is set or destination folder exists, otherwise destination URL is used literally
+func (c *copier) isDestinationFolder() bool {
+	if c.options.DestinationIsFolder || strings.HasSuffix(c.destinationURL, "/") {
+		return true
+	}
+	destinationObject, err := c.destinationService.StorageObject(c.destinationURL)
+	return err == nil && destinationObject != nil && destinationObject.IsFolder()
+}
+
+//skip notifies skip listener with supplied folder, mirror keeps corresponding destination folder
+func (c *copier) skip(object Object, destinationURL string, reason string) {
+	if c.options.Mirror {
+		c.copied[truncatePath(urlPath(destinationURL))] = true
+	}
+	if c.options.SkipListener != nil {
+		c.options.SkipListener(object, reason)
+	}
+}
+
+//mirror removes destination objects that have not been copied from the source, it returns true if all folder content was removed
+func (c *copier) mirror(URL string) (bool, error) {
+	objects, err := c.destinationService.List(URL)
+	if err != nil {
+		return false, err
+	}
+	var folderURLPath = truncatePath(urlPath(URL))
+	var removedAll = true
+	for _, object := range objects {
+		var objectURLPath = truncatePath(urlPath(object.URL()))
+		if objectURLPath == folderURLPath {
+			continue
+		}
+		if object.IsFolder() {
+			if c.copied[objectURLPath] {
+				removedAll = false
+				continue
 			}
-			reader = ioutil.NopCloser(bytes.NewReader(content))
-			reader, err = modifyContentHandler(reader)
+			emptied, err := c.mirror(object.URL())
 			if err != nil {
-				err = fmt.Errorf("unable modify content, %v %v %v", object.URL(), destinationObjectURL, err)
-				return err
+				return false, err
+			}
+			if !emptied {
+				removedAll = false
+				continue
 			}
+		} else if c.copied[objectURLPath] {
+			removedAll = false
+			continue
 		}
+		if err = c.delete(object); err != nil {
+			return false, err
+		}
+	}
+	return removedAll, nil
+}
 
-		if subPath == "" {
-			_, sourceName := path.Split(object.URL())
-			_, destinationName := path.Split(destinationURL)
-			if strings.HasSuffix(destinationObjectURL, "/") {
-				destinationObjectURL = toolbox.URLPathJoin(destinationObjectURL, sourceName)
-			} else {
-				destinationObject, _ := destinationService.StorageObject(destinationObjectURL)
-				if destinationObject != nil && destinationObject.IsFolder() {
-					destinationObjectURL = toolbox.URLPathJoin(destinationObjectURL, sourceName)
-				} else if destinationName != sourceName {
-					if !strings.Contains(destinationName, ".") {
-						destinationObjectURL = toolbox.URLPathJoin(destinationURL, sourceName)
-					}
+func (c *copier) delete(object Object) error {
+	if c.options.DeletionListener != nil {
+		c.options.DeletionListener(object)
+	}
+	if c.options.DryRun {
+		return nil
+	}
+	if err := c.destinationService.Delete(object); err != nil {
+		return fmt.Errorf("unable delete, %v, %v", object.URL(), err)
+	}
+	return nil
+}
 
-				}
+//process transfers supplied object, with ContinueOnError option failure is recorded instead of being returned
+func (c *copier) process(object Object, destinationObjectURL string) error {
+	download, err := c.transfer(object, destinationObjectURL)
+	if err == nil {
+		c.progress(object)
+		return nil
+	}
+	if c.ctx.Err() != nil {
+		return c.interrupt(err)
+	}
+	if !c.options.ContinueOnError {
+		return err
+	}
+	failure := &CopyFailure{SourceURL: object.URL(), DestinationURL: destinationObjectURL, Download: download, Err: err}
+	c.mutex.Lock()
+	c.failures = append(c.failures, failure)
+	c.mutex.Unlock()
+	if c.options.FailureListener != nil {
+		c.options.FailureListener(failure)
+	}
+	return nil
+}
+
+//progress counts supplied copied object and notifies progress listener
+func (c *copier) progress(object Object) {
+	completed := atomic.AddInt32(&c.completed, 1)
+	if c.options.ProgressListener != nil {
+		c.options.ProgressListener(&CopyProgress{Object: object, Completed: int(completed)})
+	}
+}
+
+//cancelled returns cancellation error with number of copied objects and notifies progress listener with final cancellation event
+func (c *copier) cancelled() error {
+	err := &CopyCancelledError{SourceURL: c.sourceURL, DestinationURL: c.destinationURL, Completed: int(atomic.LoadInt32(&c.completed)), Err: c.ctx.Err()}
+	if c.options.ProgressListener != nil {
+		c.options.ProgressListener(&CopyProgress{Completed: err.Completed, Cancelled: true, Err: err.Err})
+	}
+	return err
+}
+
+//transfer transfers supplied object, each retry re-downloads the source object, it returns true if the last failure was a download error
+func (c *copier) transfer(object Object, destinationObjectURL string) (bool, error) {
+	var lastErr error
+	var transfer = func() error {
+		lastErr = c.transferObject(object, destinationObjectURL)
+		return lastErr
+	}
+	var err error
+	if c.options.Retry == nil {
+		err = transfer()
+	} else {
+		err = c.options.Retry.RunWithContext(c.ctx, object.URL(), transfer)
+	}
+	_, download := lastErr.(*downloadError)
+	return download, err
+}
+
+//transferObject downloads supplied object to pass it with optionally modified content to copy handler
+func (c *copier) transferObject(object Object, destinationObjectURL string) error {
+	if c.options.SymlinkMode == SymlinkPreserve && isSymlink(object) {
+		if _, ok := resolveService(c.destinationService, destinationObjectURL).(*fileStorageService); ok {
+			if err := preserveSymlink(object, destinationObjectURL); err != nil {
+				return fmt.Errorf("unable preserve symlink, %v -> %v, %v", object.URL(), destinationObjectURL, err)
 			}
+			return nil
 		}
-		err = copyHandler(object, reader, destinationService, destinationObjectURL)
-		if err != nil {
+	}
+	if c.options.SkipSameChecksum != "" {
+		if same, err := c.hasSameChecksum(object, destinationObjectURL); same || err != nil {
+			return err
+		}
+	}
+	if c.resumable {
+		if resumed, err := c.resume(object, destinationObjectURL); resumed || err != nil {
 			return err
 		}
+	}
+	if c.serverSide {
+		if copied, err := c.copyServerSide(object, destinationObjectURL); copied || err != nil {
+			return err
+		}
+	}
+	reader, err := c.sourceService.Download(object)
+	if err != nil {
+		return &downloadError{fmt.Errorf("unable download, %v -> %v, %v", object.URL(), destinationObjectURL, err)}
+	}
+	reader = newContextReader(c.ctx, reader)
+	defer reader.Close()
+	reader = throttle(reader, c.limiter)
 
-	} else {
-		if err := copyStorageContent(sourceService, sourceURL, destinationService, destinationURL, modifyContentHandler, objectRelativePath, copyHandler); err != nil {
+	if c.options.ObjectModifier != nil {
+		modified, err := c.options.ObjectModifier(object, reader)
+		if err != nil {
+			err = fmt.Errorf("unable modify content, %v %v %v", object.URL(), destinationObjectURL, err)
 			return err
 		}
+		if modified != reader {
+			defer modified.Close()
+		}
+		reader = modified
+	}
+	if err = c.options.CopyHandler(object, reader, c.destinationService, destinationObjectURL); err != nil {
+		return err
+	}
+	if c.options.PreserveAttributes {
+		return c.setAttributes(object, destinationObjectURL)
+	}
+	return nil
+}
+
+//copyServerSide copies supplied object with ServerSideCopier if source and destination share a service, it returns false if streaming copy is needed
+func (c *copier) copyServerSide(object Object, destinationObjectURL string) (bool, error) {
+	serverSideCopier, ok := serverSideCopier(c.sourceService, object.URL(), c.destinationService, destinationObjectURL)
+	if !ok {
+		return false, nil
+	}
+	if err := serverSideCopier.CopyObject(object.URL(), destinationObjectURL); err != nil {
+		if err == ErrServerSideCopyNotSupported {
+			return false, nil
+		}
+		return true, err
+	}
+	if c.options.PreserveAttributes {
+		return true, c.setAttributes(object, destinationObjectURL)
+	}
+	return true, nil
+}
+
+//hasSameChecksum returns true if destination object exists with source object size and checksum
+func (c *copier) hasSameChecksum(object Object, destinationObjectURL string) (bool, error) {
+	if exists, err := c.destinationService.Exists(destinationObjectURL); err != nil || !exists {
+		return false, nil
+	}
+	destinationObject, err := c.destinationService.StorageObject(destinationObjectURL)
+	if err != nil || !destinationObject.IsContent() {
+		return false, nil
+	}
+	if sourceInfo, destinationInfo := object.FileInfo(), destinationObject.FileInfo(); sourceInfo != nil && destinationInfo != nil && sourceInfo.Size() != destinationInfo.Size() {
+		return false, nil
+	}
+	algo := c.options.SkipSameChecksum
+	sourceChecksum, err := Checksum(c.sourceService, object, algo)
+	if err != nil {
+		return false, err
+	}
+	destinationChecksum, err := Checksum(c.destinationService, destinationObject, algo)
+	if err != nil {
+		return false, err
+	}
+	return sourceChecksum == destinationChecksum, nil
+}
+
+//resume appends remaining source object bytes to smaller destination object, it returns false if object can not be resumed
+func (c *copier) resume(object Object, destinationObjectURL string) (bool, error) {
+	appender, ok := resolveService(c.destinationService, destinationObjectURL).(Appender)
+	sourceInfo := object.FileInfo()
+	if !ok || sourceInfo == nil {
+		return false, nil
+	}
+	if exists, err := c.destinationService.Exists(destinationObjectURL); err != nil || !exists {
+		return false, nil
+	}
+	destinationObject, err := c.destinationService.StorageObject(destinationObjectURL)
+	if err != nil || !destinationObject.IsContent() || destinationObject.FileInfo() == nil {
+		return false, nil
+	}
+	var offset = destinationObject.FileInfo().Size()
+	if offset >= sourceInfo.Size() {
+		return false, nil
+	}
+	reader, err := DownloadWithRange(c.sourceService, object, offset, -1)
+	if err != nil {
+		return true, &downloadError{fmt.Errorf("unable download range, %v, %v", object.URL(), err)}
+	}
+	reader = newContextReader(c.ctx, reader)
+	defer reader.Close()
+	if err = appender.Append(destinationObjectURL, throttle(reader, c.limiter)); err != nil {
+		return true, fmt.Errorf("unable append, %v, %v", destinationObjectURL, err)
+	}
+	return true, nil
+}
+
+//setAttributes applies source object mode and modification time to destination object, services without AttributeSetter are skipped
+func (c *copier) setAttributes(object Object, destinationObjectURL string) error {
+	setter, ok := resolveService(c.destinationService, destinationObjectURL).(AttributeSetter)
+	fileInfo := object.FileInfo()
+	if !ok || fileInfo == nil {
+		return nil
+	}
+	if err := setter.SetMode(destinationObjectURL, fileInfo.Mode()); err != nil {
+		return fmt.Errorf("unable set mode, %v, %v", destinationObjectURL, err)
+	}
+	if err := setter.SetModTime(destinationObjectURL, fileInfo.ModTime()); err != nil {
+		return fmt.Errorf("unable set modification time, %v, %v", destinationObjectURL, err)
 	}
 	return nil
 }
@@ -147,6 +562,76 @@ func copySourceToDestination(sourceObject Object, reader io.Reader, destinationS
 	return err
 }
 
+//newContentTypeCopyHandler returns copy handler uploading source content with detected content type, it falls back to copySourceToDestination if destination does not support metadata
+func newContentTypeCopyHandler(contentTypes map[string]string) CopyHandler {
+	return func(sourceObject Object, reader io.Reader, destinationService Service, destinationURL string) error {
+		if _, ok := resolveService(destinationService, destinationURL).(MetadataUploader); !ok {
+			return copySourceToDestination(sourceObject, reader, destinationService, destinationURL)
+		}
+		contentType, reader, err := DetectContentType(destinationURL, reader, contentTypes)
+		if err != nil {
+			return fmt.Errorf("unable detect content type, %v, %v", sourceObject.URL(), err)
+		}
+		if contentType == "" {
+			return copySourceToDestination(sourceObject, reader, destinationService, destinationURL)
+		}
+		err = UploadWithMetadata(destinationService, destinationURL, reader, map[string]string{ContentTypeKey: contentType})
+		if err != nil {
+			err = fmt.Errorf("unable upload, %v %v %v", sourceObject.URL(), destinationURL, err)
+		}
+		return err
+	}
+}
+
+//discardURL represents archive copy destination, archive copy handlers write entries instead of uploading content
+const discardURL = "mem:///dev/nul"
+
+//discardService represents archive copy destination service discarding uploaded content
+type discardService struct {
+	Service
+}
+
+//Upload discards supplied content
+func (s *discardService) Upload(URL string, reader io.Reader) error {
+	_, err := io.Copy(ioutil.Discard, reader)
+	return err
+}
+
+//UploadWithMode discards supplied content
+func (s *discardService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	return s.Upload(URL, reader)
+}
+
+//newDiscardService returns archive copy destination service, it never accumulates content in shared memory storage
+func newDiscardService() Service {
+	return &discardService{Service: NewPrivateMemoryService()}
+}
+
+//writeTarEntry writes tar header and streams content, header size is taken from source object file info, content is buffered only if size is unknown
+func writeTarEntry(archive *tar.Writer, header *tar.Header, sourceObject Object, reader io.Reader) error {
+	if fileInfo := sourceObject.FileInfo(); fileInfo != nil {
+		header.Size = fileInfo.Size()
+	} else {
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		header.Size = int64(len(data))
+		reader = bytes.NewReader(data)
+	}
+	if err := archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("unable to write tar header, %v", err)
+	}
+	written, err := io.Copy(archive, reader)
+	if err != nil {
+		return fmt.Errorf("unable to write tar content, %v", err)
+	}
+	if written != header.Size {
+		return fmt.Errorf("unable to write tar content, %v: expected %v bytes but had %v", header.Name, header.Size, written)
+	}
+	return nil
+}
+
 func getArchiveCopyHandler(archive *zip.Writer, parentURL string) CopyHandler {
 
 	return func(sourceObject Object, reader io.Reader, destinationService Service, destinationURL string) error {
@@ -155,12 +640,10 @@ func getArchiveCopyHandler(archive *zip.Writer, parentURL string) CopyHandler {
 			relativePath = strings.Replace(destinationURL, parentURL, "", 1)
 		}
 
-		header, err := zip.FileInfoHeader(sourceObject.FileInfo())
+		header, err := newZipHeader(sourceObject, archiveEntryName(relativePath), zip.Deflate)
 		if err != nil {
 			return err
 		}
-		header.Method = zip.Deflate
-		header.Name = relativePath
 		writer, err := archive.CreateHeader(header)
 		if err != nil {
 			return err
@@ -172,24 +655,153 @@ func getArchiveCopyHandler(archive *zip.Writer, parentURL string) CopyHandler {
 
 //Copy downloads objects from source URL to upload them to destination URL.
 func Copy(sourceService Service, sourceURL string, destinationService Service, destinationURL string, modifyContentHandler ModificationHandler, copyHandler CopyHandler) (err error) {
-	if copyHandler == nil {
-		copyHandler = copySourceToDestination
+	return CopyWithOptions(sourceService, sourceURL, destinationService, destinationURL, &CopyOptions{
+		ModificationHandler: modifyContentHandler,
+		CopyHandler:         copyHandler,
+	})
+}
+
+//CopyWithObjectModifier downloads objects from source URL to upload them to destination URL, content is modified by source object aware handler.
+func CopyWithObjectModifier(sourceService Service, sourceURL string, destinationService Service, destinationURL string, modifier ObjectModificationHandler, copyHandler CopyHandler) error {
+	return CopyWithOptions(sourceService, sourceURL, destinationService, destinationURL, &CopyOptions{
+		ObjectModifier: modifier,
+		CopyHandler:    copyHandler,
+	})
+}
+
+//CopyWithOptions downloads objects from source URL to upload them to destination URL with supplied options.
+func CopyWithOptions(sourceService Service, sourceURL string, destinationService Service, destinationURL string, options *CopyOptions) (err error) {
+	return CopyWithContext(context.Background(), sourceService, sourceURL, destinationService, destinationURL, options)
+}
+
+//CopyWithContext copies source URL objects to destination URL, cancelling context stops listing and scheduling, aborts in-flight downloads
+//where provider reader close interrupts pending reads and returns *CopyCancelledError wrapping context error
+func CopyWithContext(ctx context.Context, sourceService Service, sourceURL string, destinationService Service, destinationURL string, options *CopyOptions) (err error) {
+	if options == nil {
+		options = &CopyOptions{}
+	}
+	copyOptions := *options
+	if copyOptions.ObjectModifier == nil && copyOptions.ModificationHandler != nil {
+		modificationHandler := copyOptions.ModificationHandler
+		copyOptions.ObjectModifier = func(object Object, reader io.ReadCloser) (io.ReadCloser, error) {
+			return modificationHandler(reader)
+		}
+	}
+	var resumable = copyOptions.Resume && copyOptions.CopyHandler == nil && copyOptions.ObjectModifier == nil
+	var serverSide = copyOptions.CopyHandler == nil && copyOptions.ObjectModifier == nil && !copyOptions.DetectContentType &&
+		copyOptions.RateLimiter == nil && copyOptions.MaxBytesPerSecond == 0
+	if copyOptions.CopyHandler == nil {
+		copyOptions.CopyHandler = copySourceToDestination
+		if copyOptions.DetectContentType {
+			copyOptions.CopyHandler = newContentTypeCopyHandler(copyOptions.ContentTypes)
+		}
 	}
 	if strings.HasSuffix(sourceURL, "//") {
 		sourceURL = string(sourceURL[:len(sourceURL)-1])
 	}
-	err = copyStorageContent(sourceService, sourceURL, destinationService, destinationURL, modifyContentHandler, "", copyHandler)
+	if sourceService == destinationService && truncatePath(sourceURL) == truncatePath(destinationURL) {
+		return nil
+	}
+	if _, ok := resolveService(sourceService, sourceURL).(*fileStorageService); ok && copyOptions.SymlinkMode != SymlinkFollow {
+		sourceService = NewFileStorageWithSymlinkMode(copyOptions.SymlinkMode)
+	}
+	copier := &copier{
+		ctx:                ctx,
+		sourceService:      sourceService,
+		sourceURL:          sourceURL,
+		destinationService: destinationService,
+		destinationURL:     destinationURL,
+		options:            &copyOptions,
+		group:              &sync.WaitGroup{},
+		mutex:              &sync.Mutex{},
+		copied:             make(map[string]bool),
+		resumable:          resumable,
+		serverSide:         serverSide,
+		limiter:            copyOptions.RateLimiter,
+	}
+	if copier.limiter == nil && copyOptions.MaxBytesPerSecond > 0 {
+		copier.limiter = NewRateLimiter(copyOptions.MaxBytesPerSecond)
+	}
+	if copyOptions.CreateDestination {
+		if err = EnsureFolder(destinationService, destinationURL); err != nil {
+			return fmt.Errorf("failed to copy %v -> %v: %v", sourceURL, destinationURL, err)
+		}
+	}
+	copier.startWorkers()
+	err = copier.copyStorageContent("", 1)
+	copier.stopWorkers()
+	var interrupted *interruptedError
+	if errors.As(err, &interrupted) {
+		err = nil
+	}
+	if err == nil {
+		err = copier.error()
+	}
+	if err == nil && copier.isInterrupted() {
+		return copier.cancelled()
+	}
+	if err == nil && copyOptions.Mirror {
+		var exists bool
+		if exists, err = destinationService.Exists(destinationURL); err == nil && exists {
+			_, err = copier.mirror(destinationURL)
+		}
+	}
 	if err != nil {
-		err = fmt.Errorf("failed to copy %v -> %v: %v", sourceURL, destinationURL, err)
+		return fmt.Errorf("failed to copy %v -> %v: %v", sourceURL, destinationURL, err)
 	}
-	return err
+	if len(copier.failures) > 0 {
+		return &CopyErrors{Failures: copier.failures}
+	}
+	return nil
+}
+
+//CopyURL copies source URL objects to destination URL with services created for URL schemes from registered providers,
+//credentials are taken from copy options or registered with UseCredential
+func CopyURL(sourceURL, destinationURL string, options *CopyOptions) error {
+	if options == nil {
+		options = &CopyOptions{}
+	}
+	sourceService, err := newServiceWithCredential(sourceURL, options.SourceCredential)
+	if err != nil {
+		return err
+	}
+	defer sourceService.Close()
+	destinationService, err := newServiceWithCredential(destinationURL, options.DestinationCredential)
+	if err != nil {
+		return err
+	}
+	defer destinationService.Close()
+	return CopyWithOptions(sourceService, sourceURL, destinationService, destinationURL, options)
+}
+
+//newServiceWithCredential creates a service for URL scheme with supplied credential, credential registered for the scheme is used if supplied one is empty
+func newServiceWithCredential(URL, credential string) (Service, error) {
+	parsedURL, err := Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	if credential == "" {
+		credential = Credential(parsedURL.Scheme)
+	}
+	service, err := NewServiceForURL(URL, credential)
+	if err == nil {
+		return service, nil
+	}
+	switch actual := err.(type) {
+	case *ProviderError:
+		if credential == "" {
+			return nil, fmt.Errorf("failed to create %v service with provider, credential was neither supplied nor registered: %w", parsedURL.Scheme, actual.Err)
+		}
+		return nil, fmt.Errorf("failed to create %v service with provider for credential %v: %w", parsedURL.Scheme, credential, actual.Err)
+	case *UnsupportedSchemeError:
+		return nil, err
+	}
+	return nil, fmt.Errorf("failed to register %v service for %v: %w", parsedURL.Scheme, URL, err)
 }
 
 //Archive archives supplied URL assets into zip writer
 func Archive(service Service, URL string, writer *zip.Writer) error {
-	memService := NewMemoryService()
-	var destURL = "mem:///dev/nul"
-	return Copy(service, URL, memService, destURL, nil, getArchiveCopyHandler(writer, destURL))
+	return CopyWithOptions(service, URL, newDiscardService(), discardURL, &CopyOptions{CopyHandler: getArchiveCopyHandler(writer, discardURL), DestinationIsFolder: true})
 }
 
 func getArchiveCopyHandlerWithFilter(archive *zip.Writer, parentURL string, predicate func(candidate Object) bool) CopyHandler {
@@ -201,16 +813,10 @@ func getArchiveCopyHandlerWithFilter(archive *zip.Writer, parentURL string, pred
 		if destinationURL != parentURL {
 			relativePath = strings.Replace(destinationURL, parentURL, "", 1)
 		}
-		header, err := zip.FileInfoHeader(sourceObject.FileInfo())
+		header, err := newZipHeader(sourceObject, archiveEntryName(relativePath), zip.Store)
 		if err != nil {
 			return err
 		}
-		header.Method = zip.Store
-
-		if strings.HasPrefix(relativePath, "/") {
-			relativePath = string(relativePath[1:])
-		}
-		header.Name = relativePath
 		writer, err := archive.CreateHeader(header)
 		if err != nil {
 			return err
@@ -222,9 +828,7 @@ func getArchiveCopyHandlerWithFilter(archive *zip.Writer, parentURL string, pred
 
 //Archive archives supplied URL assets into zip writer with supplied filter
 func ArchiveWithFilter(service Service, URL string, writer *zip.Writer, predicate func(candidate Object) bool) error {
-	memService := NewMemoryService()
-	var destURL = "mem:///dev/nul"
-	return Copy(service, URL, memService, destURL, nil, getArchiveCopyHandlerWithFilter(writer, destURL, predicate))
+	return CopyWithOptions(service, URL, newDiscardService(), discardURL, &CopyOptions{CopyHandler: getArchiveCopyHandlerWithFilter(writer, discardURL, predicate), DestinationIsFolder: true})
 }
 
 func getTarCopyHandler(archive *tar.Writer, destParentURL, parentURL string, dirs map[string]bool) CopyHandler {
@@ -261,35 +865,86 @@ func getTarCopyHandler(archive *tar.Writer, destParentURL, parentURL string, dir
 			dirs[parent] = true
 		}
 
-		contents := new(bytes.Buffer)
-		if _, err := io.Copy(contents, reader); err != nil {
-			return err
-		}
-		data := contents.Bytes()
 		tarHeader := &tar.Header{
 			Name:    relativePath,
-			Size:    int64(len(data)),
 			Mode:    int64(sourceObject.FileInfo().Mode()),
 			ModTime: sourceObject.FileInfo().ModTime(),
 		}
-		if err := archive.WriteHeader(tarHeader); err != nil {
-			return fmt.Errorf(" unable to write tar header, %v", err)
-		}
-		if _, err := archive.Write(data); err != nil {
-			return fmt.Errorf(" unable to write tar content, %v", err)
-		}
-		return nil
+		return writeTarEntry(archive, tarHeader, sourceObject, reader)
 	}
 }
 
 //Tar tar archives supplied URL assets into zip writer
 func Tar(service Service, URL string, writer *tar.Writer, includeOwnerDir bool) error {
-	memService := NewMemoryService()
-	var destURL = "mem:///dev/nul"
 	var dirs = make(map[string]bool)
 	ownerDir := ""
 	if includeOwnerDir {
 		ownerDir = URL
 	}
-	return Copy(service, URL, memService, destURL, nil, getTarCopyHandler(writer, ownerDir, destURL, dirs))
+	return CopyWithOptions(service, URL, newDiscardService(), discardURL, &CopyOptions{CopyHandler: getTarCopyHandler(writer, ownerDir, discardURL, dirs), DestinationIsFolder: true})
+}
+
+//TarArchive archives supplied URL assets into tar writer, gzip compressed if compress flag is set
+func TarArchive(service Service, URL string, writer io.Writer, compress bool) error {
+	return TarArchiveWithFilter(service, URL, writer, compress, nil)
+}
+
+//TarArchiveWithFilter archives supplied URL assets matching predicate into tar writer, gzip compressed if compress flag is set
+func TarArchiveWithFilter(service Service, URL string, writer io.Writer, compress bool, predicate func(candidate Object) bool) (err error) {
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(writer)
+		writer = gzipWriter
+	}
+	archive := tar.NewWriter(writer)
+	var baseURLPath = urlPath(URL)
+	var headerError error
+	var entryName = func(object Object) string {
+		name := strings.Trim(strings.TrimPrefix(urlPath(object.URL()), baseURLPath), "/")
+		if name == "" {
+			name = path.Base(baseURLPath)
+		}
+		return name
+	}
+	options := &CopyOptions{
+		Filter: func(object Object) bool {
+			if headerError != nil {
+				return false
+			}
+			if object.IsContent() {
+				return predicate == nil || predicate(object)
+			}
+			header := &tar.Header{Name: entryName(object) + "/", Typeflag: tar.TypeDir, Mode: int64(DefaultFileMode)}
+			if fileInfo := object.FileInfo(); fileInfo != nil {
+				header.Mode = int64(fileInfo.Mode().Perm())
+				header.ModTime = fileInfo.ModTime()
+			}
+			if headerError = archive.WriteHeader(header); headerError != nil {
+				headerError = fmt.Errorf("unable to write tar header, %v", headerError)
+				return false
+			}
+			return true
+		},
+		CopyHandler: func(sourceObject Object, reader io.Reader, destinationService Service, destinationURL string) error {
+			header := &tar.Header{Name: entryName(sourceObject), Typeflag: tar.TypeReg, Mode: int64(fileMode)}
+			if fileInfo := sourceObject.FileInfo(); fileInfo != nil {
+				header.Mode = int64(fileInfo.Mode().Perm())
+				header.ModTime = fileInfo.ModTime()
+			}
+			return writeTarEntry(archive, header, sourceObject, reader)
+		},
+	}
+	err = CopyWithOptions(service, URL, newDiscardService(), discardURL, options)
+	if err == nil {
+		err = headerError
+	}
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if gzipWriter != nil {
+		if closeErr := gzipWriter.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
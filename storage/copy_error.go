@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+//CopyFailure represents a failed object transfer
+type CopyFailure struct {
+	SourceURL      string
+	DestinationURL string
+	Download       bool //true if source object could not be downloaded, otherwise destination upload failed
+	Err            error
+}
+
+//Error returns failure description
+func (f *CopyFailure) Error() string {
+	var stage = "upload"
+	if f.Download {
+		stage = "download"
+	}
+	return fmt.Sprintf("%v failed %v -> %v: %v", stage, f.SourceURL, f.DestinationURL, f.Err)
+}
+
+//CopyErrors represents failures collected by copy with ContinueOnError option
+type CopyErrors struct {
+	Failures []*CopyFailure
+}
+
+//Error returns all failures description
+func (e *CopyErrors) Error() string {
+	var failures = make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		failures = append(failures, failure.Error())
+	}
+	return fmt.Sprintf("failed to copy %v object(s): %v", len(e.Failures), strings.Join(failures, "; "))
+}
+
+//downloadError represents source object download error
+type downloadError struct {
+	error
+}
+
+//CopyCancelledError represents a copy stopped by context cancellation
+type CopyCancelledError struct {
+	SourceURL      string
+	DestinationURL string
+	Completed      int //number of objects copied before cancellation
+	Err            error
+}
+
+//Error returns cancellation description
+func (e *CopyCancelledError) Error() string {
+	return fmt.Sprintf("copy %v -> %v cancelled after %v completed object(s): %v", e.SourceURL, e.DestinationURL, e.Completed, e.Err)
+}
+
+//Unwrap returns context error
+func (e *CopyCancelledError) Unwrap() error {
+	return e.Err
+}
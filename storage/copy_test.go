@@ -1,15 +1,28 @@
 package storage_test
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/storage"
 	_ "github.com/viant/toolbox/storage/scp"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestCopy(t *testing.T) {
@@ -56,3 +69,971 @@ func TestArchive(t *testing.T) {
 		archive.Close()
 	}
 }
+
+//faultyService represents a service failing upload once number of uploads reaches failAfter
+type faultyService struct {
+	storage.Service
+	uploads   int32
+	failAfter int32
+	delay     time.Duration
+}
+
+func (s *faultyService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	time.Sleep(s.delay)
+	if count := atomic.AddInt32(&s.uploads, 1); s.failAfter > 0 && count >= s.failAfter {
+		return errors.New("injected failure")
+	}
+	return s.Service.UploadWithMode(URL, mode, reader)
+}
+
+func uploadTestTree(service storage.Service, baseURL string, count int) map[string]string {
+	var files = make(map[string]string)
+	for i := 0; i < count; i++ {
+		relativePath := fmt.Sprintf("dir%v/sub%v/file%v.txt", i%5, i%3, i)
+		files[relativePath] = fmt.Sprintf("content %v", i)
+		_ = service.Upload(toolbox.URLPathJoin(baseURL, relativePath), strings.NewReader(files[relativePath]))
+	}
+	return files
+}
+
+func TestCopyWithOptions(t *testing.T) {
+	{ //concurrent copy
+		service := storage.NewPrivateMemoryService()
+		files := uploadTestTree(service, "mem:///source", 300)
+		destination := &faultyService{Service: service}
+		err := storage.CopyWithOptions(service, "mem:///source", destination, "mem:///target", &storage.CopyOptions{Concurrency: 8})
+		assert.Nil(t, err)
+		assert.EqualValues(t, len(files), destination.uploads, "each object should be copied exactly once")
+		for relativePath, expected := range files {
+			reader, err := storage.Download(service, toolbox.URLPathJoin("mem:///target", relativePath))
+			if !assert.Nil(t, err, relativePath) {
+				continue
+			}
+			content, err := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.Nil(t, err)
+			assert.Equal(t, expected, string(content))
+		}
+	}
+	{ //injected failure
+		service := storage.NewPrivateMemoryService()
+		files := uploadTestTree(service, "mem:///source", 300)
+		destination := &faultyService{Service: service, failAfter: 10, delay: time.Millisecond}
+		err := storage.CopyWithOptions(service, "mem:///source", destination, "mem:///target", &storage.CopyOptions{Concurrency: 8})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "injected failure")
+			assert.Contains(t, err.Error(), "failed to copy mem:///source/")
+		}
+		assert.True(t, int(atomic.LoadInt32(&destination.uploads)) < len(files)/2, "copy should be aborted promptly")
+	}
+}
+
+func TestCopyWithOptions_Mirror(t *testing.T) {
+	var newService = func() storage.Service {
+		service := storage.NewPrivateMemoryService()
+		_ = service.Upload("mem:///source/file1.txt", strings.NewReader("abc"))
+		_ = service.Upload("mem:///source/dir/file2.txt", strings.NewReader("xyz"))
+		_ = service.Upload("mem:///target/file1.txt", strings.NewReader("old"))
+		_ = service.Upload("mem:///target/extra.txt", strings.NewReader("extra"))
+		_ = service.Upload("mem:///target/dir/file3.txt", strings.NewReader("extra"))
+		_ = service.Upload("mem:///target/stale/sub/file4.txt", strings.NewReader("extra"))
+		return service
+	}
+
+	{ //dry run
+		service := newService()
+		var deleted = make([]string, 0)
+		err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///target", &storage.CopyOptions{
+			Mirror:           true,
+			DryRun:           true,
+			DeletionListener: func(object storage.Object) { deleted = append(deleted, object.URL()) },
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 5, len(deleted), deleted)
+		for _, URL := range []string{"mem:///target/extra.txt", "mem:///target/dir/file3.txt", "mem:///target/stale/sub/file4.txt"} {
+			exists, _ := service.Exists(URL)
+			assert.True(t, exists, URL)
+		}
+	}
+	{ //mirror
+		service := newService()
+		var deleted = make(map[string]bool)
+		err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///target", &storage.CopyOptions{
+			Mirror:           true,
+			DeletionListener: func(object storage.Object) { deleted[object.URL()] = true },
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 5, len(deleted))
+		for _, URL := range []string{"mem:///target/extra.txt", "mem:///target/dir/file3.txt", "mem:///target/stale/sub/file4.txt", "mem:///target/stale"} {
+			exists, _ := service.Exists(URL)
+			assert.False(t, exists, URL)
+		}
+		for _, URL := range []string{"mem:///target/file1.txt", "mem:///target/dir/file2.txt"} {
+			exists, _ := service.Exists(URL)
+			assert.True(t, exists, URL)
+		}
+		text, err := storage.DownloadText(service, "mem:///target/file1.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", text)
+	}
+	{ //destination equals source
+		service := newService()
+		var deleted = 0
+		err := storage.CopyWithOptions(service, "mem:///source/", service, "mem:///source", &storage.CopyOptions{
+			Mirror:           true,
+			DeletionListener: func(object storage.Object) { deleted++ },
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, deleted)
+		for _, URL := range []string{"mem:///source/file1.txt", "mem:///source/dir/file2.txt"} {
+			exists, _ := service.Exists(URL)
+			assert.True(t, exists, URL)
+		}
+	}
+}
+
+//flakyService represents a service failing the first downloads and uploads
+type flakyService struct {
+	storage.Service
+	downloadFailures int32
+	uploadFailures   int32
+	downloads        int32
+	uploads          int32
+	err              error
+}
+
+func (s *flakyService) Download(object storage.Object) (io.ReadCloser, error) {
+	if atomic.AddInt32(&s.downloads, 1) <= s.downloadFailures {
+		return nil, s.err
+	}
+	return s.Service.Download(object)
+}
+
+func (s *flakyService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	if atomic.AddInt32(&s.uploads, 1) <= s.uploadFailures {
+		_, _ = ioutil.ReadAll(reader)
+		return s.err
+	}
+	return s.Service.UploadWithMode(URL, mode, reader)
+}
+
+func TestCopyWithOptions_Retry(t *testing.T) {
+	var errUnavailable = errors.New("503 service unavailable")
+	var errNotFound = errors.New("object not found")
+	var isRetryable = func(err error) bool {
+		return !strings.Contains(err.Error(), errNotFound.Error())
+	}
+
+	{ //transient failures
+		memService := storage.NewPrivateMemoryService()
+		_ = memService.Upload("mem:///source/file1.txt", strings.NewReader("abc"))
+		service := &flakyService{Service: memService, downloadFailures: 2, uploadFailures: 2, err: errUnavailable}
+		err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///target", &storage.CopyOptions{
+			Retry: &storage.RetryPolicy{MaxAttempts: 5, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, IsRetryable: isRetryable},
+		})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 5, service.downloads, "each attempt should re-download source")
+		text, err := storage.DownloadText(memService, "mem:///target/file1.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", text)
+	}
+	{ //attempts exhausted
+		memService := storage.NewPrivateMemoryService()
+		_ = memService.Upload("mem:///source/file1.txt", strings.NewReader("abc"))
+		service := &flakyService{Service: memService, downloadFailures: 10, err: errUnavailable}
+		err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///target", &storage.CopyOptions{
+			Retry: &storage.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, IsRetryable: isRetryable},
+		})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "mem:///source/file1.txt")
+			assert.Contains(t, err.Error(), "3 attempt(s)")
+		}
+		assert.EqualValues(t, 3, service.downloads)
+	}
+	{ //non retryable error
+		memService := storage.NewPrivateMemoryService()
+		_ = memService.Upload("mem:///source/file1.txt", strings.NewReader("abc"))
+		service := &flakyService{Service: memService, downloadFailures: 10, err: errNotFound}
+		err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///target", &storage.CopyOptions{
+			Retry: &storage.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, IsRetryable: isRetryable},
+		})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "1 attempt(s)")
+		}
+		assert.EqualValues(t, 1, service.downloads)
+	}
+}
+
+//listingService represents a service recording listed URLs
+type listingService struct {
+	storage.Service
+	listed []string
+}
+
+func (s *listingService) List(URL string) ([]storage.Object, error) {
+	s.listed = append(s.listed, URL)
+	return s.Service.List(URL)
+}
+
+func TestCopyWithOptions_Filter(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	for _, file := range []string{"config.json", "readme.md", "app/app.json", "app/app.js", "app/node_modules/lib/package.json", "app/test/fixture.json"} {
+		_ = memService.Upload(toolbox.URLPathJoin("mem:///source", file), strings.NewReader(file))
+	}
+
+	var useCases = []struct {
+		description string
+		include     []string
+		exclude     []string
+		expected    []string
+	}{
+		{
+			description: "no patterns",
+			expected:    []string{"config.json", "readme.md", "app/app.json", "app/app.js", "app/node_modules/lib/package.json", "app/test/fixture.json"},
+		},
+		{
+			description: "include only",
+			include:     []string{"**/*.json"},
+			expected:    []string{"config.json", "app/app.json", "app/node_modules/lib/package.json", "app/test/fixture.json"},
+		},
+		{
+			description: "exclude takes precedence",
+			include:     []string{"**/*.json", "*.md"},
+			exclude:     []string{"**/node_modules/**", "app/test/*.json"},
+			expected:    []string{"config.json", "readme.md", "app/app.json"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		service := &listingService{Service: memService}
+		targetURL := "mem:///target/" + strings.Replace(useCase.description, " ", "_", -1)
+		err := storage.CopyWithOptions(service, "mem:///source", service, targetURL, &storage.CopyOptions{
+			Filter: storage.NewPatternFilter("mem:///source", useCase.include, useCase.exclude),
+		})
+		assert.Nil(t, err, useCase.description)
+		var actual = make([]string, 0)
+		for _, file := range []string{"config.json", "readme.md", "app/app.json", "app/app.js", "app/node_modules/lib/package.json", "app/test/fixture.json"} {
+			if exists, _ := memService.Exists(toolbox.URLPathJoin(targetURL, file)); exists {
+				actual = append(actual, file)
+			}
+		}
+		assert.EqualValues(t, useCase.expected, actual, useCase.description)
+		if len(useCase.exclude) > 0 {
+			for _, URL := range service.listed {
+				assert.False(t, strings.Contains(URL, "node_modules"), useCase.description+": "+URL)
+			}
+		}
+	}
+}
+
+func TestCopyWithOptions_PreserveAttributes(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_preserve_attributes")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	sourceScript := path.Join(parent, "source", "bin", "run.sh")
+	_ = toolbox.CreateDirIfNotExist(path.Dir(sourceScript))
+	if !assert.Nil(t, ioutil.WriteFile(sourceScript, []byte("#!/bin/sh\necho test\n"), 0644)) {
+		return
+	}
+	modTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	assert.Nil(t, os.Chmod(sourceScript, 0750))
+	assert.Nil(t, os.Chtimes(sourceScript, modTime, modTime))
+
+	service := storage.NewService()
+	err := storage.CopyWithOptions(service, "file://"+path.Join(parent, "source"), service, "file://"+path.Join(parent, "target"), &storage.CopyOptions{
+		PreserveAttributes: true,
+	})
+	assert.Nil(t, err)
+	info, err := os.Stat(path.Join(parent, "target", "bin", "run.sh"))
+	if assert.Nil(t, err) {
+		assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+		assert.True(t, modTime.Equal(info.ModTime()), info.ModTime())
+	}
+}
+
+func TestTarArchive(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	_ = service.Upload("mem:///archive/file1.txt", strings.NewReader("abc"))
+	_ = service.Upload("mem:///archive/config/test.prop", strings.NewReader("123"))
+	_ = service.Upload("mem:///archive/empty/placeholder", strings.NewReader(""))
+	_ = service.Upload("mem:///archive/skip/file.log", strings.NewReader("log"))
+	placeholder, _ := service.StorageObject("mem:///archive/empty/placeholder")
+	assert.Nil(t, service.Delete(placeholder))
+
+	for _, compress := range []bool{false, true} {
+		buffer := new(bytes.Buffer)
+		err := storage.TarArchiveWithFilter(service, "mem:///archive", buffer, compress, func(candidate storage.Object) bool {
+			return !strings.HasSuffix(candidate.URL(), ".log")
+		})
+		if !assert.Nil(t, err) {
+			continue
+		}
+		var reader io.Reader = buffer
+		if compress {
+			gzipReader, err := gzip.NewReader(buffer)
+			if !assert.Nil(t, err) {
+				continue
+			}
+			reader = gzipReader
+		}
+		var actual = make(map[string]string)
+		tarReader := tar.NewReader(reader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			if !assert.Nil(t, err) {
+				break
+			}
+			content, _ := ioutil.ReadAll(tarReader)
+			assert.False(t, header.ModTime.IsZero(), header.Name)
+			if header.Typeflag == tar.TypeDir {
+				actual[header.Name] = "dir"
+				continue
+			}
+			actual[header.Name] = string(content)
+		}
+		assert.EqualValues(t, map[string]string{
+			"file1.txt":        "abc",
+			"config/":          "dir",
+			"config/test.prop": "123",
+			"empty/":           "dir",
+			"skip/":            "dir",
+		}, actual, fmt.Sprintf("compress: %v", compress))
+	}
+}
+
+type fakeClock struct {
+	mutex *sync.Mutex
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+	c.slept += duration
+}
+
+func TestCopyWithOptions_RateLimiter(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	var totalBytes = 0
+	for i := 0; i < 20; i++ {
+		content := strings.Repeat("x", 500)
+		totalBytes += len(content)
+		_ = service.Upload(fmt.Sprintf("mem:///source/file%02d.txt", i), strings.NewReader(content))
+	}
+	clock := &fakeClock{mutex: &sync.Mutex{}, now: time.Now()}
+	var bytesPerSecond int64 = 1000
+	err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///target", &storage.CopyOptions{
+		Concurrency: 4,
+		RateLimiter: storage.NewRateLimiterWithClock(bytesPerSecond, clock),
+	})
+	assert.Nil(t, err)
+	expected := time.Duration(totalBytes) * time.Second / time.Duration(bytesPerSecond)
+	assert.True(t, clock.slept >= expected, fmt.Sprintf("expected at least %v, but had %v", expected, clock.slept))
+
+	objects, err := service.List("mem:///target")
+	assert.Nil(t, err)
+	assert.Equal(t, 21, len(objects))
+
+	{ //zero means unlimited
+		err := storage.CopyWithOptions(service, "mem:///source", service, "mem:///unlimited", &storage.CopyOptions{MaxBytesPerSecond: 0})
+		assert.Nil(t, err)
+	}
+}
+
+func TestArchiveWithOptions(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	for _, name := range []string{"b/file2.txt", "a/file1.txt", "c.txt", "a/sub/file3.txt"} {
+		_ = service.Upload("mem:///archive/"+name, strings.NewReader(strings.Repeat(name, 20)))
+	}
+	timestamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	bestCompression := flate.BestCompression
+	options := &storage.ArchiveOptions{
+		SortEntries:        true,
+		FixedTimestamp:     &timestamp,
+		CompressionLevel:   &bestCompression,
+		IncludeDirectories: true,
+	}
+	var archives = make([][]byte, 0)
+	for i := 0; i < 2; i++ {
+		buffer := new(bytes.Buffer)
+		writer := zip.NewWriter(buffer)
+		if !assert.Nil(t, storage.ArchiveWithOptions(service, "mem:///archive", writer, options)) {
+			return
+		}
+		assert.Nil(t, writer.Close())
+		archives = append(archives, buffer.Bytes())
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, bytes.Equal(archives[0], archives[1]), "archives should be byte identical")
+
+	reader, err := zip.NewReader(bytes.NewReader(archives[0]), int64(len(archives[0])))
+	if !assert.Nil(t, err) {
+		return
+	}
+	var names = make([]string, 0)
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		assert.True(t, timestamp.Equal(file.Modified), file.Name)
+	}
+	assert.Equal(t, []string{"a/", "a/file1.txt", "a/sub/", "a/sub/file3.txt", "b/", "b/file2.txt", "c.txt"}, names)
+
+	{ //without directories
+		buffer := new(bytes.Buffer)
+		writer := zip.NewWriter(buffer)
+		assert.Nil(t, storage.ArchiveWithOptions(service, "mem:///archive/", writer, &storage.ArchiveOptions{SortEntries: true}))
+		assert.Nil(t, writer.Close())
+		reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		if assert.Nil(t, err) {
+			assert.Equal(t, 4, len(reader.File))
+			assert.Equal(t, "a/file1.txt", reader.File[0].Name)
+		}
+	}
+	{ //no compression level
+		noCompression := flate.NoCompression
+		for _, level := range []*int{nil, &noCompression} {
+			buffer := new(bytes.Buffer)
+			writer := zip.NewWriter(buffer)
+			assert.Nil(t, storage.ArchiveWithOptions(service, "mem:///archive/", writer, &storage.ArchiveOptions{CompressionLevel: level}))
+			assert.Nil(t, writer.Close())
+			reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+			if assert.Nil(t, err) && assert.True(t, len(reader.File) > 0) {
+				file := reader.File[0]
+				assert.Equal(t, level != nil, file.CompressedSize64 >= file.UncompressedSize64, fmt.Sprintf("%v: %v -> %v", file.Name, file.UncompressedSize64, file.CompressedSize64))
+			}
+		}
+	}
+}
+
+func TestArchive_EntryNames(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	_ = service.Upload("mem:///names/dir/file1.txt", strings.NewReader("abc"))
+	for _, archive := range []func(writer *zip.Writer) error{
+		func(writer *zip.Writer) error {
+			return storage.Archive(service, "mem:///names", writer)
+		},
+		func(writer *zip.Writer) error {
+			return storage.ArchiveWithFilter(service, "mem:///names", writer, func(candidate storage.Object) bool { return true })
+		},
+	} {
+		buffer := new(bytes.Buffer)
+		writer := zip.NewWriter(buffer)
+		assert.Nil(t, archive(writer))
+		assert.Nil(t, writer.Close())
+		reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		if assert.Nil(t, err) && assert.Equal(t, 1, len(reader.File)) {
+			assert.Equal(t, "dir/file1.txt", reader.File[0].Name)
+		}
+	}
+}
+
+//streamingService represents a service returning tracked download readers
+type streamingService struct {
+	storage.Service
+}
+
+type trackedReader struct {
+	io.ReadCloser
+}
+
+func (s *streamingService) Download(object storage.Object) (io.ReadCloser, error) {
+	reader, err := s.Service.Download(object)
+	if err != nil {
+		return nil, err
+	}
+	return &trackedReader{reader}, nil
+}
+
+func TestCopyWithObjectModifier(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	_ = service.Upload("mem:///source/config.yaml", strings.NewReader("name: $name"))
+	_ = service.Upload("mem:///source/data.bin", strings.NewReader("$name"))
+	_ = service.Upload("mem:///source/sub/app.yaml", strings.NewReader("app: $name"))
+
+	var modified = make(map[string]bool)
+	var mutex = &sync.Mutex{}
+	modifier := func(object storage.Object, reader io.ReadCloser) (io.ReadCloser, error) {
+		if !strings.HasSuffix(object.URL(), ".yaml") {
+			return reader, nil
+		}
+		mutex.Lock()
+		modified[object.URL()] = true
+		mutex.Unlock()
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(strings.NewReader(strings.Replace(string(content), "$name", "test", -1))), nil
+	}
+	var streamed = make(map[string]bool)
+	copyHandler := func(sourceObject storage.Object, reader io.Reader, destinationService storage.Service, destinationURL string) error {
+		if _, ok := reader.(*trackedReader); ok {
+			mutex.Lock()
+			streamed[sourceObject.URL()] = true
+			mutex.Unlock()
+		}
+		return destinationService.Upload(destinationURL, reader)
+	}
+	err := storage.CopyWithObjectModifier(&streamingService{service}, "mem:///source", service, "mem:///target", modifier, copyHandler)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"mem:///source/config.yaml": true, "mem:///source/sub/app.yaml": true}, modified)
+	assert.Equal(t, map[string]bool{"mem:///source/data.bin": true}, streamed)
+
+	var expected = map[string]string{
+		"config.yaml":  "name: test",
+		"data.bin":     "$name",
+		"sub/app.yaml": "app: test",
+	}
+	for name, expect := range expected {
+		reader, err := storage.Download(service, "mem:///target/"+name)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		content, _ := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		assert.Equal(t, expect, string(content), name)
+	}
+
+	{ //legacy modification handler
+		err := storage.Copy(service, "mem:///source/data.bin", service, "mem:///legacy/data.bin", func(reader io.ReadCloser) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader("legacy")), nil
+		}, nil)
+		assert.Nil(t, err)
+		reader, err := storage.Download(service, "mem:///legacy/data.bin")
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			assert.Equal(t, "legacy", string(content))
+		}
+	}
+}
+
+//brokenService represents a service failing download or upload of objects with supplied suffixes
+type brokenService struct {
+	storage.Service
+	downloadSuffix string
+	uploadSuffix   string
+}
+
+func (s *brokenService) Download(object storage.Object) (io.ReadCloser, error) {
+	if strings.HasSuffix(object.URL(), s.downloadSuffix) {
+		return nil, errors.New("unreadable")
+	}
+	return s.Service.Download(object)
+}
+
+func (s *brokenService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	if strings.HasSuffix(URL, s.uploadSuffix) {
+		return errors.New("unwritable")
+	}
+	return s.Service.UploadWithMode(URL, mode, reader)
+}
+
+func TestCopyWithOptions_ContinueOnError(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		service := storage.NewPrivateMemoryService()
+		files := uploadTestTree(service, "mem:///source", 30)
+		broken := &brokenService{Service: service, downloadSuffix: "/file7.txt", uploadSuffix: "/file12.txt"}
+		var notified = make(map[string]bool)
+		var mutex = &sync.Mutex{}
+		err := storage.CopyWithOptions(broken, "mem:///source", broken, "mem:///target", &storage.CopyOptions{
+			Concurrency:     concurrency,
+			ContinueOnError: true,
+			FailureListener: func(failure *storage.CopyFailure) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				notified[failure.SourceURL] = true
+			},
+		})
+		copyErrors, ok := err.(*storage.CopyErrors)
+		if !assert.True(t, ok, fmt.Sprintf("expected *storage.CopyErrors, but had %T", err)) {
+			continue
+		}
+		if assert.Equal(t, 2, len(copyErrors.Failures)) {
+			var failures = make(map[string]*storage.CopyFailure)
+			for _, failure := range copyErrors.Failures {
+				failures[path.Base(failure.SourceURL)] = failure
+			}
+			if failure, ok := failures["file7.txt"]; assert.True(t, ok) {
+				assert.True(t, failure.Download)
+				assert.Equal(t, "mem:///target/dir2/sub1/file7.txt", failure.DestinationURL)
+			}
+			if failure, ok := failures["file12.txt"]; assert.True(t, ok) {
+				assert.False(t, failure.Download)
+				assert.Contains(t, failure.Err.Error(), "unwritable")
+			}
+		}
+		assert.Equal(t, 2, len(notified))
+		assert.Contains(t, err.Error(), "failed to copy 2 object(s)")
+
+		for relativePath, expected := range files {
+			reader, err := storage.Download(service, toolbox.URLPathJoin("mem:///target", relativePath))
+			if strings.HasSuffix(relativePath, "/file7.txt") || strings.HasSuffix(relativePath, "/file12.txt") {
+				assert.NotNil(t, err, relativePath)
+				continue
+			}
+			if !assert.Nil(t, err, relativePath) {
+				continue
+			}
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.Equal(t, expected, string(content), relativePath)
+		}
+	}
+}
+
+func TestCopyWithOptions_MaxDepth(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	var files = []string{"a.txt", "l1/b.txt", "l1/l2/c.txt", "l1/l2/l3/d.txt"}
+	for _, file := range files {
+		_ = memService.Upload(toolbox.URLPathJoin("mem:///source", file), strings.NewReader(file))
+	}
+	var useCases = []struct {
+		description   string
+		maxDepth      int
+		expected      []string
+		expectSkipped []string
+	}{
+		{
+			description:   "direct children",
+			maxDepth:      1,
+			expected:      []string{"a.txt"},
+			expectSkipped: []string{"mem:///source/l1"},
+		},
+		{
+			description:   "two levels",
+			maxDepth:      2,
+			expected:      []string{"a.txt", "l1/b.txt"},
+			expectSkipped: []string{"mem:///source/l1/l2"},
+		},
+		{
+			description:   "unlimited",
+			expected:      files,
+			expectSkipped: []string{},
+		},
+	}
+	for _, useCase := range useCases {
+		targetURL := "mem:///target/" + strings.Replace(useCase.description, " ", "_", -1)
+		var skipped = make([]string, 0)
+		err := storage.CopyWithOptions(memService, "mem:///source", memService, targetURL, &storage.CopyOptions{
+			MaxDepth: useCase.maxDepth,
+			SkipListener: func(object storage.Object, reason string) {
+				assert.Equal(t, storage.SkipReasonDepth, reason)
+				skipped = append(skipped, strings.TrimSuffix(object.URL(), "/"))
+			},
+		})
+		assert.Nil(t, err, useCase.description)
+		var actual = make([]string, 0)
+		for _, file := range files {
+			if exists, _ := memService.Exists(toolbox.URLPathJoin(targetURL, file)); exists {
+				actual = append(actual, file)
+			}
+		}
+		assert.EqualValues(t, useCase.expected, actual, useCase.description)
+		assert.EqualValues(t, useCase.expectSkipped, skipped, useCase.description)
+	}
+}
+
+func TestCopyWithOptions_DestinationResolution(t *testing.T) {
+	var useCases = []struct {
+		description         string
+		sourceURL           string
+		destinationURL      string
+		destinationIsFolder bool
+		expectURL           string
+	}{
+		{
+			description:    "trailing slash",
+			sourceURL:      "mem:///src/README.md",
+			destinationURL: "mem:///dst/v1.2.3/",
+			expectURL:      "mem:///dst/v1.2.3/README.md",
+		},
+		{
+			description:    "existing folder",
+			sourceURL:      "mem:///src/README.md",
+			destinationURL: "mem:///dst/existing",
+			expectURL:      "mem:///dst/existing/README.md",
+		},
+		{
+			description:    "existing file",
+			sourceURL:      "mem:///src/README.md",
+			destinationURL: "mem:///dst/old.md",
+			expectURL:      "mem:///dst/old.md",
+		},
+		{
+			description:    "non existent with dot",
+			sourceURL:      "mem:///src/README.md",
+			destinationURL: "mem:///dst/v1.2.3",
+			expectURL:      "mem:///dst/v1.2.3",
+		},
+		{
+			description:    "non existent without dot",
+			sourceURL:      "mem:///src/README.md",
+			destinationURL: "mem:///dst/readme",
+			expectURL:      "mem:///dst/readme",
+		},
+		{
+			description:         "non existent folder override",
+			sourceURL:           "mem:///src/README.md",
+			destinationURL:      "mem:///dst/v1.2.3",
+			destinationIsFolder: true,
+			expectURL:           "mem:///dst/v1.2.3/README.md",
+		},
+		{
+			description:    "source folder",
+			sourceURL:      "mem:///src",
+			destinationURL: "mem:///dst/v1.2.3",
+			expectURL:      "mem:///dst/v1.2.3/README.md",
+		},
+	}
+	for _, useCase := range useCases {
+		service := storage.NewPrivateMemoryService()
+		_ = service.Upload("mem:///src/README.md", strings.NewReader("readme"))
+		_ = service.Upload("mem:///dst/existing/other.md", strings.NewReader("other"))
+		_ = service.Upload("mem:///dst/old.md", strings.NewReader("old"))
+		err := storage.CopyWithOptions(service, useCase.sourceURL, service, useCase.destinationURL, &storage.CopyOptions{DestinationIsFolder: useCase.destinationIsFolder})
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		content, err := storage.DownloadText(service, useCase.expectURL)
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, "readme", content, useCase.description)
+	}
+}
+
+//slowService represents a service with slow downloads, pending reads are aborted when download reader is closed
+type slowService struct {
+	storage.Service
+	latency time.Duration
+}
+
+type slowReader struct {
+	io.ReadCloser
+	latency time.Duration
+	closed  chan bool
+	once    *sync.Once
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	select {
+	case <-time.After(r.latency):
+		return r.ReadCloser.Read(p)
+	case <-r.closed:
+		return 0, errors.New("read on closed reader")
+	}
+}
+
+func (r *slowReader) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return r.ReadCloser.Close()
+}
+
+func (s *slowService) Download(object storage.Object) (io.ReadCloser, error) {
+	reader, err := s.Service.Download(object)
+	if err != nil {
+		return nil, err
+	}
+	return &slowReader{ReadCloser: reader, latency: s.latency, closed: make(chan bool), once: &sync.Once{}}, nil
+}
+
+func TestCopyWithContext(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	for i := 0; i < 20; i++ {
+		_ = memService.Upload(fmt.Sprintf("mem:///cancel/source/file%02d.txt", i), strings.NewReader("abc"))
+	}
+	sourceService := &slowService{Service: memService, latency: 50 * time.Millisecond}
+	destinationService := &countingService{Service: memService}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var cancelled time.Time
+	var events = make([]*storage.CopyProgress, 0)
+	var mutex = &sync.Mutex{}
+	err := storage.CopyWithContext(ctx, sourceService, "mem:///cancel/source", destinationService, "mem:///cancel/target", &storage.CopyOptions{
+		Concurrency: 2,
+		Retry:       &storage.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second},
+		ProgressListener: func(progress *storage.CopyProgress) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			events = append(events, progress)
+			if progress.Completed == 3 {
+				cancelled = time.Now()
+				cancel()
+			}
+		},
+	})
+	elapsed := time.Now().Sub(cancelled)
+	uploads := atomic.LoadInt32(&destinationService.uploads)
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, context.Canceled))
+		cancelledErr, ok := err.(*storage.CopyCancelledError)
+		if assert.True(t, ok) {
+			assert.True(t, cancelledErr.Completed >= 3 && cancelledErr.Completed < 20, fmt.Sprintf("completed %v", cancelledErr.Completed))
+			assert.True(t, int(uploads) <= cancelledErr.Completed+2, fmt.Sprintf("uploads %v", uploads))
+		}
+	}
+	assert.True(t, elapsed < 40*time.Millisecond, fmt.Sprintf("elapsed %v", elapsed))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uploads, atomic.LoadInt32(&destinationService.uploads))
+	mutex.Lock()
+	defer mutex.Unlock()
+	if assert.True(t, len(events) > 0) {
+		last := events[len(events)-1]
+		assert.True(t, last.Cancelled)
+		assert.Equal(t, context.Canceled, last.Err)
+	}
+}
+
+type failingUploadService struct {
+	storage.Service
+	failSuffix string
+	onFailure  func()
+}
+
+func (s *failingUploadService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	if strings.HasSuffix(URL, s.failSuffix) {
+		s.onFailure()
+		return errors.New("disk full")
+	}
+	return s.Service.UploadWithMode(URL, mode, reader)
+}
+
+func TestCopyWithContext_Outcome(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	for i := 0; i < 3; i++ {
+		_ = memService.Upload(fmt.Sprintf("mem:///outcome/source/file%02d.txt", i), strings.NewReader("abc"))
+	}
+	for _, concurrency := range []int{1, 2} { //cancel after the last object completed
+		ctx, cancel := context.WithCancel(context.Background())
+		err := storage.CopyWithContext(ctx, memService, "mem:///outcome/source", memService, fmt.Sprintf("mem:///outcome/completed%v", concurrency), &storage.CopyOptions{
+			Concurrency: concurrency,
+			ProgressListener: func(progress *storage.CopyProgress) {
+				if progress.Completed == 3 {
+					cancel()
+				}
+			},
+		})
+		assert.Nil(t, err, fmt.Sprintf("concurrency %v", concurrency))
+		cancel()
+	}
+	{ //real worker error is preferred over later cancellation
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		destinationService := &failingUploadService{Service: memService, failSuffix: "file00.txt", onFailure: func() {
+			time.AfterFunc(5*time.Millisecond, cancel)
+		}}
+		sourceService := &slowService{Service: memService, latency: 30 * time.Millisecond}
+		err := storage.CopyWithContext(ctx, sourceService, "mem:///outcome/source", destinationService, "mem:///outcome/failed", &storage.CopyOptions{Concurrency: 2})
+		if assert.NotNil(t, err) {
+			_, cancelled := err.(*storage.CopyCancelledError)
+			assert.False(t, cancelled, err.Error())
+			assert.True(t, strings.Contains(err.Error(), "disk full"), err.Error())
+		}
+	}
+}
+
+func TestUploadWithContext(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, storage.UploadWithContext(ctx, service, fmt.Sprintf("mem:///upload_context/file%02d.txt", i), strings.NewReader("abc")))
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, runtime.NumGoroutine() <= before+2, fmt.Sprintf("goroutines before %v, after %v", before, runtime.NumGoroutine()))
+	reader, err := storage.Download(service, "mem:///upload_context/file49.txt")
+	if assert.Nil(t, err) {
+		content, _ := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		assert.Equal(t, "abc", string(content))
+	}
+}
+
+//largeObject represents a content object reporting size without backing content
+type largeObject struct {
+	*storage.AbstractObject
+}
+
+func (o *largeObject) Unwrap(target interface{}) error {
+	return nil
+}
+
+func newLargeObject(URL string, size int64, isDir bool) storage.Object {
+	mode := os.FileMode(0644)
+	if isDir {
+		mode = os.ModeDir | 0755
+	}
+	result := &largeObject{AbstractObject: storage.NewAbstractStorageObject(URL, nil, storage.NewFileInfo(path.Base(URL), size, mode, time.Now(), isDir))}
+	result.AbstractObject.Object = result
+	return result
+}
+
+//largeService represents a service listing a single large object streamed as zeros
+type largeService struct {
+	storage.Service
+	size int64
+}
+
+type zeroReader struct{}
+
+func (r zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func (s *largeService) List(URL string) ([]storage.Object, error) {
+	return []storage.Object{newLargeObject("mem:///large", 0, true), newLargeObject("mem:///large/big.bin", s.size, false)}, nil
+}
+
+func (s *largeService) Download(object storage.Object) (io.ReadCloser, error) {
+	return ioutil.NopCloser(io.LimitReader(zeroReader{}, object.FileInfo().Size())), nil
+}
+
+func TestArchive_Zip64(t *testing.T) {
+	if os.Getenv("TOOLBOX_LARGE_ARCHIVE_TEST") == "" {
+		t.Skip("TOOLBOX_LARGE_ARCHIVE_TEST was not set")
+	}
+	var size = int64(1<<32 + 1024)
+	service := &largeService{Service: storage.NewPrivateMemoryService(), size: size}
+	{ //zip
+		buffer := new(bytes.Buffer)
+		writer := zip.NewWriter(buffer)
+		assert.Nil(t, storage.Archive(service, "mem:///large", writer))
+		assert.Nil(t, writer.Close())
+		reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		if assert.Nil(t, err) && assert.Equal(t, 1, len(reader.File)) {
+			assert.Equal(t, "big.bin", reader.File[0].Name)
+			assert.EqualValues(t, size, reader.File[0].UncompressedSize64)
+			entry, err := reader.File[0].Open()
+			if assert.Nil(t, err) {
+				read, err := io.Copy(ioutil.Discard, entry)
+				assert.Nil(t, err)
+				assert.Equal(t, size, read)
+				_ = entry.Close()
+			}
+		}
+	}
+	{ //tar
+		buffer := new(bytes.Buffer)
+		assert.Nil(t, storage.TarArchive(service, "mem:///large", buffer, true))
+		gzipReader, err := gzip.NewReader(buffer)
+		if assert.Nil(t, err) {
+			header, err := tar.NewReader(gzipReader).Next()
+			if assert.Nil(t, err) {
+				assert.Equal(t, "big.bin", header.Name)
+				assert.Equal(t, size, header.Size)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+//DeleteAll removes supplied URL object, folders are removed with all their content, children before parents,
+//non existing URL is not an error, failures are aggregated into a single error listing URLs that could not be removed
+func DeleteAll(service Service, URL string) error {
+	objects, err := listForDeletion(service, URL)
+	if err != nil || len(objects) == 0 {
+		return err
+	}
+	var failures = make([]string, 0)
+	for _, object := range objects {
+		if err := service.Delete(object); err != nil {
+			if exists, existsErr := service.Exists(object.URL()); existsErr == nil && !exists {
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("%v: %v", object.URL(), err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to delete %v object(s) of %v: %v", len(failures), URL, strings.Join(failures, ", "))
+	}
+	return nil
+}
+
+//DeleteAllDryRun returns URLs that DeleteAll would remove in deletion order
+func DeleteAllDryRun(service Service, URL string) ([]string, error) {
+	objects, err := listForDeletion(service, URL)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0, len(objects))
+	for _, object := range objects {
+		result = append(result, object.URL())
+	}
+	return result, nil
+}
+
+//listForDeletion returns supplied URL object with all descendants in depth first order, children go before parents
+func listForDeletion(service Service, URL string) ([]Object, error) {
+	exists, err := service.Exists(URL)
+	if err != nil || !exists {
+		return nil, err
+	}
+	object, err := service.StorageObject(URL)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]Object, 0)
+	err = collectForDeletion(service, object, &result)
+	return result, err
+}
+
+func collectForDeletion(service Service, object Object, result *[]Object) error {
+	if object.IsFolder() {
+		objects, err := service.List(object.URL())
+		if err != nil {
+			return err
+		}
+		var folderPath = urlPath(object.URL())
+		for _, candidate := range objects {
+			if urlPath(candidate.URL()) == folderPath {
+				continue
+			}
+			if err = collectForDeletion(service, candidate, result); err != nil {
+				return err
+			}
+		}
+	}
+	*result = append(*result, object)
+	return nil
+}
@@ -0,0 +1,104 @@
+package storage_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+//undeletableService represents a service failing deletion of supplied URL suffixes
+type undeletableService struct {
+	storage.Service
+	suffixes []string
+}
+
+func (s *undeletableService) Delete(object storage.Object) error {
+	for _, suffix := range s.suffixes {
+		if strings.HasSuffix(object.URL(), suffix) {
+			return errors.New("permission denied")
+		}
+	}
+	return s.Service.Delete(object)
+}
+
+func TestDeleteAll(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_delete_all")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	fileService := storage.NewFileStorage()
+
+	var useCases = []struct {
+		description string
+		service     storage.Service
+		baseURL     string
+	}{
+		{
+			description: "memory service",
+			service:     storage.NewPrivateMemoryService(),
+			baseURL:     "mem:///delete",
+		},
+		{
+			description: "file service",
+			service:     fileService,
+			baseURL:     toolbox.FileSchema + parent,
+		},
+	}
+	for _, useCase := range useCases {
+		for _, name := range []string{"root/file1.txt", "root/a/file2.txt", "root/a/b/file3.txt", "root/c/file4.txt", "keep.txt"} {
+			assert.Nil(t, useCase.service.Upload(toolbox.URLPathJoin(useCase.baseURL, name), strings.NewReader(name)), useCase.description)
+		}
+		rootURL := toolbox.URLPathJoin(useCase.baseURL, "root")
+
+		URLs, err := storage.DeleteAllDryRun(useCase.service, rootURL)
+		assert.Nil(t, err, useCase.description)
+		var positions = make(map[string]int)
+		for i, URL := range URLs {
+			positions[strings.TrimPrefix(urlPath(URL), urlPath(useCase.baseURL))] = i
+		}
+		assert.Equal(t, 8, len(URLs), useCase.description)
+		assert.True(t, positions["/root/a/b/file3.txt"] < positions["/root/a/b"], useCase.description)
+		assert.True(t, positions["/root/a/b"] < positions["/root/a"], useCase.description)
+		assert.Equal(t, len(URLs)-1, positions["/root"], useCase.description)
+		exists, _ := useCase.service.Exists(toolbox.URLPathJoin(useCase.baseURL, "root/a/b/file3.txt"))
+		assert.True(t, exists, useCase.description+" dry run should not delete")
+
+		assert.Nil(t, storage.DeleteAll(useCase.service, rootURL), useCase.description)
+		exists, _ = useCase.service.Exists(rootURL)
+		assert.False(t, exists, useCase.description)
+		exists, _ = useCase.service.Exists(toolbox.URLPathJoin(useCase.baseURL, "keep.txt"))
+		assert.True(t, exists, useCase.description)
+
+		assert.Nil(t, storage.DeleteAll(useCase.service, toolbox.URLPathJoin(useCase.baseURL, "missing")), useCase.description)
+		URLs, err = storage.DeleteAllDryRun(useCase.service, toolbox.URLPathJoin(useCase.baseURL, "missing"))
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, 0, len(URLs), useCase.description)
+	}
+}
+
+func TestDeleteAll_Failures(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	for _, name := range []string{"a/file1.txt", "a/file2.txt", "b/file3.txt"} {
+		_ = memService.Upload("mem:///failures/"+name, strings.NewReader(name))
+	}
+	service := &undeletableService{Service: memService, suffixes: []string{"file1.txt", "file3.txt"}}
+	err := storage.DeleteAll(service, "mem:///failures")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "mem:///failures/a/file1.txt: permission denied")
+		assert.Contains(t, err.Error(), "mem:///failures/b/file3.txt: permission denied")
+		assert.NotContains(t, err.Error(), "file2.txt")
+	}
+	exists, _ := memService.Exists("mem:///failures/a/file2.txt")
+	assert.False(t, exists)
+}
+
+func urlPath(URL string) string {
+	if index := strings.Index(URL, "://"); index != -1 {
+		URL = URL[index+3:]
+	}
+	return strings.TrimSuffix(URL, "/")
+}
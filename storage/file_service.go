@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/toolbox"
@@ -9,16 +10,28 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 )
 
 var fileMode os.FileMode = 0644
 var execFileMode os.FileMode = 0755
 
 //Service represents abstract way to accessing local or remote storage
-type fileStorageService struct{}
+type fileStorageService struct {
+	symlinkMode  SymlinkMode
+	directUpload bool
+}
+
+//atomicUploadPrefix represents a prefix of temporary files used by atomic uploads
+const atomicUploadPrefix = ".tmp-"
 
 //List returns a list of object for supplied url
 func (s *fileStorageService) List(URL string) ([]Object, error) {
+	if s.symlinkMode != SymlinkFollow {
+		if linkInfo, err := os.Lstat(toolbox.Filename(URL)); err == nil && linkInfo.Mode()&os.ModeSymlink != 0 {
+			return []Object{newFileObject(URL, linkInfo)}, nil
+		}
+	}
 	file, err := toolbox.OpenFile(URL)
 	if err != nil {
 		return nil, err
@@ -46,6 +59,17 @@ func (s *fileStorageService) List(URL string) ([]Object, error) {
 		if parsedURL != nil {
 			fileName = strings.Replace(fileName, parsedURL.Path, "", 1)
 		}
+		if fileInfo.Mode()&os.ModeSymlink != 0 && s.symlinkMode == SymlinkFollow {
+			linkPath := path.Join(file.Name(), fileInfo.Name())
+			if targetInfo, err := os.Stat(linkPath); err == nil {
+				if targetInfo.IsDir() {
+					if err := checkSymlinkCycle(file.Name(), linkPath); err != nil {
+						return nil, err
+					}
+				}
+				fileInfo = targetInfo
+			}
+		}
 
 		fileURL := toolbox.URLPathJoin(URL, fileName)
 		result = append(result, newFileObject(fileURL, fileInfo))
@@ -76,13 +100,30 @@ func (s *fileStorageService) StorageObject(URL string) (Object, error) {
 		return nil, err
 	}
 	defer file.Close()
-	fileInfo, err := os.Stat(file.Name())
+	stat := os.Stat
+	if s.symlinkMode != SymlinkFollow {
+		stat = os.Lstat
+	}
+	fileInfo, err := stat(file.Name())
 	if err != nil {
 		return nil, err
 	}
 	return newFileObject(URL, fileInfo), nil
 }
 
+//Stat returns file info for supplied URL, symbolic links are not followed unless links are followed by the service
+func (s *fileStorageService) Stat(URL string) (os.FileInfo, error) {
+	if s.symlinkMode != SymlinkFollow {
+		return os.Lstat(toolbox.Filename(URL))
+	}
+	return os.Stat(toolbox.Filename(URL))
+}
+
+//CreateFolder creates folder for supplied URL with missing parent folders
+func (s *fileStorageService) CreateFolder(URL string) error {
+	return toolbox.CreateDirIfNotExist(toolbox.Filename(URL))
+}
+
 //Download returns reader for downloaded storage object
 func (s *fileStorageService) Download(object Object) (io.ReadCloser, error) {
 	return toolbox.OpenFile(object.URL())
@@ -97,11 +138,19 @@ func (s *fileStorageService) DownloadWithURL(URL string) (io.ReadCloser, error)
 	return s.Download(object)
 }
 
+//DownloadWithContext downloads content for passed in URL, local file reads are not bound to context deadline once opened
+func (s *fileStorageService) DownloadWithContext(ctx context.Context, URL string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.DownloadWithURL(URL)
+}
+
 func (s *fileStorageService) Upload(URL string, reader io.Reader) error {
 	return s.UploadWithMode(URL, DefaultFileMode, reader)
 }
 
-//Upload uploads provided reader content for supplied url.
+//UploadWithMode uploads provided reader content for supplied url, content is written to a temporary file renamed into place unless direct upload was requested
 func (s *fileStorageService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
 	if mode == 0 {
 		mode = DefaultFileMode
@@ -120,6 +169,9 @@ func (s *fileStorageService) UploadWithMode(URL string, mode os.FileMode, reader
 	if err != nil {
 		return err
 	}
+	if !s.directUpload {
+		return uploadAtomically(parsedUrl.Path, mode, reader)
+	}
 	data, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return err
@@ -127,6 +179,33 @@ func (s *fileStorageService) UploadWithMode(URL string, mode os.FileMode, reader
 	return ioutil.WriteFile(parsedUrl.Path, data, mode)
 }
 
+//uploadAtomically writes reader content to a temporary file in the destination directory and renames it into place,
+//existing destination file permissions are preserved, temporary file is removed on failure
+func uploadAtomically(filename string, mode os.FileMode, reader io.Reader) error {
+	if fileInfo, err := os.Stat(filename); err == nil {
+		mode = fileInfo.Mode().Perm()
+	}
+	parentDir, name := path.Split(filename)
+	file, err := ioutil.TempFile(parentDir, atomicUploadPrefix+name+"-")
+	if err != nil {
+		return err
+	}
+	tempFilename := file.Name()
+	if _, err = io.Copy(file, reader); err == nil {
+		err = file.Chmod(mode)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFilename, filename)
+	}
+	if err != nil {
+		_ = os.Remove(tempFilename)
+	}
+	return err
+}
+
 func (s *fileStorageService) Register(schema string, service Service) error {
 	return errors.New("unsupported")
 }
@@ -153,6 +232,68 @@ func (s *fileStorageService) Delete(object Object) error {
 	return os.Remove(fileName)
 }
 
+//SetMode sets file mode for supplied URL
+func (s *fileStorageService) SetMode(URL string, mode os.FileMode) error {
+	return os.Chmod(toolbox.Filename(URL), mode)
+}
+
+//SetModTime sets modification time for supplied URL
+func (s *fileStorageService) SetModTime(URL string, modTime time.Time) error {
+	return os.Chtimes(toolbox.Filename(URL), modTime, modTime)
+}
+
+//Move renames source file or directory to destination URL, existing destination directory is merged with source content
+func (s *fileStorageService) Move(sourceURL, destinationURL string) error {
+	sourcePath := toolbox.Filename(sourceURL)
+	destinationPath := toolbox.Filename(destinationURL)
+	if sourceInfo, err := os.Stat(sourcePath); err == nil && sourceInfo.IsDir() {
+		if destinationInfo, err := os.Stat(destinationPath); err == nil && destinationInfo.IsDir() {
+			return move(s, sourceURL, destinationURL)
+		}
+	}
+	parentDir, _ := path.Split(destinationPath)
+	if err := toolbox.CreateDirIfNotExist(parentDir); err != nil {
+		return err
+	}
+	return os.Rename(sourcePath, destinationPath)
+}
+
+//DownloadWithRange returns reader for file bytes starting at from offset up to, but excluding to offset, negative to reads till the end
+func (s *fileStorageService) DownloadWithRange(object Object, from, to int64) (io.ReadCloser, error) {
+	file, err := toolbox.OpenFile(object.URL())
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err == nil && from > stat.Size() {
+		err = fmt.Errorf("range start %v exceeds %v size %v", from, object.URL(), stat.Size())
+	}
+	if err == nil {
+		_, err = file.Seek(from, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if to < 0 {
+		return file, nil
+	}
+	return &readCloser{Reader: io.LimitReader(file, to-from), Closer: file}, nil
+}
+
+//Append appends reader content to supplied URL file
+func (s *fileStorageService) Append(URL string, reader io.Reader) error {
+	file, err := os.OpenFile(toolbox.Filename(URL), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 type fileStorageObject struct {
 	*AbstractObject
 }
@@ -161,6 +302,34 @@ func NewFileStorage() Service {
 	return &fileStorageService{}
 }
 
+//CopyObject copies source file to destination URL within the process, folders are not supported
+func (s *fileStorageService) CopyObject(sourceURL, destinationURL string) error {
+	sourcePath := toolbox.Filename(sourceURL)
+	fileInfo, err := os.Stat(sourcePath)
+	if err != nil {
+		return err
+	}
+	if fileInfo.IsDir() {
+		return ErrServerSideCopyNotSupported
+	}
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	return s.UploadWithMode(destinationURL, fileInfo.Mode().Perm(), source)
+}
+
+//NewFileStorageWithSymlinkMode returns file storage service with supplied symbolic link handling mode
+func NewFileStorageWithSymlinkMode(mode SymlinkMode) Service {
+	return &fileStorageService{symlinkMode: mode}
+}
+
+//NewFileStorageWithAtomicUpload returns file storage service, with atomic flag unset uploads write directly to the destination file
+func NewFileStorageWithAtomicUpload(atomic bool) Service {
+	return &fileStorageService{directUpload: !atomic}
+}
+
 func (o *fileStorageObject) Unwrap(target interface{}) error {
 	if fileInfo, casted := target.(*os.FileInfo); casted {
 		source, ok := o.Source.(os.FileInfo)
@@ -193,7 +362,7 @@ func newFileObject(url string, fileInfo os.FileInfo) Object {
 const FileProviderSchema = "file"
 
 func init() {
-	Registry().Registry[FileProviderSchema] = fileServiceProvider
+	RegisterProvider(FileProviderSchema, fileServiceProvider)
 
 }
 
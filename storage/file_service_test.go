@@ -0,0 +1,103 @@
+package storage_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+//failingReader represents a reader returning an error after supplied content is read
+type failingReader struct {
+	io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		return n, errors.New("injected read failure")
+	}
+	return n, err
+}
+
+func TestFileStorageService_UploadWithMode(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_atomic_upload")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	var useCases = []struct {
+		description   string
+		service       storage.Service
+		existing      string
+		reader        io.Reader
+		expectError   bool
+		expectContent string
+		expectMissing bool
+	}{
+		{
+			description:   "new file",
+			service:       storage.NewFileStorage(),
+			reader:        strings.NewReader("new content"),
+			expectContent: "new content",
+		},
+		{
+			description:   "replaced file",
+			service:       storage.NewFileStorage(),
+			existing:      "old content",
+			reader:        strings.NewReader("new content"),
+			expectContent: "new content",
+		},
+		{
+			description:   "failed replace keeps old content",
+			service:       storage.NewFileStorage(),
+			existing:      "old content",
+			reader:        &failingReader{strings.NewReader("partial")},
+			expectError:   true,
+			expectContent: "old content",
+		},
+		{
+			description:   "failed upload leaves no file",
+			service:       storage.NewFileStorage(),
+			reader:        &failingReader{strings.NewReader("partial")},
+			expectError:   true,
+			expectMissing: true,
+		},
+		{
+			description:   "direct upload",
+			service:       storage.NewFileStorageWithAtomicUpload(false),
+			existing:      "old content",
+			reader:        strings.NewReader("new content"),
+			expectContent: "new content",
+		},
+	}
+	for i, useCase := range useCases {
+		dir := path.Join(parent, string(rune('a'+i)))
+		_ = os.MkdirAll(dir, 0755)
+		filename := path.Join(dir, "file.txt")
+		if useCase.existing != "" {
+			assert.Nil(t, ioutil.WriteFile(filename, []byte(useCase.existing), 0600))
+		}
+		err := useCase.service.UploadWithMode("file://"+filename, 0644, useCase.reader)
+		assert.Equal(t, useCase.expectError, err != nil, useCase.description)
+		content, err := ioutil.ReadFile(filename)
+		if useCase.expectMissing {
+			assert.True(t, os.IsNotExist(err), useCase.description)
+		} else if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expectContent, string(content), useCase.description)
+		}
+		if useCase.existing != "" {
+			if fileInfo, err := os.Stat(filename); assert.Nil(t, err, useCase.description) {
+				assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm(), useCase.description)
+			}
+		}
+		files, err := ioutil.ReadDir(dir)
+		assert.Nil(t, err)
+		for _, file := range files {
+			assert.False(t, strings.HasPrefix(file.Name(), ".tmp-"), useCase.description)
+		}
+	}
+}
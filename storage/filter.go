@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"path"
+	"strings"
+)
+
+//NewPatternFilter returns a copy filter matching object path relative to supplied source URL with include and exclude glob patterns,
+//'**' matches any number of path segments, exclude patterns take precedence, empty include matches all content objects.
+//Folders are only matched with exclude patterns, so that excluded folders are not descended into.
+func NewPatternFilter(sourceURL string, include, exclude []string) func(object Object) bool {
+	var sourcePath = urlPath(sourceURL)
+	return func(object Object) bool {
+		var relativePath = strings.Trim(strings.TrimPrefix(urlPath(object.URL()), sourcePath), "/")
+		if relativePath == "" {
+			relativePath = path.Base(sourcePath)
+		}
+		if object.IsFolder() {
+			relativePath += "/"
+		}
+		for _, pattern := range exclude {
+			if matchPathPattern(pattern, relativePath) {
+				return false
+			}
+		}
+		if object.IsFolder() || len(include) == 0 {
+			return true
+		}
+		for _, pattern := range include {
+			if matchPathPattern(pattern, relativePath) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+//matchPathPattern returns true if supplied slash separated path matches glob pattern
+func matchPathPattern(pattern, candidate string) bool {
+	return matchPathSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(candidate, "/"))
+}
+
+func matchPathSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchPathSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(patterns[0], segments[0]); !matched {
+		return false
+	}
+	return matchPathSegments(patterns[1:], segments[1:])
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"fmt"
+)
+
+//FolderCreator represents an optional service extension creating folders, services without it have implicit folders
+type FolderCreator interface {
+	//CreateFolder creates folder for supplied URL with missing parent folders
+	CreateFolder(URL string) error
+}
+
+//ExistsKind returns true if supplied URL exists, and true if it is a folder, object content is not downloaded
+func ExistsKind(service Service, URL string) (exists bool, isFolder bool, err error) {
+	if exists, err = service.Exists(URL); err != nil || !exists {
+		return false, false, err
+	}
+	if stater, ok := resolveService(service, URL).(Stater); ok {
+		fileInfo, err := stater.Stat(URL)
+		if err != nil {
+			return true, false, err
+		}
+		return true, fileInfo.IsDir(), nil
+	}
+	object, err := service.StorageObject(URL)
+	if err != nil {
+		return true, false, err
+	}
+	return true, object.IsFolder(), nil
+}
+
+//EnsureFolder creates folder for supplied URL with missing parent folders unless it already exists, it returns an error if a file exists at folder URL
+func EnsureFolder(service Service, URL string) error {
+	exists, isFolder, err := ExistsKind(service, URL)
+	if err != nil {
+		return fmt.Errorf("unable check folder %v, %v", URL, err)
+	}
+	if exists {
+		if !isFolder {
+			return fmt.Errorf("unable create folder %v, file already exists", URL)
+		}
+		return nil
+	}
+	if creator, ok := resolveService(service, URL).(FolderCreator); ok {
+		if err = creator.CreateFolder(URL); err != nil {
+			return fmt.Errorf("unable create folder %v, %v", URL, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestEnsureFolder(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_ensure_folder")
+	_ = os.RemoveAll(parent)
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	assert.Nil(t, ioutil.WriteFile(path.Join(parent, "file.txt"), []byte("abc"), 0644))
+
+	var useCases = []struct {
+		description string
+		service     storage.Service
+		baseURL     string
+	}{
+		{"memory", storage.NewPrivateMemoryService(), "mem:///ensure"},
+		{"file", storage.NewFileStorage(), "file://" + parent},
+	}
+	for _, useCase := range useCases {
+		service := useCase.service
+		assert.Nil(t, service.Upload(useCase.baseURL+"/file.txt", strings.NewReader("abc")), useCase.description)
+
+		folderURL := useCase.baseURL + "/a/b/c"
+		exists, isFolder, err := storage.ExistsKind(service, folderURL)
+		assert.Nil(t, err, useCase.description)
+		assert.False(t, exists, useCase.description)
+		assert.Nil(t, storage.EnsureFolder(service, folderURL), useCase.description)
+		assert.Nil(t, storage.EnsureFolder(service, folderURL), useCase.description)
+		exists, isFolder, err = storage.ExistsKind(service, folderURL)
+		assert.Nil(t, err, useCase.description)
+		assert.True(t, exists, useCase.description)
+		assert.True(t, isFolder, useCase.description)
+
+		exists, isFolder, err = storage.ExistsKind(service, useCase.baseURL+"/file.txt")
+		assert.Nil(t, err, useCase.description)
+		assert.True(t, exists, useCase.description)
+		assert.False(t, isFolder, useCase.description)
+
+		err = storage.EnsureFolder(service, useCase.baseURL+"/file.txt")
+		if assert.NotNil(t, err, useCase.description) {
+			assert.True(t, strings.Contains(err.Error(), "file already exists"), err.Error())
+		}
+		assert.NotNil(t, storage.EnsureFolder(service, useCase.baseURL+"/file.txt/sub"), useCase.description)
+	}
+}
+
+func TestCopyWithOptions_CreateDestination(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	_ = service.Upload("mem:///create/source/file.txt", strings.NewReader("abc"))
+	err := storage.CopyWithOptions(service, "mem:///create/source/file.txt", service, "mem:///create/target/data", &storage.CopyOptions{CreateDestination: true})
+	assert.Nil(t, err)
+	content, err := storage.DownloadText(service, "mem:///create/target/data/file.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", content)
+
+	err = storage.CopyWithOptions(service, "mem:///create/source", service, "mem:///create/source/file.txt", &storage.CopyOptions{CreateDestination: true})
+	assert.NotNil(t, err)
+}
@@ -17,7 +17,7 @@ const DevstorageFullControlScope = "https://www.googleapis.com/auth/devstorage.f
 const googleStorageProjectKey = "GOOGLE_STORAGE_PROJECT"
 
 func init() {
-	storage.Registry().Registry[ProviderScheme] = serviceProvider
+	storage.RegisterProvider(ProviderScheme, serviceProvider)
 }
 
 func serviceProvider(credentials string) (storage.Service, error) {
@@ -67,7 +67,7 @@ func credServiceProvider(config *cred.Config) (storage.Service, error) {
 
 //SetProvider set gs provider with supplied config
 func SetProvider(config *cred.Config) {
-	storage.Registry().Registry[ProviderScheme] = func(string) (storage.Service, error) {
+	storage.RegisterProvider(ProviderScheme, func(string) (storage.Service, error) {
 		return credServiceProvider(config)
-	}
+	})
 }
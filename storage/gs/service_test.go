@@ -1,6 +1,10 @@
 package gs
 
 import (
+	"github.com/stretchr/testify/assert"
+	tstorage "github.com/viant/toolbox/storage"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -21,3 +25,51 @@ func TestService_List(t *testing.T) {
 		assert.Nil(t, err)*/
 
 }
+
+func TestServiceProvider(t *testing.T) {
+	defer os.Setenv(googleStorageProjectKey, os.Getenv(googleStorageProjectKey))
+	_ = os.Setenv(googleStorageProjectKey, "test-project")
+	storageService, err := serviceProvider("")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "test-project", storageService.(*service).projectID)
+	}
+	registered, err := tstorage.NewServiceForURL("gs://bucket/key", "")
+	assert.Nil(t, err)
+	assert.NotNil(t, registered)
+}
+
+//TestService_Copy copies memory content to bucket set with GS_TEST_BUCKET and back, use STORAGE_EMULATOR_HOST to run against emulator
+func TestService_Copy(t *testing.T) {
+	bucket := os.Getenv("GS_TEST_BUCKET")
+	if bucket == "" {
+		t.Skip("GS_TEST_BUCKET was not set")
+	}
+	storageService, err := serviceProvider(os.Getenv("GS_TEST_CREDENTIALS"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	memService := tstorage.NewPrivateMemoryService()
+	_ = memService.Upload("mem:///gs/source/file1.txt", strings.NewReader("abc"))
+	_ = memService.Upload("mem:///gs/source/dir/file2.txt", strings.NewReader("xyz"))
+	bucketURL := "gs://" + bucket + "/toolbox/copy"
+
+	err = tstorage.Copy(memService, "mem:///gs/source", storageService, bucketURL, nil, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	object, err := storageService.StorageObject(bucketURL + "/dir/file2.txt")
+	if assert.Nil(t, err) {
+		assert.Equal(t, bucketURL+"/dir/file2.txt", object.URL())
+	}
+	err = tstorage.Copy(storageService, bucketURL, memService, "mem:///gs/target", nil, nil)
+	assert.Nil(t, err)
+	for _, file := range []string{"file1.txt", "dir/file2.txt"} {
+		expected, _ := tstorage.DownloadText(memService, "mem:///gs/source/"+file)
+		actual, err := tstorage.DownloadText(memService, "mem:///gs/target/"+file)
+		assert.Nil(t, err, file)
+		assert.Equal(t, expected, actual, file)
+	}
+	if folder, err := storageService.StorageObject(bucketURL); err == nil {
+		_ = storageService.Delete(folder)
+	}
+}
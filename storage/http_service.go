@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/toolbox"
@@ -12,10 +13,146 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
+var errUnsupportedHTTPOperation = errors.New("unsupported operation: http storage service supports only download and PUT upload")
+
+//HTTPStatusError represents unexpected http response status error
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration //delay requested with Retry-After header, 0 if not present
+}
+
+//Error returns error message
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("invalid response code: %v, %v", e.Status, e.URL)
+}
+
+//newHTTPStatusError creates a new status error for supplied response, response body is closed
+func newHTTPStatusError(URL string, response *http.Response) error {
+	_ = response.Body.Close()
+	result := &HTTPStatusError{URL: URL, StatusCode: response.StatusCode, Status: response.Status}
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			result.RetryAfter = time.Duration(seconds) * time.Second
+		} else if timeValue, err := http.ParseTime(retryAfter); err == nil && timeValue.After(time.Now()) {
+			result.RetryAfter = timeValue.Sub(time.Now())
+		}
+	}
+	return result
+}
+
+//httpHeadersKey represents context key of http request headers
+type httpHeadersKey struct{}
+
+//WithHTTPHeaders returns a context carrying headers sent with http storage service context aware downloads
+func WithHTTPHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, httpHeadersKey{}, headers)
+}
+
+//httpClientKey represents context key of http client
+type httpClientKey struct{}
+
+//WithHTTPClient returns a context carrying http client used by http storage service context aware operations, i.e. client with custom TLS configuration
+func WithHTTPClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, client)
+}
+
+//httpClient returns context http client or HTTPClientProvider client, context redirect policy is applied to a client copy
+func httpClient(ctx context.Context) (*http.Client, error) {
+	client, ok := ctx.Value(httpClientKey{}).(*http.Client)
+	if !ok || client == nil {
+		var err error
+		if client, err = HTTPClientProvider(); err != nil {
+			return nil, err
+		}
+	}
+	if policy, ok := ctx.Value(httpRedirectPolicyKey{}).(*HTTPRedirectPolicy); ok && policy != nil {
+		var policyClient = *client
+		policyClient.CheckRedirect = policy.checkRedirect
+		return &policyClient, nil
+	}
+	return client, nil
+}
+
+//redirectError returns RedirectLimitError if client error was caused by redirect policy
+func redirectError(err error) error {
+	if urlError, ok := err.(*url.Error); ok {
+		if limitError, ok := urlError.Err.(*RedirectLimitError); ok {
+			return limitError
+		}
+	}
+	return err
+}
+
+//defaultMaxRedirects represents max number of followed redirects if policy does not define it
+const defaultMaxRedirects = 10
+
+//HTTPRedirectPolicy represents http storage service redirect policy
+type HTTPRedirectPolicy struct {
+	MaxRedirects                int  //MaxRedirects max number of followed redirects, 0 means default (10)
+	DisallowRedirects           bool //DisallowRedirects returns redirect response as HTTPStatusError instead of following it
+	StripCrossHostAuthorization bool //StripCrossHostAuthorization removes Authorization header when redirected to another host
+}
+
+//RedirectLimitError represents redirect chain exceeding policy max redirects
+type RedirectLimitError struct {
+	URL          string //URL of redirect that was not followed
+	MaxRedirects int    //max number of followed redirects
+}
+
+//Error returns error message
+func (e *RedirectLimitError) Error() string {
+	return fmt.Sprintf("stopped after %v redirects: %v", e.MaxRedirects, e.URL)
+}
+
+//checkRedirect implements http client redirect check
+func (p *HTTPRedirectPolicy) checkRedirect(request *http.Request, via []*http.Request) error {
+	if p.DisallowRedirects {
+		return http.ErrUseLastResponse
+	}
+	maxRedirects := p.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	if len(via) > maxRedirects {
+		return &RedirectLimitError{URL: request.URL.String(), MaxRedirects: maxRedirects}
+	}
+	if p.StripCrossHostAuthorization && len(via) > 0 && request.URL.Host != via[0].URL.Host {
+		request.Header.Del("Authorization")
+	}
+	return nil
+}
+
+//httpRedirectPolicyKey represents context key of http redirect policy
+type httpRedirectPolicyKey struct{}
+
+//WithHTTPRedirectPolicy returns a context carrying redirect policy used by http storage service context aware operations
+func WithHTTPRedirectPolicy(ctx context.Context, policy *HTTPRedirectPolicy) context.Context {
+	return context.WithValue(ctx, httpRedirectPolicyKey{}, policy)
+}
+
+//ContentLengthProvider represents an optional download stream extension reporting expected content length, -1 if unknown
+type ContentLengthProvider interface {
+	ContentLength() int64
+}
+
+//httpResponseBody represents http response body with response content length
+type httpResponseBody struct {
+	io.ReadCloser
+	contentLength int64
+}
+
+//ContentLength returns response Content-Length, -1 if unknown
+func (b *httpResponseBody) ContentLength() int64 {
+	return b.contentLength
+}
+
 //httpStorageService represents basic http storage service (only limited listing and full download are supported)
 type httpStorageService struct {
 	Credential *cred.Config
@@ -86,7 +223,7 @@ func (s *httpStorageService) List(URL string) ([]Object, error) {
 	if err != nil {
 		return nil, err
 	}
-
+	defer response.Body.Close()
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		return nil, err
@@ -169,30 +306,101 @@ func (s *httpStorageService) List(URL string) ([]Object, error) {
 	return result, err
 }
 
+//HeadWithContext returns http HEAD response for supplied URL bound to context, headers supplied with WithHTTPHeaders are added to the request,
+//response body is closed, unexpected response status returns HTTPStatusError
+func HeadWithContext(ctx context.Context, URL string) (*http.Response, error) {
+	client, err := httpClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(http.MethodHead, URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if headers, ok := ctx.Value(httpHeadersKey{}).(map[string]string); ok {
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, redirectError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError(URL, response)
+	}
+	_ = response.Body.Close()
+	return response, nil
+}
+
+//head returns HEAD response for supplied URL
+func (s *httpStorageService) head(URL string) (*http.Response, error) {
+	client, err := HTTPClientProvider()
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Head(s.addCredentialToURLIfNeeded(URL))
+	if err != nil {
+		return nil, err
+	}
+	_ = response.Body.Close()
+	return response, nil
+}
+
 //Exists returns true if resource exists
 func (s *httpStorageService) Exists(URL string) (bool, error) {
+	response, err := s.head(URL)
+	if err != nil {
+		return false, err
+	}
+	if response.StatusCode != http.StatusMethodNotAllowed && response.StatusCode != http.StatusNotImplemented {
+		return response.StatusCode == http.StatusOK, nil
+	}
 	client, err := HTTPClientProvider()
 	if err != nil {
 		return false, err
 	}
-	response, err := client.Get(URL)
+	response, err = client.Get(s.addCredentialToURLIfNeeded(URL))
 	if err != nil {
 		return false, err
 	}
-	return response.StatusCode == 200, nil
+	_ = response.Body.Close()
+	return response.StatusCode == http.StatusOK, nil
 }
 
-//Object returns a Object for supplied url
+//Object returns a Object for supplied url, size and modification time are taken from HEAD response Content-Length and Last-Modified headers
 func (s *httpStorageService) StorageObject(URL string) (Object, error) {
-	objects, err := s.List(URL)
+	response, err := s.head(URL)
 	if err != nil {
 		return nil, err
 	}
-	if len(objects) == 0 {
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		objects, err := s.List(URL)
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			return nil, fmt.Errorf("resource  not found: %v", URL)
+		}
+		return objects[len(objects)-1], nil
+	case http.StatusNotFound:
 		return nil, fmt.Errorf("resource  not found: %v", URL)
+	default:
+		return nil, newHTTPStatusError(URL, response)
 	}
-
-	return objects[0], nil
+	modified := time.Now()
+	if lastModified := response.Header.Get("Last-Modified"); lastModified != "" {
+		if timeValue, err := http.ParseTime(lastModified); err == nil {
+			modified = timeValue
+		}
+	}
+	objectType := StorageObjectContentType
+	if strings.HasSuffix(URL, "/") {
+		objectType = StorageObjectFolderType
+	}
+	return newHttpFileObject(URL, objectType, nil, modified, response.ContentLength), nil
 }
 
 //Download returns reader for downloaded storage object
@@ -202,17 +410,75 @@ func (s *httpStorageService) Download(object Object) (io.ReadCloser, error) {
 		return nil, err
 	}
 	response, err := client.Get(s.addCredentialToURLIfNeeded(object.URL()))
-	return response.Body, err
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError(object.URL(), response)
+	}
+	return response.Body, nil
+}
+
+//DownloadWithContext returns reader for supplied URL, request is bound to context, so that cancellation aborts both connection and body read,
+//headers supplied with WithHTTPHeaders are added to the request, client supplied with WithHTTPClient is used if present, WithHTTPRedirectPolicy controls redirects,
+//returned reader implements ContentLengthProvider
+func (s *httpStorageService) DownloadWithContext(ctx context.Context, URL string) (io.ReadCloser, error) {
+	client, err := httpClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(http.MethodGet, s.addCredentialToURLIfNeeded(URL), nil)
+	if err != nil {
+		return nil, err
+	}
+	if headers, ok := ctx.Value(httpHeadersKey{}).(map[string]string); ok {
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, redirectError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError(URL, response)
+	}
+	return &httpResponseBody{ReadCloser: response.Body, contentLength: response.ContentLength}, nil
 }
 
-//Upload uploads provided reader content for supplied url.
+//UploadWithContext uploads reader content with http PUT request bound to context, headers supplied with WithHTTPHeaders are added to the request
+func (s *httpStorageService) UploadWithContext(ctx context.Context, URL string, reader io.Reader) error {
+	client, err := httpClient(ctx)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPut, s.addCredentialToURLIfNeeded(URL), reader)
+	if err != nil {
+		return err
+	}
+	if headers, ok := ctx.Value(httpHeadersKey{}).(map[string]string); ok {
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return redirectError(err)
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return newHTTPStatusError(URL, response)
+	}
+	return response.Body.Close()
+}
+
+//Upload uploads provided reader content for supplied url with http PUT request
 func (s *httpStorageService) Upload(URL string, reader io.Reader) error {
-	return errors.New("unsupported")
+	return s.UploadWithContext(context.Background(), URL, reader)
 }
 
-//Upload uploads provided reader content for supplied url.
+//UploadWithMode uploads provided reader content for supplied url with http PUT request, mode is ignored
 func (s *httpStorageService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
-	return errors.New("unsupported")
+	return s.UploadWithContext(context.Background(), URL, reader)
 }
 
 func (s *httpStorageService) Register(schema string, service Service) error {
@@ -221,8 +487,7 @@ func (s *httpStorageService) Register(schema string, service Service) error {
 
 //Delete removes passed in storage object
 func (s *httpStorageService) Delete(object Object) error {
-	fileName := toolbox.Filename(object.URL())
-	return os.Remove(fileName)
+	return errUnsupportedHTTPOperation
 }
 
 func (s *httpStorageService) Close() error {
@@ -272,8 +537,8 @@ const HttpProviderScheme = "http"
 const HttpsProviderScheme = "https"
 
 func init() {
-	Registry().Registry[HttpsProviderScheme] = httpServiceProvider
-	Registry().Registry[HttpProviderScheme] = httpServiceProvider
+	RegisterProvider(HttpsProviderScheme, httpServiceProvider)
+	RegisterProvider(HttpProviderScheme, httpServiceProvider)
 
 }
 
@@ -1,6 +1,19 @@
 package storage_test
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestNewHttpStorageService(t *testing.T) {
 
@@ -23,3 +36,119 @@ func TestNewHttpStorageService(t *testing.T) {
 	//}
 
 }
+
+func TestHttpStorageService(t *testing.T) {
+	modified := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/release/app.tar.gz", func(writer http.ResponseWriter, request *http.Request) {
+		http.ServeContent(writer, request, "app.tar.gz", modified, strings.NewReader("release content"))
+	})
+	var uploaded []string
+	mux.HandleFunc("/upload/", func(writer http.ResponseWriter, request *http.Request) {
+		content, _ := ioutil.ReadAll(request.Body)
+		uploaded = append(uploaded, request.Method+" "+string(content))
+		writer.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/release/", func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/release/" {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte(`<html><body><a href="app.tar.gz">app.tar.gz</a><a href="docs/">docs/</a></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	service := storage.NewHttpStorageService(nil)
+
+	{ //storage object
+		object, err := service.StorageObject(server.URL + "/release/app.tar.gz")
+		if assert.Nil(t, err) {
+			assert.True(t, object.IsContent())
+			assert.Equal(t, int64(len("release content")), object.FileInfo().Size())
+			assert.True(t, modified.Equal(object.FileInfo().ModTime()))
+		}
+		_, err = service.StorageObject(server.URL + "/release/missing.tar.gz")
+		assert.NotNil(t, err)
+	}
+	{ //exists
+		exists, err := service.Exists(server.URL + "/release/app.tar.gz")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		exists, err = service.Exists(server.URL + "/release/missing.tar.gz")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	}
+	{ //list
+		objects, err := service.List(server.URL + "/release/app.tar.gz")
+		if assert.Nil(t, err) && assert.Equal(t, 1, len(objects)) {
+			assert.Equal(t, server.URL+"/release/app.tar.gz", objects[0].URL())
+		}
+		objects, err = service.List(server.URL + "/release/")
+		if assert.Nil(t, err) && assert.True(t, len(objects) >= 2) {
+			assert.Equal(t, server.URL+"/release/app.tar.gz", objects[0].URL())
+			assert.True(t, objects[1].IsFolder())
+		}
+	}
+	{ //download and copy
+		text, err := storage.DownloadText(service, server.URL+"/release/app.tar.gz")
+		assert.Nil(t, err)
+		assert.Equal(t, "release content", text)
+
+		memService := storage.NewPrivateMemoryService()
+		err = storage.Copy(service, server.URL+"/release/app.tar.gz", memService, "mem:///cdn/app.tar.gz", nil, nil)
+		assert.Nil(t, err)
+		text, err = storage.DownloadText(memService, "mem:///cdn/app.tar.gz")
+		assert.Nil(t, err)
+		assert.Equal(t, "release content", text)
+	}
+	{ //upload and delete
+		object, _ := service.StorageObject(server.URL + "/release/app.tar.gz")
+		assert.Nil(t, service.Upload(server.URL+"/upload/new.tar.gz", strings.NewReader("abc")))
+		assert.Nil(t, service.UploadWithMode(server.URL+"/upload/new.tar.gz", 0644, strings.NewReader("xyz")))
+		assert.EqualValues(t, []string{"PUT abc", "PUT xyz"}, uploaded)
+		err := service.Upload(server.URL+"/release/new.tar.gz", strings.NewReader("abc"))
+		_, ok := err.(*storage.HTTPStatusError)
+		assert.True(t, ok, fmt.Sprintf("%T %v", err, err))
+		err = service.Delete(object)
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "unsupported operation")
+		}
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/unavailable":
+			writer.Header().Set("Retry-After", "2")
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		case "/throttled":
+			writer.WriteHeader(http.StatusTooManyRequests)
+		default:
+			writer.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	service := storage.NewHttpStorageService(nil)
+	var useCases = []struct {
+		path       string
+		transient  bool
+		retryAfter time.Duration
+	}{
+		{"/unavailable", true, 2 * time.Second},
+		{"/throttled", true, 0},
+		{"/forbidden", false, 0},
+	}
+	for _, useCase := range useCases {
+		_, err := storage.DownloadWithContext(context.Background(), service, server.URL+useCase.path)
+		statusError, ok := err.(*storage.HTTPStatusError)
+		if assert.True(t, ok, useCase.path) {
+			assert.Equal(t, useCase.retryAfter, statusError.RetryAfter, useCase.path)
+		}
+		assert.Equal(t, useCase.transient, storage.IsTransientError(err), useCase.path)
+	}
+	assert.True(t, storage.IsTransientError(io.ErrUnexpectedEOF))
+	assert.False(t, storage.IsTransientError(errors.New("test")))
+	assert.False(t, storage.IsTransientError(nil))
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	//OperationList represents list operation
+	OperationList = "list"
+	//OperationExists represents exists operation
+	OperationExists = "exists"
+	//OperationStorageObject represents storage object operation
+	OperationStorageObject = "storageObject"
+	//OperationDownload represents download operation, event is emitted once download reader is closed
+	OperationDownload = "download"
+	//OperationUpload represents upload operation
+	OperationUpload = "upload"
+	//OperationDelete represents delete operation
+	OperationDelete = "delete"
+)
+
+//StorageEvent represents an instrumented storage operation
+type StorageEvent struct {
+	Operation string        `json:"operation"`
+	URL       string        `json:"url"`
+	Started   time.Time     `json:"started"`
+	Duration  time.Duration `json:"duration"`
+	Bytes     int64         `json:"bytes,omitempty"`
+	Error     error         `json:"-"`
+}
+
+//MarshalJSON marshals event with error message
+func (e StorageEvent) MarshalJSON() ([]byte, error) {
+	type event StorageEvent
+	var errorMessage string
+	if e.Error != nil {
+		errorMessage = e.Error.Error()
+	}
+	return json.Marshal(&struct {
+		event
+		Error string `json:"error,omitempty"`
+	}{event(e), errorMessage})
+}
+
+//NewJSONEventListener returns a listener writing each event as a JSON line to supplied writer
+func NewJSONEventListener(writer io.Writer) func(event StorageEvent) {
+	var mutex = &sync.Mutex{}
+	var encoder = json.NewEncoder(writer)
+	return func(event StorageEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		_ = encoder.Encode(event)
+	}
+}
+
+//instrumentedService represents a service notifying listener with each delegate operation
+type instrumentedService struct {
+	delegate Service
+	listener func(event StorageEvent)
+}
+
+func (s *instrumentedService) notify(operation, URL string, started time.Time, bytes int64, err error) {
+	s.listener(StorageEvent{Operation: operation, URL: URL, Started: started, Duration: time.Now().Sub(started), Bytes: bytes, Error: err})
+}
+
+//countingReader represents a reader counting read bytes, it notifies close handler once
+type countingReader struct {
+	io.Reader
+	closer  io.Closer
+	bytes   int64
+	once    sync.Once
+	onClose func(bytes int64, err error)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	atomic.AddInt64(&r.bytes, int64(n))
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	var err error
+	if r.closer != nil {
+		err = r.closer.Close()
+	}
+	r.once.Do(func() {
+		r.onClose(atomic.LoadInt64(&r.bytes), err)
+	})
+	return err
+}
+
+//download wraps supplied download reader to notify listener with read bytes once it is closed
+func (s *instrumentedService) download(URL string, started time.Time, reader io.ReadCloser, err error) (io.ReadCloser, error) {
+	if err != nil {
+		s.notify(OperationDownload, URL, started, 0, err)
+		return nil, err
+	}
+	return &countingReader{Reader: reader, closer: reader, onClose: func(bytes int64, err error) {
+		s.notify(OperationDownload, URL, started, bytes, err)
+	}}, nil
+}
+
+func (s *instrumentedService) List(URL string) ([]Object, error) {
+	started := time.Now()
+	objects, err := s.delegate.List(URL)
+	s.notify(OperationList, URL, started, 0, err)
+	return objects, err
+}
+
+func (s *instrumentedService) Exists(URL string) (bool, error) {
+	started := time.Now()
+	exists, err := s.delegate.Exists(URL)
+	s.notify(OperationExists, URL, started, 0, err)
+	return exists, err
+}
+
+func (s *instrumentedService) StorageObject(URL string) (Object, error) {
+	started := time.Now()
+	object, err := s.delegate.StorageObject(URL)
+	s.notify(OperationStorageObject, URL, started, 0, err)
+	return object, err
+}
+
+func (s *instrumentedService) Download(object Object) (io.ReadCloser, error) {
+	started := time.Now()
+	reader, err := s.delegate.Download(object)
+	return s.download(object.URL(), started, reader, err)
+}
+
+func (s *instrumentedService) DownloadWithURL(URL string) (io.ReadCloser, error) {
+	started := time.Now()
+	reader, err := s.delegate.DownloadWithURL(URL)
+	return s.download(URL, started, reader, err)
+}
+
+func (s *instrumentedService) Upload(URL string, reader io.Reader) error {
+	started := time.Now()
+	counter := &countingReader{Reader: reader}
+	err := s.delegate.Upload(URL, counter)
+	s.notify(OperationUpload, URL, started, atomic.LoadInt64(&counter.bytes), err)
+	return err
+}
+
+func (s *instrumentedService) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
+	started := time.Now()
+	counter := &countingReader{Reader: reader}
+	err := s.delegate.UploadWithMode(URL, mode, counter)
+	s.notify(OperationUpload, URL, started, atomic.LoadInt64(&counter.bytes), err)
+	return err
+}
+
+func (s *instrumentedService) Delete(object Object) error {
+	started := time.Now()
+	err := s.delegate.Delete(object)
+	s.notify(OperationDelete, object.URL(), started, 0, err)
+	return err
+}
+
+func (s *instrumentedService) Register(schema string, service Service) error {
+	return s.delegate.Register(schema, service)
+}
+
+func (s *instrumentedService) Close() error {
+	return s.delegate.Close()
+}
+
+//Unwrap returns delegate service, optional service extensions are resolved on delegate and are not instrumented
+func (s *instrumentedService) Unwrap() Service {
+	return s.delegate
+}
+
+//NewInstrumentedService returns a service notifying listener with URL, duration, transferred bytes and error of each delegate operation,
+//optional service extensions of delegate are reachable with Unwrap
+func NewInstrumentedService(delegate Service, listener func(event StorageEvent)) Service {
+	return &instrumentedService{delegate: delegate, listener: listener}
+}
@@ -0,0 +1,132 @@
+package storage_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestNewInstrumentedService(t *testing.T) {
+	var events = make([]storage.StorageEvent, 0)
+	service := storage.NewInstrumentedService(storage.NewPrivateMemoryService(), func(event storage.StorageEvent) {
+		events = append(events, event)
+	})
+	assert.Nil(t, service.Upload("mem:///data/file.txt", strings.NewReader("hello world")))
+	exists, err := service.Exists("mem:///data/file.txt")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	object, err := service.StorageObject("mem:///data/file.txt")
+	if assert.Nil(t, err) {
+		reader, err := service.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			assert.Equal(t, "hello world", string(content))
+			assert.Nil(t, reader.Close())
+			assert.Nil(t, reader.Close())
+		}
+		reader, err = storage.DownloadWithRange(service, object, 6, 8)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			assert.Equal(t, "wo", string(content))
+			_ = reader.Close()
+		}
+		assert.Nil(t, service.Delete(object))
+	}
+	_, err = service.List("mem:///data")
+	assert.Nil(t, err)
+	_, err = service.DownloadWithURL("mem:///data/file.txt")
+	assert.NotNil(t, err)
+
+	var expected = []struct {
+		operation string
+		bytes     int64
+		failed    bool
+	}{
+		{storage.OperationUpload, 11, false},
+		{storage.OperationExists, 0, false},
+		{storage.OperationStorageObject, 0, false},
+		{storage.OperationDownload, 11, false},
+		{storage.OperationDownload, 8, false}, //memory service is not a Ranger, range prefix is downloaded and discarded
+		{storage.OperationDelete, 0, false},
+		{storage.OperationList, 0, false},
+		{storage.OperationDownload, 0, true},
+	}
+	if assert.Equal(t, len(expected), len(events)) {
+		for i, event := range events {
+			assert.Equal(t, expected[i].operation, event.Operation)
+			assert.Equal(t, expected[i].bytes, event.Bytes, event.Operation)
+			assert.Equal(t, expected[i].failed, event.Error != nil, event.Operation)
+		}
+	}
+	assert.Equal(t, "mem:///data/file.txt", events[0].URL)
+}
+
+func TestNewInstrumentedService_Extensions(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_instrumented_extensions")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	var events = make([]storage.StorageEvent, 0)
+	delegate := storage.NewFileStorage()
+	service := storage.NewInstrumentedService(delegate, func(event storage.StorageEvent) {
+		events = append(events, event)
+	})
+	wrapper, ok := service.(storage.Wrapper)
+	if assert.True(t, ok) {
+		assert.Equal(t, delegate, wrapper.Unwrap())
+	}
+	URL := func(name string) string {
+		return "file://" + path.Join(parent, name)
+	}
+	assert.Nil(t, service.Upload(URL("file.txt"), strings.NewReader("hello world")))
+	events = events[:0]
+
+	assert.Nil(t, storage.Copy(service, URL("file.txt"), service, URL("copy.txt"), nil, nil))
+	assert.Nil(t, storage.Move(service, URL("copy.txt"), URL("moved.txt")))
+	object, err := service.StorageObject(URL("moved.txt"))
+	if assert.Nil(t, err) {
+		reader, err := storage.DownloadWithRange(service, object, 6, 8)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			assert.Equal(t, "wo", string(content))
+			_ = reader.Close()
+		}
+	}
+	for _, event := range events { //server side copy, move and range download are resolved on delegate
+		assert.True(t, event.Operation != storage.OperationDownload && event.Operation != storage.OperationUpload, event.Operation)
+	}
+
+	memService := storage.NewPrivateMemoryService()
+	instrumented := storage.NewInstrumentedService(memService, func(event storage.StorageEvent) {})
+	assert.Nil(t, instrumented.Upload("mem:///extensions/file.txt", strings.NewReader("abc")))
+	object, err = instrumented.StorageObject("mem:///extensions/file.txt")
+	if assert.Nil(t, err) {
+		expected, _ := memService.(storage.Checksummer).Checksum(object, "md5")
+		checksum, err := storage.Checksum(instrumented, object, "md5")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, checksum)
+	}
+}
+
+func TestNewJSONEventListener(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	service := storage.NewInstrumentedService(storage.NewPrivateMemoryService(), storage.NewJSONEventListener(buffer))
+	assert.Nil(t, service.Upload("mem:///data/file.txt", strings.NewReader("abc")))
+	_, _ = service.StorageObject("mem:///missing.txt")
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if assert.Equal(t, 2, len(lines)) {
+		var upload, lookup map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(lines[0]), &upload))
+		assert.Nil(t, json.Unmarshal([]byte(lines[1]), &lookup))
+		assert.EqualValues(t, "upload", upload["operation"])
+		assert.EqualValues(t, 3, upload["bytes"])
+		assert.Nil(t, upload["error"])
+		assert.EqualValues(t, "storageObject", lookup["operation"])
+		assert.NotNil(t, lookup["error"])
+	}
+}
@@ -0,0 +1,26 @@
+package storage
+
+//StreamLister represents an optional service extension listing objects page by page
+type StreamLister interface {
+	//ListStream passes objects for supplied URL to handler as soon as each page is fetched, handler returning false stops listing
+	ListStream(URL string, handler func(object Object) (bool, error)) error
+}
+
+//ListStream passes objects for supplied URL to handler, handler returning false stops listing,
+//it uses service StreamLister if available, otherwise objects returned by List are passed
+func ListStream(service Service, URL string, handler func(object Object) (bool, error)) error {
+	if lister, ok := resolveService(service, URL).(StreamLister); ok {
+		return lister.ListStream(URL, handler)
+	}
+	objects, err := service.List(URL)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		toContinue, err := handler(object)
+		if err != nil || !toContinue {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package storage_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"strings"
+	"testing"
+)
+
+//pagedService represents a service listing objects in pages of pageSize, counting fetched pages
+type pagedService struct {
+	storage.Service
+	pageSize int
+	pages    int
+	listed   int
+}
+
+func (s *pagedService) List(URL string) ([]storage.Object, error) {
+	s.listed++
+	return s.Service.List(URL)
+}
+
+func (s *pagedService) ListStream(URL string, handler func(object storage.Object) (bool, error)) error {
+	objects, err := s.Service.List(URL)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(objects); i += s.pageSize {
+		s.pages++
+		end := i + s.pageSize
+		if end > len(objects) {
+			end = len(objects)
+		}
+		for _, object := range objects[i:end] {
+			if toContinue, err := handler(object); err != nil || !toContinue {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestListStream(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	for i := 0; i < 10; i++ {
+		_ = memService.Upload(fmt.Sprintf("mem:///data/file%v.txt", i), strings.NewReader("abc"))
+	}
+
+	{ //early termination stops fetching further pages
+		service := &pagedService{Service: memService, pageSize: 3}
+		var visited = 0
+		err := storage.ListStream(service, "mem:///data", func(object storage.Object) (bool, error) {
+			if object.IsContent() {
+				visited++
+			}
+			return visited < 4, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, visited)
+		assert.Equal(t, 2, service.pages)
+	}
+	{ //handler error is returned
+		service := &pagedService{Service: memService, pageSize: 3}
+		err := storage.ListStream(service, "mem:///data", func(object storage.Object) (bool, error) {
+			return true, fmt.Errorf("handler failure")
+		})
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "handler failure", err.Error())
+		}
+		assert.Equal(t, 1, service.pages)
+	}
+	{ //fallback to List
+		var visited = 0
+		err := storage.ListStream(memService, "mem:///data", func(object storage.Object) (bool, error) {
+			visited++
+			return true, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 11, visited)
+	}
+}
+
+func TestCopy_ListStream(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	files := uploadTestTree(memService, "mem:///source", 20)
+	service := &pagedService{Service: memService, pageSize: 2}
+	err := storage.Copy(service, "mem:///source", memService, "mem:///target", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, service.listed)
+	assert.True(t, service.pages > 0)
+	for relativePath := range files {
+		exists, _ := memService.Exists(toolbox.URLPathJoin("mem:///target", relativePath))
+		assert.True(t, exists, relativePath)
+	}
+}
@@ -40,7 +40,7 @@ func GenerateStorageCode(mappings ...*StorageMapping) error {
 		}
 		defer writer.Close()
 		destinationURL := "mem://" + mapping.DestinationURI
-		err = copyStorageContent(sourceService, mapping.SourceURL, destinationService, destinationURL, nil, "", handler)
+		err = Copy(sourceService, mapping.SourceURL, destinationService, destinationURL, nil, handler)
 		if err != nil {
 			return err
 		}
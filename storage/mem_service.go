@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/url"
@@ -31,9 +32,26 @@ type memoryStorageService struct {
 }
 
 type MemoryFile struct {
-	name     string
-	fileInfo os.FileInfo
-	content  []byte
+	name      string
+	fileInfo  os.FileInfo
+	content   []byte
+	mutex     *sync.Mutex
+	checksums map[string]string
+}
+
+//checksum returns cached content checksum for supplied algorithm
+func (f *MemoryFile) checksum(algo string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if checksum, ok := f.checksums[algo]; ok {
+		return checksum, nil
+	}
+	checksum, err := computeChecksum(bytes.NewReader(f.content), algo)
+	if err != nil {
+		return "", err
+	}
+	f.checksums[algo] = checksum
+	return checksum, nil
 }
 
 func (f *MemoryFile) Object() Object {
@@ -53,6 +71,8 @@ func (f *MemoryFolder) Object() Object {
 }
 
 func (f *MemoryFolder) Objects() []Object {
+	f.mutext.RLock()
+	defer f.mutext.RUnlock()
 	var result = make([]Object, 0)
 	result = append(result, f.Object())
 	for _, folder := range f.folders {
@@ -79,7 +99,10 @@ func (s *memoryStorageService) getFolder(pathFragments []string) (*MemoryFolder,
 	var ok bool
 	for i := 1; i+1 < len(pathFragments); i++ {
 		pathFragment := pathFragments[i]
-		node, ok = node.folders[pathFragment]
+		node.mutext.RLock()
+		parent := node
+		node, ok = parent.folders[pathFragment]
+		parent.mutext.RUnlock()
 		if !ok {
 			return nil, noSuchFileOrDirectoryError
 		}
@@ -87,6 +110,20 @@ func (s *memoryStorageService) getFolder(pathFragments []string) (*MemoryFolder,
 	return node, nil
 }
 
+func (f *MemoryFolder) file(name string) (*MemoryFile, bool) {
+	f.mutext.RLock()
+	defer f.mutext.RUnlock()
+	result, ok := f.files[name]
+	return result, ok
+}
+
+func (f *MemoryFolder) folder(name string) (*MemoryFolder, bool) {
+	f.mutext.RLock()
+	defer f.mutext.RUnlock()
+	result, ok := f.folders[name]
+	return result, ok
+}
+
 func (s *memoryStorageService) getPath(URL string) (string, error) {
 	parsedURL, err := url.Parse(URL)
 	if err != nil {
@@ -116,10 +153,10 @@ func (s *memoryStorageService) List(URL string) ([]Object, error) {
 	}
 	var pathLeaf = pathFragments[len(pathFragments)-1]
 
-	if memoryFile, ok := node.files[pathLeaf]; ok {
+	if memoryFile, ok := node.file(pathLeaf); ok {
 		return []Object{memoryFile.Object()}, nil
 	}
-	if folder, ok := node.folders[pathLeaf]; ok {
+	if folder, ok := node.folder(pathLeaf); ok {
 		return folder.Objects(), nil
 	}
 
@@ -129,6 +166,9 @@ func (s *memoryStorageService) List(URL string) ([]Object, error) {
 //Exists returns true if resource exists
 func (s *memoryStorageService) Exists(URL string) (bool, error) {
 	objects, err := s.List(URL)
+	if err == noSuchFileOrDirectoryError {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
@@ -163,12 +203,29 @@ func (s *memoryStorageService) Download(object Object) (io.ReadCloser, error) {
 		return nil, err
 	}
 	var pathLeaf = pathFragments[len(pathFragments)-1]
-	if memoryFile, ok := node.files[pathLeaf]; ok {
+	if memoryFile, ok := node.file(pathLeaf); ok {
 		return ioutil.NopCloser(bytes.NewReader(memoryFile.content)), nil
 	}
 	return nil, noSuchFileOrDirectoryError
 }
 
+//Checksum returns cached object content checksum
+func (s *memoryStorageService) Checksum(object Object, algo string) (string, error) {
+	var urlPath, err = s.getPath(object.URL())
+	if err != nil {
+		return "", err
+	}
+	var pathFragments = strings.Split(urlPath, "/")
+	node, err := s.getFolder(pathFragments)
+	if err != nil {
+		return "", err
+	}
+	if memoryFile, ok := node.file(pathFragments[len(pathFragments)-1]); ok {
+		return memoryFile.checksum(algo)
+	}
+	return "", noSuchFileOrDirectoryError
+}
+
 //Upload uploads provided reader content for supplied url.
 func (s *memoryStorageService) Upload(URL string, reader io.Reader) error {
 	return s.UploadWithMode(URL, DefaultFileMode, reader)
@@ -185,30 +242,139 @@ func (s *memoryStorageService) UploadWithMode(URL string, mode os.FileMode, read
 		return err
 	}
 
+	s.putFile(URL, urlPath, content)
+	return nil
+}
+
+//putFile stores content at supplied path, missing parent folders are created
+func (s *memoryStorageService) putFile(URL, urlPath string, content []byte) {
 	var node = s.root
 	var pathFragments = strings.Split(urlPath, "/")
 	for i := 1; i+1 < len(pathFragments); i++ {
 		pathFragment := pathFragments[i]
-		if subFolder, ok := node.folders[pathFragment]; ok {
-			node = subFolder
-		} else {
+		node.mutext.Lock()
+		subFolder, ok := node.folders[pathFragment]
+		if !ok {
 			var folderURL = MemoryProviderScheme + "://" + strings.Join(pathFragments[:i+1], "/")
 			var folderInfo = NewFileInfo(pathFragment, 102, folderMode, time.Now(), true)
-			newFolder := newMemoryFolder(folderURL, folderInfo)
-			node.mutext.Lock()
-			node.folders[folderInfo.Name()] = newFolder
-			node.mutext.Unlock()
-			node = newFolder
+			subFolder = newMemoryFolder(folderURL, folderInfo)
+			node.folders[folderInfo.Name()] = subFolder
 		}
+		node.mutext.Unlock()
+		node = subFolder
 	}
 
 	var pathLeaf = pathFragments[len(pathFragments)-1]
 	fileInfo := NewFileInfo(pathLeaf, int64(len(content)), fileMode, time.Now(), false)
-	var memoryFile = &MemoryFile{name: URL, content: content, fileInfo: fileInfo}
+	var memoryFile = &MemoryFile{name: URL, content: content, fileInfo: fileInfo, mutex: &sync.Mutex{}, checksums: make(map[string]string)}
+	node.mutext.Lock()
 	node.files[fileInfo.Name()] = memoryFile
+	node.mutext.Unlock()
+}
+
+//CreateFolder creates folder for supplied URL with missing parent folders
+func (s *memoryStorageService) CreateFolder(URL string) error {
+	urlPath, err := s.getPath(URL)
+	if err != nil {
+		return err
+	}
+	var node = s.root
+	var pathFragments = strings.Split(urlPath, "/")
+	for i := 1; i < len(pathFragments); i++ {
+		pathFragment := pathFragments[i]
+		if pathFragment == "" {
+			continue
+		}
+		node.mutext.Lock()
+		if _, ok := node.files[pathFragment]; ok {
+			node.mutext.Unlock()
+			return fmt.Errorf("%v is a file", strings.Join(pathFragments[:i+1], "/"))
+		}
+		subFolder, ok := node.folders[pathFragment]
+		if !ok {
+			var folderURL = MemoryProviderScheme + "://" + strings.Join(pathFragments[:i+1], "/")
+			subFolder = newMemoryFolder(folderURL, NewFileInfo(pathFragment, 102, folderMode, time.Now(), true))
+			node.folders[pathFragment] = subFolder
+		}
+		node.mutext.Unlock()
+		node = subFolder
+	}
 	return nil
 }
 
+//CopyObject copies source file to destination URL sharing immutable content, folders are not supported
+func (s *memoryStorageService) CopyObject(sourceURL, destinationURL string) error {
+	sourcePath, err := s.getPath(sourceURL)
+	if err != nil {
+		return err
+	}
+	destinationPath, err := s.getPath(destinationURL)
+	if err != nil {
+		return err
+	}
+	var pathFragments = strings.Split(sourcePath, "/")
+	node, err := s.getFolder(pathFragments)
+	if err != nil {
+		return err
+	}
+	memoryFile, ok := node.file(pathFragments[len(pathFragments)-1])
+	if !ok {
+		if _, ok := node.folder(pathFragments[len(pathFragments)-1]); ok {
+			return ErrServerSideCopyNotSupported
+		}
+		return noSuchFileOrDirectoryError
+	}
+	s.putFile(destinationURL, destinationPath, memoryFile.content)
+	return nil
+}
+
+//SetMode sets file mode for supplied URL
+func (s *memoryStorageService) SetMode(URL string, mode os.FileMode) error {
+	return s.updateFileInfo(URL, func(info os.FileInfo) os.FileInfo {
+		return NewFileInfo(info.Name(), info.Size(), mode, info.ModTime(), info.IsDir())
+	})
+}
+
+//SetModTime sets modification time for supplied URL
+func (s *memoryStorageService) SetModTime(URL string, modTime time.Time) error {
+	return s.updateFileInfo(URL, func(info os.FileInfo) os.FileInfo {
+		return NewFileInfo(info.Name(), info.Size(), info.Mode(), modTime, info.IsDir())
+	})
+}
+
+//updateFileInfo replaces file or folder info for supplied URL, files are replaced with updated copy
+func (s *memoryStorageService) updateFileInfo(URL string, update func(info os.FileInfo) os.FileInfo) error {
+	urlPath, err := s.getPath(URL)
+	if err != nil {
+		return err
+	}
+	if urlPath == "/" {
+		s.root.mutext.Lock()
+		s.root.fileInfo = update(s.root.fileInfo)
+		s.root.mutext.Unlock()
+		return nil
+	}
+	var pathFragments = strings.Split(urlPath, "/")
+	node, err := s.getFolder(pathFragments)
+	if err != nil {
+		return err
+	}
+	var pathLeaf = pathFragments[len(pathFragments)-1]
+	node.mutext.Lock()
+	defer node.mutext.Unlock()
+	if memoryFile, ok := node.files[pathLeaf]; ok {
+		node.files[pathLeaf] = &MemoryFile{name: memoryFile.name, content: memoryFile.content, fileInfo: update(memoryFile.fileInfo), mutex: &sync.Mutex{}, checksums: make(map[string]string)}
+		return nil
+	}
+	if folder, ok := node.folders[pathLeaf]; ok {
+		folder.mutext.Lock()
+		folder.fileInfo = update(folder.fileInfo)
+		folder.mutext.Unlock()
+		return nil
+	}
+	return noSuchFileOrDirectoryError
+}
+
 func (s *memoryStorageService) Register(schema string, service Service) error {
 	return errors.New("unsupported")
 }
@@ -225,11 +391,13 @@ func (s *memoryStorageService) Delete(object Object) error {
 		return err
 	}
 	var pathLeaf = pathFragments[len(pathFragments)-1]
+	node.mutext.Lock()
+	defer node.mutext.Unlock()
 	if _, ok := node.files[pathLeaf]; ok {
 		delete(node.files, pathLeaf)
 		return nil
 	}
-	if _, ok := node.files[pathLeaf]; ok {
+	if _, ok := node.folders[pathLeaf]; ok {
 		delete(node.folders, pathLeaf)
 		return nil
 	}
@@ -246,21 +414,21 @@ func (s *memoryStorageService) DownloadWithURL(URL string) (io.ReadCloser, error
 }
 
 // creates a new private memory service
-func NewPrivateMemoryService() Service {
+func NewPrivateMemoryService() MemoryService {
 	return &memoryStorageService{
 		root: newMemoryFolder("mem:///", NewFileInfo("/", 102, folderMode, time.Now(), true)),
 	}
 }
 
 //creates a new memory service
-func NewMemoryService() Service {
+func NewMemoryService() MemoryService {
 	return &memoryStorageService{
 		root: MemoryRoot,
 	}
 }
 
 func init() {
-	Registry().Registry[MemoryProviderScheme] = memServiceProvider
+	RegisterProvider(MemoryProviderScheme, memServiceProvider)
 }
 
 func memServiceProvider(credentialFile string) (Service, error) {
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"github.com/viant/toolbox"
+	"path"
+	"strings"
+)
+
+//MemoryService represents memory storage service with local filesystem snapshot support
+type MemoryService interface {
+	Service
+	//ImportFrom loads local directory tree into memory root preserving relative paths, file modes and modification times
+	ImportFrom(directory string) error
+	//ExportTo writes memory content into local directory preserving file modes and modification times
+	ExportTo(directory string) error
+	//Reset removes all memory content
+	Reset()
+}
+
+//ImportFrom loads local directory tree into memory root preserving relative paths, file modes and modification times
+func (s *memoryStorageService) ImportFrom(directory string) error {
+	return CopyWithOptions(NewFileStorage(), toolbox.FileSchema+directory, s, MemoryProviderScheme+":///", &CopyOptions{PreserveAttributes: true})
+}
+
+//ExportTo writes memory content into local directory preserving file modes and modification times
+func (s *memoryStorageService) ExportTo(directory string) error {
+	fileService := NewFileStorage()
+	for _, object := range s.root.Objects() {
+		var name = strings.Trim(urlPath(object.URL()), "/")
+		if name == "" {
+			continue
+		}
+		destinationURL := toolbox.FileSchema + path.Join(directory, name)
+		if err := CopyWithOptions(s, object.URL(), fileService, destinationURL, &CopyOptions{PreserveAttributes: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Reset removes all memory content, memory shared by services created with NewMemoryService is cleared for all of them
+func (s *memoryStorageService) Reset() {
+	s.root.mutext.Lock()
+	defer s.root.mutext.Unlock()
+	s.root.files = make(map[string]*MemoryFile)
+	s.root.folders = make(map[string]*MemoryFolder)
+}
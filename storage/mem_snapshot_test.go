@@ -0,0 +1,77 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestMemoryService_ImportFrom(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_mem_snapshot")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	modTime := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	var files = map[string]os.FileMode{
+		"readme.md":            0644,
+		"bin/run.sh":           0755,
+		"config/app.json":      0600,
+		"config/env/prod.yaml": 0640,
+	}
+	for name, mode := range files {
+		filename := path.Join(parent, "source", name)
+		_ = os.MkdirAll(path.Dir(filename), 0755)
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(name), mode))
+		assert.Nil(t, os.Chmod(filename, mode))
+		assert.Nil(t, os.Chtimes(filename, modTime, modTime))
+	}
+
+	service := storage.NewPrivateMemoryService()
+	assert.Nil(t, service.ImportFrom(path.Join(parent, "source")))
+	for name, mode := range files {
+		object, err := service.StorageObject("mem:///" + name)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		assert.Equal(t, mode, object.FileInfo().Mode().Perm(), name)
+		assert.True(t, modTime.Equal(object.FileInfo().ModTime()), name)
+		content, err := storage.DownloadText(service, "mem:///"+name)
+		assert.Nil(t, err)
+		assert.Equal(t, name, content)
+	}
+	objects, err := service.List("mem:///config")
+	if assert.Nil(t, err) && assert.Equal(t, 3, len(objects)) {
+		var folders = 0
+		for _, object := range objects {
+			if object.IsFolder() {
+				folders++
+			}
+		}
+		assert.Equal(t, 2, folders)
+	}
+
+	assert.Nil(t, service.ExportTo(path.Join(parent, "target")))
+	for name, mode := range files {
+		filename := path.Join(parent, "target", name)
+		content, err := ioutil.ReadFile(filename)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		assert.Equal(t, name, string(content))
+		fileInfo, err := os.Stat(filename)
+		if assert.Nil(t, err) {
+			assert.Equal(t, mode, fileInfo.Mode().Perm(), name)
+			assert.True(t, modTime.Equal(fileInfo.ModTime()), name)
+		}
+	}
+
+	service.Reset()
+	objects, err = service.List("mem:///")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(objects))
+	exists, _ := service.Exists("mem:///readme.md")
+	assert.False(t, exists)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//ContentTypeKey represents content type metadata key
+const ContentTypeKey = "Content-Type"
+
+//MetadataUploader represents an optional service extension that uploads content with metadata
+type MetadataUploader interface {
+	//UploadWithMetadata uploads provided reader content with metadata like Content-Type, Cache-Control or custom attributes
+	UploadWithMetadata(URL string, reader io.Reader, meta map[string]string) error
+}
+
+//UploadWithMetadata uploads content with metadata if service implements MetadataUploader, otherwise metadata is ignored
+func UploadWithMetadata(service Service, URL string, reader io.Reader, meta map[string]string) error {
+	if uploader, ok := resolveService(service, URL).(MetadataUploader); ok {
+		return uploader.UploadWithMetadata(URL, reader, meta)
+	}
+	return service.Upload(URL, reader)
+}
+
+//ContentType returns content type guessed from URL extension or empty string
+func ContentType(URL string) string {
+	return mime.TypeByExtension(path.Ext(URL))
+}
+
+//contentTypeSniffLength represents maximum number of bytes used to sniff content type
+const contentTypeSniffLength = 512
+
+//DetectContentType returns content type guessed from URL extension with optional extension overrides (i.e. ".wasm": "application/wasm"),
+//content type of unknown extension is sniffed from the first 512 bytes, returned reader streams the whole supplied reader content
+func DetectContentType(URL string, reader io.Reader, overrides map[string]string) (string, io.Reader, error) {
+	var extension = strings.ToLower(path.Ext(URL))
+	if extension != "" {
+		if contentType, ok := overrides[extension]; ok {
+			return contentType, reader, nil
+		}
+		if contentType, ok := overrides[extension[1:]]; ok {
+			return contentType, reader, nil
+		}
+	}
+	if contentType := ContentType(URL); contentType != "" {
+		return contentType, reader, nil
+	}
+	var buffer = make([]byte, contentTypeSniffLength)
+	n, err := io.ReadFull(reader, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	buffer = buffer[:n]
+	var streamed = io.MultiReader(bytes.NewReader(buffer), reader)
+	if n == 0 {
+		return "", streamed, nil
+	}
+	return http.DetectContentType(buffer), streamed, nil
+}
@@ -0,0 +1,104 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+//metadataService represents a service capturing uploaded metadata
+type metadataService struct {
+	storage.Service
+	metadata map[string]map[string]string
+}
+
+func (s *metadataService) UploadWithMetadata(URL string, reader io.Reader, meta map[string]string) error {
+	s.metadata[URL] = meta
+	return s.Service.Upload(URL, reader)
+}
+
+func TestUploadWithMetadata(t *testing.T) {
+	{ //service with metadata support
+		service := &metadataService{Service: storage.NewPrivateMemoryService(), metadata: make(map[string]map[string]string)}
+		err := storage.UploadWithMetadata(service, "mem:///site/index.html", strings.NewReader("<html/>"), map[string]string{
+			"Content-Type":  "text/html",
+			"Cache-Control": "max-age=60",
+		})
+		assert.Nil(t, err)
+		assert.EqualValues(t, map[string]string{"Content-Type": "text/html", "Cache-Control": "max-age=60"}, service.metadata["mem:///site/index.html"])
+		text, err := storage.DownloadText(service, "mem:///site/index.html")
+		assert.Nil(t, err)
+		assert.Equal(t, "<html/>", text)
+	}
+	{ //service without metadata support
+		service := storage.NewPrivateMemoryService()
+		err := storage.UploadWithMetadata(service, "mem:///site/index.html", strings.NewReader("<html/>"), map[string]string{"Content-Type": "text/html"})
+		assert.Nil(t, err)
+		text, err := storage.DownloadText(service, "mem:///site/index.html")
+		assert.Nil(t, err)
+		assert.Equal(t, "<html/>", text)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	assert.True(t, strings.HasPrefix(storage.ContentType("s3://bucket/site/index.html"), "text/html"))
+	assert.Equal(t, "application/json", storage.ContentType("mem:///data/file.json"))
+	assert.Equal(t, "", storage.ContentType("mem:///data/file"))
+}
+
+func TestCopyWithOptions_DetectContentType(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	var files = map[string]string{
+		"index.html":    "<html/>",
+		"css/site.css":  "body {}",
+		"data/app.json": "{}",
+		"app.wasm":      "\x00asm",
+		"LICENSE":       "MIT",
+		"logo":          "\x89PNG\x0D\x0A\x1A\x0A" + strings.Repeat("x", 1024),
+	}
+	for name, content := range files {
+		_ = memService.Upload("mem:///site/"+name, strings.NewReader(content))
+	}
+	service := &metadataService{Service: memService, metadata: make(map[string]map[string]string)}
+	err := storage.CopyWithOptions(memService, "mem:///site", service, "mem:///deploy", &storage.CopyOptions{
+		DetectContentType: true,
+		ContentTypes:      map[string]string{".wasm": "application/wasm"},
+	})
+	assert.Nil(t, err)
+	var expected = map[string]string{
+		"index.html":    "text/html",
+		"css/site.css":  "text/css",
+		"data/app.json": "application/json",
+		"app.wasm":      "application/wasm",
+		"LICENSE":       "text/plain",
+		"logo":          "image/png",
+	}
+	for name, expectedType := range expected {
+		URL := "mem:///deploy/" + name
+		if assert.NotNil(t, service.metadata[URL], name) {
+			assert.True(t, strings.HasPrefix(service.metadata[URL][storage.ContentTypeKey], expectedType), name+": "+service.metadata[URL][storage.ContentTypeKey])
+		}
+		text, err := storage.DownloadText(memService, URL)
+		assert.Nil(t, err, name)
+		assert.Equal(t, files[name], text, name)
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	contentType, reader, err := storage.DetectContentType("mem:///data/file", strings.NewReader("plain text"), nil)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(contentType, "text/plain"))
+	content, _ := ioutil.ReadAll(reader)
+	assert.Equal(t, "plain text", string(content))
+
+	contentType, _, err = storage.DetectContentType("mem:///data/file.MJS", strings.NewReader(""), map[string]string{"mjs": "text/javascript"})
+	assert.Nil(t, err)
+	assert.Equal(t, "text/javascript", contentType)
+
+	contentType, _, err = storage.DetectContentType("mem:///data/empty", strings.NewReader(""), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", contentType)
+}
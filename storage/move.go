@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+)
+
+//Mover represents an optional service extension that moves objects without streaming content
+type Mover interface {
+	//Move moves source URL object to destination URL, folders are moved with their content
+	Move(sourceURL, destinationURL string) error
+}
+
+//Move moves source URL object to destination URL, it uses Mover if service resolved for both URLs implements it, otherwise copies and deletes source object
+func Move(service Service, sourceURL, destinationURL string) error {
+	if truncatePath(sourceURL) == truncatePath(destinationURL) {
+		return nil
+	}
+	var err error
+	sourceService := resolveService(service, sourceURL)
+	if mover, ok := sourceService.(Mover); ok && sourceService == resolveService(service, destinationURL) {
+		err = mover.Move(sourceURL, destinationURL)
+	} else {
+		err = move(service, sourceURL, destinationURL)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to move %v -> %v: %v", sourceURL, destinationURL, err)
+	}
+	return err
+}
+
+//move copies source object to destination URL and deletes the source
+func move(service Service, sourceURL, destinationURL string) error {
+	object, err := service.StorageObject(sourceURL)
+	if err != nil {
+		return err
+	}
+	if object.IsFolder() {
+		err = Copy(service, sourceURL, service, destinationURL, nil, nil)
+	} else {
+		err = moveContent(service, object, destinationURL)
+	}
+	if err != nil {
+		return err
+	}
+	return service.Delete(object)
+}
+
+func moveContent(service Service, object Object, destinationURL string) error {
+	reader, err := service.Download(object)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	mode := DefaultFileMode
+	if fileInfo := object.FileInfo(); fileInfo != nil {
+		mode = fileInfo.Mode()
+	}
+	return service.UploadWithMode(destinationURL, mode, reader)
+}
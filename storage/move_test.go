@@ -0,0 +1,112 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestMove(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_move")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+
+	var useCases = []struct {
+		description string
+		service     storage.Service
+		baseURL     string
+	}{
+		{
+			description: "file service",
+			service:     storage.NewService(),
+			baseURL:     "file://" + parent,
+		},
+		{
+			description: "memory service",
+			service:     storage.NewPrivateMemoryService(),
+			baseURL:     "mem:///move",
+		},
+	}
+
+	for _, useCase := range useCases {
+		service := useCase.service
+		var URL = func(relativePath string) string {
+			return toolbox.URLPathJoin(useCase.baseURL, relativePath)
+		}
+		for relativePath, content := range map[string]string{
+			"a/file1.txt":       "abc",
+			"a/sub/file2.txt":   "xyz",
+			"b/file3.txt":       "123",
+			"b/existing.txt":    "old",
+			"b/replacement.txt": "new",
+		} {
+			assert.Nil(t, service.Upload(URL(relativePath), strings.NewReader(content)), useCase.description)
+		}
+
+		{ //same folder rename
+			err := storage.Move(service, URL("b/file3.txt"), URL("b/renamed.txt"))
+			assert.Nil(t, err, useCase.description)
+			exists, _ := service.Exists(URL("b/file3.txt"))
+			assert.False(t, exists, useCase.description)
+			text, err := storage.DownloadText(service, URL("b/renamed.txt"))
+			assert.Nil(t, err, useCase.description)
+			assert.Equal(t, "123", text, useCase.description)
+		}
+		{ //cross folder move
+			err := storage.Move(service, URL("a"), URL("c/moved"))
+			assert.Nil(t, err, useCase.description)
+			exists, _ := service.Exists(URL("a/file1.txt"))
+			assert.False(t, exists, useCase.description)
+			for relativePath, expected := range map[string]string{"c/moved/file1.txt": "abc", "c/moved/sub/file2.txt": "xyz"} {
+				text, err := storage.DownloadText(service, URL(relativePath))
+				assert.Nil(t, err, useCase.description+" "+relativePath)
+				assert.Equal(t, expected, text, useCase.description+" "+relativePath)
+			}
+		}
+		{ //overwrite existing object
+			err := storage.Move(service, URL("b/replacement.txt"), URL("b/existing.txt"))
+			assert.Nil(t, err, useCase.description)
+			exists, _ := service.Exists(URL("b/replacement.txt"))
+			assert.False(t, exists, useCase.description)
+			text, err := storage.DownloadText(service, URL("b/existing.txt"))
+			assert.Nil(t, err, useCase.description)
+			assert.Equal(t, "new", text, useCase.description)
+		}
+	}
+}
+
+type moverService struct {
+	storage.Service
+	moved []string
+}
+
+func (s *moverService) Move(sourceURL, destinationURL string) error {
+	s.moved = append(s.moved, sourceURL+" -> "+destinationURL)
+	return storage.Move(s.Service, sourceURL, destinationURL)
+}
+
+func TestMove_RegisteredMover(t *testing.T) {
+	mover := &moverService{Service: storage.NewPrivateMemoryService()}
+	service := storage.NewService()
+	assert.Nil(t, service.Register("mover", mover))
+	assert.Nil(t, service.Upload("mover:///move/file1.txt", strings.NewReader("abc")))
+	assert.Nil(t, service.Upload("mover:///move/file2.txt", strings.NewReader("xyz")))
+
+	assert.Nil(t, storage.Move(service, "mover:///move/file1.txt", "mover:///move/renamed.txt"))
+	assert.EqualValues(t, []string{"mover:///move/file1.txt -> mover:///move/renamed.txt"}, mover.moved)
+	text, err := storage.DownloadText(service, "mover:///move/renamed.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", text)
+
+	assert.Nil(t, storage.Move(service, "mover:///move/file2.txt", "mem:///move_registered_mover/file2.txt"))
+	assert.Equal(t, 1, len(mover.moved), "cross scheme move does not use Mover")
+	exists, _ := service.Exists("mover:///move/file2.txt")
+	assert.False(t, exists)
+	text, err = storage.DownloadText(service, "mem:///move_registered_mover/file2.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "xyz", text)
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"github.com/viant/toolbox"
+	"path"
+	"strings"
+)
+
+//PatternLister represents an optional service extension providing native pattern listing
+type PatternLister interface {
+	//ListWithPattern returns objects which path relative to base URL matches supplied glob pattern
+	ListWithPattern(baseURL string, pattern string) ([]Object, error)
+}
+
+//ListWithPattern returns objects which path relative to base URL matches supplied glob pattern, '**' matches any number of path segments
+func ListWithPattern(service Service, baseURL string, pattern string) ([]Object, error) {
+	if lister, ok := resolveService(service, baseURL).(PatternLister); ok {
+		return lister.ListWithPattern(baseURL, pattern)
+	}
+	return listWithPattern(service, baseURL, pattern)
+}
+
+func listWithPattern(service Service, baseURL string, pattern string) ([]Object, error) {
+	var result = make([]Object, 0)
+	pattern = strings.Trim(pattern, "/")
+	if !strings.ContainsAny(pattern, "*?[") {
+		URL := baseURL
+		if pattern != "" {
+			URL = toolbox.URLPathJoin(baseURL, pattern)
+		}
+		exists, err := service.Exists(URL)
+		if err != nil || !exists {
+			return result, err
+		}
+		object, err := service.StorageObject(URL)
+		if err != nil {
+			return nil, err
+		}
+		return append(result, object), nil
+	}
+	var patternSegments = strings.Split(pattern, "/")
+	err := walkWithPattern(service, baseURL, urlPath(baseURL), patternSegments, &result)
+	return result, err
+}
+
+func walkWithPattern(service Service, URL, basePath string, patternSegments []string, result *[]Object) error {
+	objects, err := service.List(URL)
+	if err != nil {
+		return err
+	}
+	var folderPath = urlPath(URL)
+	for _, object := range objects {
+		var objectPath = urlPath(object.URL())
+		if objectPath == folderPath {
+			continue
+		}
+		var relativePath = strings.Trim(strings.TrimPrefix(objectPath, basePath), "/")
+		var segments = strings.Split(relativePath, "/")
+		if matchPathSegments(patternSegments, segments) {
+			*result = append(*result, object)
+		}
+		if object.IsFolder() && canMatchDescendant(patternSegments, segments) {
+			if err = walkWithPattern(service, object.URL(), basePath, patternSegments, result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//canMatchDescendant returns true if pattern can match any path below supplied folder segments
+func canMatchDescendant(patternSegments, folderSegments []string) bool {
+	for i, segment := range folderSegments {
+		if i >= len(patternSegments) {
+			return false
+		}
+		if patternSegments[i] == "**" {
+			return true
+		}
+		if matched, _ := path.Match(patternSegments[i], segment); !matched {
+			return false
+		}
+	}
+	return len(patternSegments) > len(folderSegments)
+}
@@ -0,0 +1,71 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestListWithPattern(t *testing.T) {
+	service := storage.NewPrivateMemoryService()
+	for _, file := range []string{"config.json", "readme.md", "app/app.json", "app/app.js", "app/lib/util.js", "app/lib/deep/more.json", "docs/guide.md"} {
+		_ = service.Upload("mem:///pattern/"+file, strings.NewReader(file))
+	}
+
+	var useCases = []struct {
+		description string
+		pattern     string
+		expected    []string
+	}{
+		{
+			description: "top level wildcard",
+			pattern:     "*.json",
+			expected:    []string{"mem:///pattern/config.json"},
+		},
+		{
+			description: "recursive wildcard",
+			pattern:     "**/*.json",
+			expected:    []string{"mem:///pattern/app/app.json", "mem:///pattern/app/lib/deep/more.json", "mem:///pattern/config.json"},
+		},
+		{
+			description: "nested wildcard",
+			pattern:     "app/*/*.js",
+			expected:    []string{"mem:///pattern/app/lib/util.js"},
+		},
+		{
+			description: "folders",
+			pattern:     "*",
+			expected:    []string{"mem:///pattern/app", "mem:///pattern/config.json", "mem:///pattern/docs", "mem:///pattern/readme.md"},
+		},
+		{
+			description: "no match",
+			pattern:     "**/*.xml",
+			expected:    []string{},
+		},
+		{
+			description: "direct lookup",
+			pattern:     "app/lib/util.js",
+			expected:    []string{"mem:///pattern/app/lib/util.js"},
+		},
+		{
+			description: "direct lookup of missing object",
+			pattern:     "app/missing.js",
+			expected:    []string{},
+		},
+	}
+
+	for _, useCase := range useCases {
+		objects, err := storage.ListWithPattern(service, "mem:///pattern", useCase.pattern)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		var actual = make([]string, 0)
+		for _, object := range objects {
+			actual = append(actual, object.URL())
+		}
+		sort.Strings(actual)
+		assert.EqualValues(t, useCase.expected, actual, useCase.description)
+	}
+}
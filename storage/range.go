@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+//Ranger represents an optional service extension that downloads object byte range
+type Ranger interface {
+	//DownloadWithRange returns reader for object bytes starting at from offset up to, but excluding to offset, negative to reads till the end
+	DownloadWithRange(object Object, from, to int64) (io.ReadCloser, error)
+}
+
+//Appender represents an optional service extension that appends content to existing object
+type Appender interface {
+	//Append appends reader content to supplied URL object
+	Append(URL string, reader io.Reader) error
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+//DownloadWithRange returns reader for object bytes starting at from offset up to, but excluding to offset, negative to reads till the end,
+//it uses service Ranger if available, otherwise it reads and discards content prefix
+func DownloadWithRange(service Service, object Object, from, to int64) (io.ReadCloser, error) {
+	if from < 0 || (to >= 0 && to < from) {
+		return nil, fmt.Errorf("invalid range %v-%v for %v", from, to, object.URL())
+	}
+	if ranger, ok := resolveService(service, object.URL()).(Ranger); ok {
+		return ranger.DownloadWithRange(object, from, to)
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return nil, err
+	}
+	if discarded, err := io.CopyN(ioutil.Discard, reader, from); err != nil {
+		_ = reader.Close()
+		if err == io.EOF {
+			return nil, fmt.Errorf("range start %v exceeds %v size %v", from, object.URL(), discarded)
+		}
+		return nil, err
+	}
+	if to < 0 {
+		return reader, nil
+	}
+	return &readCloser{Reader: io.LimitReader(reader, to-from), Closer: reader}, nil
+}
+
+//resolveService returns service handling supplied URL, storage service router is resolved per URL scheme and Wrapper is unwrapped
+func resolveService(service Service, URL string) Service {
+	for {
+		if wrapper, ok := service.(Wrapper); ok {
+			service = wrapper.Unwrap()
+			continue
+		}
+		if storageService, ok := service.(*storageService); ok {
+			if result, err := storageService.getServiceForSchema(URL); err == nil && result != service {
+				service = result
+				continue
+			}
+		}
+		return service
+	}
+}
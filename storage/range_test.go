@@ -0,0 +1,98 @@
+package storage_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDownloadWithRange(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_range")
+	_ = os.RemoveAll(parent)
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	var data = make([]byte, 3*1024*1024+7)
+	rand.New(rand.NewSource(1)).Read(data)
+	filename := path.Join(parent, "data.bin")
+	if !assert.Nil(t, ioutil.WriteFile(filename, data, 0644)) {
+		return
+	}
+	var size = int64(len(data))
+	memService := storage.NewPrivateMemoryService()
+	assert.Nil(t, memService.Upload("mem:///range/data.bin", bytes.NewReader(data)))
+
+	var services = map[string]string{
+		"file://" + filename:    "file",
+		"mem:///range/data.bin": "memory",
+	}
+	var useCases = []struct {
+		description string
+		from        int64
+		to          int64
+		hasError    bool
+	}{
+		{description: "header", from: 0, to: 16},
+		{description: "middle", from: 1024*1024 - 1, to: 2*1024*1024 + 1},
+		{description: "tail", from: size - 10, to: size},
+		{description: "till the end", from: 1024, to: -1},
+		{description: "empty at the end", from: size, to: -1},
+		{description: "from exceeds size", from: size + 1, to: -1, hasError: true},
+		{description: "invalid range", from: 10, to: 5, hasError: true},
+	}
+
+	for URL, name := range services {
+		service := storage.NewService()
+		if name == "memory" {
+			service = memService
+		}
+		object, err := service.StorageObject(URL)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		for _, useCase := range useCases {
+			reader, err := storage.DownloadWithRange(service, object, useCase.from, useCase.to)
+			if useCase.hasError {
+				assert.NotNil(t, err, name+" "+useCase.description)
+				continue
+			}
+			if !assert.Nil(t, err, name+" "+useCase.description) {
+				continue
+			}
+			actual, err := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.Nil(t, err)
+			var to = useCase.to
+			if to < 0 {
+				to = size
+			}
+			assert.True(t, bytes.Equal(data[useCase.from:to], actual), name+" "+useCase.description)
+		}
+	}
+}
+
+func TestCopyWithOptions_Resume(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_resume")
+	_ = os.RemoveAll(parent)
+	_ = os.MkdirAll(path.Join(parent, "source"), 0755)
+	_ = os.MkdirAll(path.Join(parent, "target"), 0755)
+	defer os.RemoveAll(parent)
+	var data = make([]byte, 2*1024*1024+3)
+	rand.New(rand.NewSource(2)).Read(data)
+	assert.Nil(t, ioutil.WriteFile(path.Join(parent, "source", "data.bin"), data, 0644))
+	var partial = append([]byte{}, data[:1024*1024]...)
+	partial[0] ^= 0xFF //marks existing content, which resumed copy should keep
+	assert.Nil(t, ioutil.WriteFile(path.Join(parent, "target", "data.bin"), partial, 0644))
+
+	service := storage.NewService()
+	err := storage.CopyWithOptions(service, "file://"+path.Join(parent, "source"), service, "file://"+path.Join(parent, "target"), &storage.CopyOptions{Resume: true})
+	assert.Nil(t, err)
+	actual, err := ioutil.ReadFile(path.Join(parent, "target", "data.bin"))
+	assert.Nil(t, err)
+	assert.Equal(t, len(data), len(actual))
+	assert.True(t, bytes.Equal(append(partial, data[len(partial):]...), actual))
+}
@@ -1,22 +1,100 @@
 package storage
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
 type registry struct {
+	//Registry represents registered providers, Deprecated: use RegisterProvider to register provider concurrently
 	Registry map[string]Provider
+	mutex    *sync.RWMutex
 }
 
+//Get returns provider for supplied scheme or nil
 func (p *registry) Get(namespace string) func(credentialFile string) (Service, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 	return p.Registry[namespace]
 }
 
-var registrySingleton *registry
+//Register registers provider for supplied scheme
+func (p *registry) Register(scheme string, provider Provider) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Registry[scheme] = provider
+}
+
+//Schemes returns sorted registered schemes
+func (p *registry) Schemes() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	var result = make([]string, 0, len(p.Registry))
+	for scheme := range p.Registry {
+		result = append(result, scheme)
+	}
+	sort.Strings(result)
+	return result
+}
+
+var registrySingleton = &registry{
+	Registry: make(map[string]Provider),
+	mutex:    &sync.RWMutex{},
+}
 
 //Registry returns new provider
 func Registry() *registry {
-	if registrySingleton != nil {
-		return registrySingleton
-	}
-	registrySingleton = &registry{
-		Registry: make(map[string]Provider),
-	}
 	return registrySingleton
 }
+
+//RegisterProvider registers storage service provider for supplied scheme
+func RegisterProvider(scheme string, provider func(credentialFile string) (Service, error)) {
+	registrySingleton.Register(scheme, provider)
+}
+
+var credentialMutex = &sync.RWMutex{}
+var credentials = make(map[string]string)
+
+//UseCredential registers default credential file for supplied scheme, it is used by CopyURL unless copy options specify credential
+func UseCredential(scheme string, credentialFile string) {
+	credentialMutex.Lock()
+	defer credentialMutex.Unlock()
+	credentials[scheme] = credentialFile
+}
+
+//Credential returns registered default credential file for supplied scheme or empty string
+func Credential(scheme string) string {
+	credentialMutex.RLock()
+	defer credentialMutex.RUnlock()
+	return credentials[scheme]
+}
+
+//UnsupportedSchemeError represents URL scheme without registered provider
+type UnsupportedSchemeError struct {
+	Scheme  string
+	URL     string
+	Schemes []string //registered schemes
+}
+
+//Error returns error message
+func (e *UnsupportedSchemeError) Error() string {
+	return fmt.Sprintf("unsupported scheme %v in %v, registered schemes: %v", e.Scheme, e.URL, strings.Join(e.Schemes, ", "))
+}
+
+//ProviderError represents registered provider failure to create a service
+type ProviderError struct {
+	Scheme string
+	Err    error
+}
+
+//Error returns error message
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("failed lookup service for %v: %v", e.Scheme, e.Err)
+}
+
+//Unwrap returns provider error
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
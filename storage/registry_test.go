@@ -0,0 +1,114 @@
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegisterProvider(t *testing.T) {
+	var group = &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		group.Add(2)
+		scheme := fmt.Sprintf("test%v", i)
+		go func() {
+			defer group.Done()
+			storage.RegisterProvider(scheme, func(credentialFile string) (storage.Service, error) {
+				return storage.NewPrivateMemoryService(), nil
+			})
+		}()
+		go func() {
+			defer group.Done()
+			_ = storage.Registry().Get(scheme)
+			_, _ = storage.NewServiceForURL("mem:///registry", "")
+		}()
+	}
+	group.Wait()
+	for i := 0; i < 20; i++ {
+		scheme := fmt.Sprintf("test%v", i)
+		assert.NotNil(t, storage.Registry().Get(scheme), scheme)
+		service, err := storage.NewServiceForURL(scheme+"://host/path", "")
+		assert.Nil(t, err)
+		assert.NotNil(t, service)
+	}
+	assert.Contains(t, storage.Registry().Schemes(), "mem")
+
+	_, err := storage.NewServiceForURL("unknown://host/path", "")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unsupported scheme unknown")
+		assert.Contains(t, err.Error(), "mem")
+	}
+}
+
+func TestCopyURL(t *testing.T) {
+	service := storage.NewMemoryService()
+	_ = service.Upload("mem:///copy_url/source/file1.txt", strings.NewReader("abc"))
+	err := storage.CopyURL("mem:///copy_url/source", "mem:///copy_url/target", nil)
+	assert.Nil(t, err)
+	text, err := storage.DownloadText(service, "mem:///copy_url/target/file1.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", text)
+}
+
+var errRejectedCredential = errors.New("credential was rejected")
+
+func TestCopyURL_Credential(t *testing.T) {
+	var usedCredentials = make([]string, 0)
+	storage.RegisterProvider("vault", func(credentialFile string) (storage.Service, error) {
+		if credentialFile == "" {
+			return nil, errors.New("credential file was empty")
+		}
+		if credentialFile == "/tmp/rejected.json" {
+			return nil, errRejectedCredential
+		}
+		usedCredentials = append(usedCredentials, credentialFile)
+		return storage.NewMemoryService(), nil
+	})
+	service := storage.NewMemoryService()
+	_ = service.Upload("mem:///copy_url_credential/source/file1.txt", strings.NewReader("abc"))
+
+	err := storage.CopyURL("mem:///copy_url_credential/source", "vault:///copy_url_credential/target1", nil)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "failed to create vault service with provider, credential was neither supplied nor registered")
+		assert.Contains(t, err.Error(), "credential file was empty")
+	}
+	err = storage.CopyURL("mem:///copy_url_credential/source", "vault:///copy_url_credential/target1", &storage.CopyOptions{DestinationCredential: "/tmp/rejected.json"})
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, errRejectedCredential), err.Error())
+		assert.Contains(t, err.Error(), "failed to create vault service with provider for credential /tmp/rejected.json")
+	}
+	err = storage.CopyURL("mem:///copy_url_credential/source", "missing:///copy_url_credential/target", nil)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unsupported scheme missing")
+	}
+
+	storage.UseCredential("vault", "/tmp/default.json")
+	assert.Equal(t, "/tmp/default.json", storage.Credential("vault"))
+	err = storage.CopyURL("mem:///copy_url_credential/source", "vault:///copy_url_credential/target2", nil)
+	assert.Nil(t, err)
+	err = storage.CopyURL("mem:///copy_url_credential/source", "vault:///copy_url_credential/target3", &storage.CopyOptions{DestinationCredential: "/tmp/override.json"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"/tmp/default.json", "/tmp/override.json"}, usedCredentials)
+	for _, target := range []string{"target2", "target3"} {
+		text, err := storage.DownloadText(service, "mem:///copy_url_credential/"+target+"/file1.txt")
+		assert.Nil(t, err, target)
+		assert.Equal(t, "abc", text, target)
+	}
+
+	parent := path.Join(os.TempDir(), "storage_copy_url_credential")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	err = storage.CopyURL("mem:///copy_url_credential/source", "file://"+parent, nil)
+	assert.Nil(t, err)
+	content, err := ioutil.ReadFile(path.Join(parent, "file1.txt"))
+	if assert.Nil(t, err) {
+		assert.Equal(t, "abc", string(content))
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+//RetryPolicy represents retry policy for transient storage errors
+type RetryPolicy struct {
+	MaxAttempts  int                  //max number of attempts, 0 or 1 disables retries
+	InitialDelay time.Duration        //delay before the first retry
+	MaxDelay     time.Duration        //max delay between attempts, 0 means no limit
+	Multiplier   float64              //delay multiplier applied after each retry, 2 by default
+	IsRetryable  func(err error) bool //optional error classifier, all errors are retried by default
+	Sleeper      func(time.Duration)  //optional sleeper used instead of context aware timer, i.e. to fake delays in tests
+}
+
+//isRetryable returns true if supplied error can be retried
+func (p *RetryPolicy) isRetryable(err error) bool {
+	if p.IsRetryable == nil {
+		return true
+	}
+	return p.IsRetryable(err)
+}
+
+//delay returns a delay before the next attempt following supplied attempt number
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	var multiplier = p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	var result = float64(p.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		result *= multiplier
+		if p.MaxDelay > 0 && result >= float64(p.MaxDelay) {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && result > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(result)
+}
+
+//RunWithContext runs supplied function until it succeeds, returns non retryable error or the max attempts is reached, it stops retrying once context is done,
+//HTTPStatusError RetryAfter takes precedence over policy delay
+func (p *RetryPolicy) RunWithContext(ctx context.Context, URL string, fn func() error) error {
+	var err error
+	var attempt = 1
+	for ; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= p.MaxAttempts || !p.isRetryable(err) {
+			break
+		}
+		delay := p.delay(attempt)
+		var statusError *HTTPStatusError
+		if errors.As(err, &statusError) && statusError.RetryAfter > 0 {
+			delay = statusError.RetryAfter
+		}
+		if p.Sleeper != nil {
+			p.Sleeper(delay)
+			continue
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("failed %v after %v attempt(s): %v", URL, attempt, err)
+}
+
+//IsTransientError returns true for errors worth retrying: 5xx and 429 HTTPStatusError, temporary or timed out network errors and unexpected EOF,
+//other HTTP status errors i.e. 4xx are never transient
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusError *HTTPStatusError
+	if errors.As(err, &statusError) {
+		return statusError.StatusCode >= 500 || statusError.StatusCode == 429
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return netError.Timeout() || isTemporary(netError)
+	}
+	return false
+}
+
+//isTemporary returns true if network error declares itself temporary, i.e. connection reset
+func isTemporary(err error) bool {
+	temporary, ok := err.(interface{ Temporary() bool })
+	return ok && temporary.Temporary()
+}
@@ -0,0 +1,31 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_RunWithContext(t *testing.T) {
+	var delays = make([]time.Duration, 0)
+	policy := &storage.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, Sleeper: func(delay time.Duration) {
+		delays = append(delays, delay)
+	}}
+	var attempts = 0
+	err := policy.RunWithContext(context.Background(), "http://localhost/data.json", func() error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("failed to download, %w", &storage.HTTPStatusError{StatusCode: 503, RetryAfter: 3 * time.Second})
+		}
+		if attempts == 2 {
+			return &storage.HTTPStatusError{StatusCode: 503}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.EqualValues(t, []time.Duration{3 * time.Second, 2 * time.Millisecond}, delays)
+}
@@ -17,6 +17,7 @@ func init() {
 	SetDefaultProvider()
 }
 
+//serviceProvider returns s3 service for supplied credential file, with empty credential file default aws credential chain is used
 func serviceProvider(credentialFile string) (storage.Service, error) {
 	s3config := &cred.Config{}
 	if credentialFile != "" {
@@ -24,23 +25,26 @@ func serviceProvider(credentialFile string) (storage.Service, error) {
 			dir, _ := filepath.Abs(filepath.Dir(os.Args[0]))
 			credentialFile = path.Join(dir, credentialFile)
 		}
-		resource := url.NewResource(credentialFile)
-		err := resource.Decode(s3config)
+		resource := &url.Resource{Credentials: credentialFile}
+		credential, err := resource.LoadCredentialConfig(true)
 		if err != nil {
 			return nil, err
 		}
+		if s3config, err = credential.Config(); err != nil {
+			return nil, err
+		}
 	}
 	return NewService(s3config), nil
 }
 
 //SetProvider set s3 provider with dynamic credentials
 func SetDefaultProvider() {
-	storage.Registry().Registry[ProviderScheme] = serviceProvider
+	storage.RegisterProvider(ProviderScheme, serviceProvider)
 }
 
 //SetProvider set s3 provider with supplied config
 func SetProvider(config *cred.Config) {
-	storage.Registry().Registry[ProviderScheme] = func(string) (storage.Service, error) {
+	storage.RegisterProvider(ProviderScheme, func(string) (storage.Service, error) {
 		return NewService(config), nil
-	}
+	})
 }
@@ -1,11 +1,18 @@
 package s3
 
 import (
+	"io/ioutil"
+	"os"
 	"path"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/cred"
 	"github.com/viant/toolbox/storage"
 )
 
@@ -24,3 +31,114 @@ func TestLoadingCredentialFile(t *testing.T) {
 	assert.NotNil(t, service)
 
 }
+
+func TestServiceProvider(t *testing.T) {
+	{ //credential file
+		credentialFile, err := ioutil.TempFile("", "s3_credentials*.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer os.Remove(credentialFile.Name())
+		_, _ = credentialFile.WriteString(`{"Region":"us-west-2","Key":"MOCKKEY","Secret":"MOCKSECRET","Token":"MOCKTOKEN"}`)
+		_ = credentialFile.Close()
+
+		storageService, err := serviceProvider(credentialFile.Name())
+		if !assert.Nil(t, err) {
+			return
+		}
+		config := storageService.(*service).config
+		assert.Equal(t, "us-west-2", config.Region)
+		assert.Equal(t, "MOCKKEY", config.Key)
+		assert.Equal(t, "MOCKSECRET", config.Secret)
+		assert.Equal(t, "MOCKTOKEN", config.Token)
+
+		awsConfig, err := storageService.(*service).getAwsConfig()
+		if assert.Nil(t, err) {
+			assert.Equal(t, "us-west-2", *awsConfig.Region)
+			value, err := awsConfig.Credentials.Get()
+			assert.Nil(t, err)
+			assert.Equal(t, "MOCKTOKEN", value.SessionToken)
+		}
+	}
+	{ //default credential chain
+		defer os.Setenv("AWS_REGION", os.Getenv("AWS_REGION"))
+		defer os.Setenv("AWS_DEFAULT_REGION", os.Getenv("AWS_DEFAULT_REGION"))
+		_ = os.Setenv("AWS_REGION", "eu-west-1")
+		storageService, err := serviceProvider("")
+		if !assert.Nil(t, err) {
+			return
+		}
+		awsConfig, err := storageService.(*service).getAwsConfig()
+		if assert.Nil(t, err) {
+			assert.Equal(t, "eu-west-1", *awsConfig.Region)
+			assert.Nil(t, awsConfig.Credentials)
+		}
+
+		_ = os.Setenv("AWS_REGION", "")
+		_ = os.Setenv("AWS_DEFAULT_REGION", "")
+		_, err = storageService.(*service).getAwsConfig()
+		assert.NotNil(t, err)
+	}
+}
+
+func TestService_getAwsConfig(t *testing.T) {
+	defer os.Setenv(EndpointEnvKey, os.Getenv(EndpointEnvKey))
+	_ = os.Setenv(EndpointEnvKey, "")
+	s3Service := &service{config: &cred.Config{
+		Region:           "us-east-1",
+		Endpoint:         "localhost:9000",
+		DisableSSL:       true,
+		S3ForcePathStyle: true,
+	}}
+	awsConfig, err := s3Service.getAwsConfig()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "localhost:9000", *awsConfig.Endpoint)
+		assert.True(t, *awsConfig.DisableSSL)
+		assert.True(t, *awsConfig.S3ForcePathStyle)
+	}
+	_ = os.Setenv(EndpointEnvKey, "http://minio:9000")
+	awsConfig, err = s3Service.getAwsConfig()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "http://minio:9000", *awsConfig.Endpoint)
+	}
+}
+
+func TestService_Checksum(t *testing.T) {
+	var useCases = []struct {
+		description string
+		eTag        *string
+		algo        string
+		expect      string
+	}{
+		{
+			description: "single part etag",
+			eTag:        aws.String(`"900150983CD24FB0D6963F7D28E17F72"`),
+			algo:        storage.MD5,
+			expect:      "900150983cd24fb0d6963f7d28e17f72",
+		},
+		{
+			description: "multipart etag",
+			eTag:        aws.String(`"d41d8cd98f00b204e9800998ecf8427e-3"`),
+			algo:        storage.MD5,
+			expect:      "",
+		},
+		{
+			description: "unsupported algorithm",
+			eTag:        aws.String(`"900150983cd24fb0d6963f7d28e17f72"`),
+			algo:        storage.SHA256,
+			expect:      "",
+		},
+		{
+			description: "missing etag",
+			algo:        storage.MD5,
+			expect:      "",
+		},
+	}
+	service := &service{config: &cred.Config{}}
+	for _, useCase := range useCases {
+		object := newStorageObject("s3://bucket/abc.txt", &s3.Object{Key: aws.String("abc.txt"), ETag: useCase.eTag}, storage.NewFileInfo("abc.txt", 3, 0644, time.Now(), false))
+		checksum, err := service.Checksum(object, useCase.algo)
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expect, checksum, useCase.description)
+	}
+}
@@ -23,6 +23,11 @@ import (
 
 var defaultTime = time.Time{}
 
+const userMetadataPrefix = "x-amz-meta-"
+
+//EndpointEnvKey represents environment variable overriding s3 endpoint
+const EndpointEnvKey = "AWS_S3_ENDPOINT"
+
 type service struct {
 	config *cred.Config
 }
@@ -47,16 +52,27 @@ func listFolders(client *s3.S3, url *url.URL, result *[]storage.Object) error {
 		return err
 	}
 	for _, prefix := range prefixes {
-		pathURL := "s3://" + url.Host + "/" + *prefix.Prefix
-		var _, name = toolbox.URLSplit(pathURL)
-		var fileMode, _ = storage.NewFileMode("drw-rw-rw-")
-		var fileInfo = storage.NewFileInfo(name, 102, fileMode, defaultTime, fileMode.IsDir())
-		var object = newStorageObject(pathURL, prefix, fileInfo)
-		*result = append(*result, object)
+		*result = append(*result, newFolderObject(url.Host, prefix))
 	}
 	return nil
 }
 
+func newFolderObject(bucket string, prefix *s3.CommonPrefix) storage.Object {
+	pathURL := "s3://" + bucket + "/" + *prefix.Prefix
+	var _, name = toolbox.URLSplit(pathURL)
+	var fileMode, _ = storage.NewFileMode("drw-rw-rw-")
+	var fileInfo = storage.NewFileInfo(name, 102, fileMode, defaultTime, fileMode.IsDir())
+	return newStorageObject(pathURL, prefix, fileInfo)
+}
+
+func newContentObject(bucket string, content *s3.Object) storage.Object {
+	objectURL := "s3://" + bucket + "/" + *content.Key
+	var _, name = toolbox.URLSplit(objectURL)
+	var fileMode, _ = storage.NewFileMode("-rw-rw-rw-")
+	var fileInfo = storage.NewFileInfo(name, *content.Size, fileMode, *content.LastModified, fileMode.IsDir())
+	return newStorageObject(objectURL, content, fileInfo)
+}
+
 func listContent(client *s3.S3, parsedURL *url.URL, result *[]storage.Object) error {
 	var path = parsedURL.Path
 
@@ -81,26 +97,49 @@ func listContent(client *s3.S3, parsedURL *url.URL, result *[]storage.Object) er
 		return err
 	}
 	for _, content := range contents {
-		objectURL := "s3://" + parsedURL.Host + "/" + *content.Key
-		var _, name = toolbox.URLSplit(objectURL)
-		var fileMode, _ = storage.NewFileMode("-rw-rw-rw-")
-		var fileInfo = storage.NewFileInfo(name, *content.Size, fileMode, *content.LastModified, fileMode.IsDir())
-		var object = newStorageObject(objectURL, content, fileInfo)
-		*result = append(*result, object)
+		*result = append(*result, newContentObject(parsedURL.Host, content))
 	}
 	return nil
 }
 
+//getAwsConfig returns aws config with static credentials if secret is specified, otherwise default credential chain (environment, shared credentials, IAM role) is used
 func (s *service) getAwsConfig() (*aws.Config, error) {
+	region := s.config.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("region was empty, specify Region in credential file or AWS_REGION environment variable")
+	}
+	config := aws.NewConfig().WithRegion(region)
+	endpoint := s.config.Endpoint
+	if customEndpoint := os.Getenv(EndpointEnvKey); customEndpoint != "" {
+		endpoint = customEndpoint
+	}
+	if endpoint != "" {
+		config = config.WithEndpoint(endpoint)
+	}
+	if s.config.DisableSSL {
+		config = config.WithDisableSSL(true)
+	}
+	if s.config.S3ForcePathStyle {
+		config = config.WithS3ForcePathStyle(true)
+	}
 	if s.config.Secret == "" {
-		return aws.NewConfig().WithRegion(s.config.Region), nil
+		return config, nil
+	}
+	if s.config.Key == "" {
+		return nil, fmt.Errorf("key was empty for supplied secret")
 	}
 	awsCredentials := credentials.NewStaticCredentials(s.config.Key, s.config.Secret, s.config.Token)
 	_, err := awsCredentials.Get()
 	if err != nil {
 		return nil, fmt.Errorf("bad credentials: %s", err)
 	}
-	return aws.NewConfig().WithRegion(s.config.Region).WithCredentials(awsCredentials), nil
+	return config.WithCredentials(awsCredentials), nil
 }
 
 func (s *service) List(URL string) ([]storage.Object, error) {
@@ -124,6 +163,48 @@ func (s *service) List(URL string) ([]storage.Object, error) {
 	return result, nil
 }
 
+//ListStream passes folders and objects to handler page by page with continuation tokens, handler returning false stops fetching further pages
+func (s *service) ListStream(URL string, handler func(object storage.Object) (bool, error)) error {
+	parsedURL, err := url.Parse(URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse : %v", err)
+	}
+	config, err := s.getAwsConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get aws config: %v", err)
+	}
+	client := s3.New(session.New(), config)
+	request := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(parsedURL.Host),
+		Delimiter: aws.String("/"),
+	}
+	if len(parsedURL.Path) > 0 {
+		request.Prefix = aws.String(parsedURL.Path[1:])
+	}
+	var toContinue = true
+	var handlerErr error
+	err = client.ListObjectsV2Pages(request, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, prefix := range page.CommonPrefixes {
+			if toContinue, handlerErr = handler(newFolderObject(parsedURL.Host, prefix)); handlerErr != nil || !toContinue {
+				return false
+			}
+		}
+		for _, content := range page.Contents {
+			if toContinue, handlerErr = handler(newContentObject(parsedURL.Host, content)); handlerErr != nil || !toContinue {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "BucketRegionError") {
+			return nil
+		}
+		return fmt.Errorf("failed to get list content: %v", err)
+	}
+	return handlerErr
+}
+
 func (s *service) Exists(URL string) (bool, error) {
 	objects, err := s.List(URL)
 	if err != nil {
@@ -143,6 +224,37 @@ func (s *service) StorageObject(URL string) (storage.Object, error) {
 	return objects[0], nil
 }
 
+//Stat returns object file info with HeadObject, folders are resolved with listing
+func (s *service) Stat(URL string) (os.FileInfo, error) {
+	parsedURL, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.getAwsConfig()
+	if err != nil {
+		return nil, err
+	}
+	client := s3.New(session.New(), config)
+	output, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(parsedURL.Host),
+		Key:    aws.String(strings.TrimPrefix(parsedURL.Path, "/")),
+	})
+	if err != nil || output.ContentLength == nil {
+		object, err := s.StorageObject(URL)
+		if err != nil {
+			return nil, err
+		}
+		return object.FileInfo(), nil
+	}
+	var modified = defaultTime
+	if output.LastModified != nil {
+		modified = *output.LastModified
+	}
+	var _, name = toolbox.URLSplit(URL)
+	var fileMode, _ = storage.NewFileMode("-rw-rw-rw-")
+	return storage.NewFileInfo(name, *output.ContentLength, fileMode, modified, false), nil
+}
+
 func (s *service) Download(object storage.Object) (io.ReadCloser, error) {
 	u, err := url.Parse(object.URL())
 	if err != nil {
@@ -168,12 +280,74 @@ func (s *service) Download(object storage.Object) (io.ReadCloser, error) {
 
 }
 
+//DownloadWithRange returns reader for object bytes starting at from offset up to, but excluding to offset, negative to reads till the end
+func (s *service) DownloadWithRange(object storage.Object, from, to int64) (io.ReadCloser, error) {
+	u, err := url.Parse(object.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse : %v", err)
+	}
+	config, err := s.getAwsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aws config: %v", err)
+	}
+	if to == from {
+		return ioutil.NopCloser(bytes.NewReader([]byte{})), nil
+	}
+	byteRange := fmt.Sprintf("bytes=%d-", from)
+	if to > 0 {
+		byteRange += fmt.Sprintf("%d", to-1)
+	}
+	target := &s3.Object{}
+	_ = object.Unwrap(&target)
+	client := s3.New(session.New(), config)
+	output, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(u.Host),
+		Key:    target.Key,
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download range %v: %v", byteRange, err)
+	}
+	return output.Body, nil
+}
+
+//Checksum returns md5 checksum stored as object ETag, empty string is returned for other algorithms and multipart or encrypted objects which ETag is not a content md5
+func (s *service) Checksum(object storage.Object, algo string) (string, error) {
+	if algo != storage.MD5 {
+		return "", nil
+	}
+	target := &s3.Object{}
+	_ = object.Unwrap(&target)
+	if target.ETag == nil {
+		return "", nil
+	}
+	return md5FromETag(*target.ETag), nil
+}
+
+//md5FromETag returns md5 checksum from ETag or empty string if ETag is not a content md5
+func md5FromETag(eTag string) string {
+	eTag = strings.ToLower(strings.Trim(eTag, "\""))
+	if len(eTag) != 32 || strings.Contains(eTag, "-") {
+		return ""
+	}
+	return eTag
+}
+
 func (s *service) Upload(URL string, reader io.Reader) error {
 	return s.UploadWithMode(URL, storage.DefaultFileMode, reader)
 }
 
 func (s *service) UploadWithMode(URL string, mode os.FileMode, reader io.Reader) error {
-	err := s.uploadContent(URL, reader)
+	return s.upload(URL, reader, nil)
+}
+
+//UploadWithMetadata uploads provided reader content with Content-Type, Cache-Control, Content-Encoding and x-amz-meta-* metadata
+func (s *service) UploadWithMetadata(URL string, reader io.Reader, meta map[string]string) error {
+	return s.upload(URL, reader, meta)
+}
+
+func (s *service) upload(URL string, reader io.Reader, meta map[string]string) error {
+	err := s.uploadContent(URL, reader, meta)
 	if toolbox.IsNotFoundError(err) {
 		config, err := s.getAwsConfig()
 		if err != nil {
@@ -187,12 +361,12 @@ func (s *service) UploadWithMode(URL string, mode os.FileMode, reader io.Reader)
 				return err
 			}
 		}
-		return s.uploadContent(URL, reader)
+		return s.uploadContent(URL, reader, meta)
 	}
 	return err
 }
 
-func (s *service) uploadContent(URL string, reader io.Reader) error {
+func (s *service) uploadContent(URL string, reader io.Reader, meta map[string]string) error {
 	parsedURL, err := url.Parse(URL)
 	if err != nil {
 		return err
@@ -202,17 +376,46 @@ func (s *service) uploadContent(URL string, reader io.Reader) error {
 		return err
 	}
 	uploader := s3manager.NewUploader(session.New(config))
-	_, err = uploader.Upload(&s3manager.UploadInput{
+	input := &s3manager.UploadInput{
 		Body:   reader,
 		Bucket: aws.String(parsedURL.Host),
 		Key:    aws.String(parsedURL.Path),
-	})
+	}
+	applyMetadata(input, meta)
+	_, err = uploader.Upload(input)
 	if err != nil {
 		return toolbox.ReclassifyNotFoundIfMatched(err, URL)
 	}
 	return nil
 }
 
+//applyMetadata maps supplied metadata into upload input, keys other than content headers are stored as user metadata
+func applyMetadata(input *s3manager.UploadInput, meta map[string]string) {
+	for key, value := range meta {
+		switch strings.ToLower(key) {
+		case "content-type":
+			input.ContentType = aws.String(value)
+		case "cache-control":
+			input.CacheControl = aws.String(value)
+		case "content-encoding":
+			input.ContentEncoding = aws.String(value)
+		case "content-disposition":
+			input.ContentDisposition = aws.String(value)
+		case "content-language":
+			input.ContentLanguage = aws.String(value)
+		default:
+			if input.Metadata == nil {
+				input.Metadata = make(map[string]*string)
+			}
+			name := key
+			if strings.HasPrefix(strings.ToLower(name), userMetadataPrefix) {
+				name = string(name[len(userMetadataPrefix):])
+			}
+			input.Metadata[name] = aws.String(value)
+		}
+	}
+}
+
 func (s *service) Delete(object storage.Object) error {
 	parsedURL, err := url.Parse(object.URL())
 	if err != nil {
@@ -248,6 +451,73 @@ func (s *service) Delete(object storage.Object) error {
 	return nil
 }
 
+//Move copies source object to destination URL with server side copy and deletes the source object
+func (s *service) Move(sourceURL, destinationURL string) error {
+	object, err := s.StorageObject(sourceURL)
+	if err != nil {
+		return err
+	}
+	if object.IsFolder() {
+		objects, err := s.List(sourceURL)
+		if err != nil {
+			return err
+		}
+		for _, candidate := range objects {
+			if strings.TrimRight(candidate.URL(), "/") == strings.TrimRight(sourceURL, "/") {
+				continue
+			}
+			_, name := toolbox.URLSplit(strings.TrimRight(candidate.URL(), "/"))
+			if err = s.Move(candidate.URL(), toolbox.URLPathJoin(destinationURL, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = s.copyObject(object, destinationURL); err != nil {
+		return err
+	}
+	return s.Delete(object)
+}
+
+//CopyObject copies source object to destination URL with s3 CopyObject, folders are not supported
+func (s *service) CopyObject(sourceURL, destinationURL string) error {
+	object, err := s.StorageObject(sourceURL)
+	if err != nil {
+		return err
+	}
+	if object.IsFolder() {
+		return storage.ErrServerSideCopyNotSupported
+	}
+	return s.copyObject(object, destinationURL)
+}
+
+func (s *service) copyObject(object storage.Object, destinationURL string) error {
+	sourceObject := &s3.Object{}
+	_ = object.Unwrap(&sourceObject)
+	parsedSourceURL, err := url.Parse(object.URL())
+	if err != nil {
+		return err
+	}
+	parsedDestinationURL, err := url.Parse(destinationURL)
+	if err != nil {
+		return err
+	}
+	config, err := s.getAwsConfig()
+	if err != nil {
+		return err
+	}
+	client := s3.New(session.New(), config)
+	copySource := (&url.URL{Path: parsedSourceURL.Host + "/" + strings.TrimLeft(*sourceObject.Key, "/")}).EscapedPath()
+	if _, err = client.CopyObject(&s3.CopyObjectInput{
+		Bucket:     aws.String(parsedDestinationURL.Host),
+		Key:        aws.String(parsedDestinationURL.Path),
+		CopySource: aws.String(copySource),
+	}); err != nil {
+		return toolbox.ReclassifyNotFoundIfMatched(err, object.URL())
+	}
+	return nil
+}
+
 func (s *service) Register(schema string, service storage.Service) error {
 	return fmt.Errorf("Unsupported")
 }
@@ -1,6 +1,12 @@
 package s3_test
 
 import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/cred"
+	"github.com/viant/toolbox/storage/s3"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +36,40 @@ func TestService_List(t *testing.T) {
 
 		assert.Nil(t, service.Delete(obj))*/
 }
+
+//TestService_Endpoint runs against s3 compatible endpoint (i.e. MinIO) set with AWS_S3_TEST_ENDPOINT and AWS_S3_TEST_BUCKET
+func TestService_Endpoint(t *testing.T) {
+	endpoint := os.Getenv("AWS_S3_TEST_ENDPOINT")
+	bucket := os.Getenv("AWS_S3_TEST_BUCKET")
+	if endpoint == "" || bucket == "" {
+		t.Skip("AWS_S3_TEST_ENDPOINT and AWS_S3_TEST_BUCKET were not set")
+	}
+	service := s3.NewService(&cred.Config{
+		Region:           "us-east-1",
+		Key:              os.Getenv("AWS_ACCESS_KEY_ID"),
+		Secret:           os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		Endpoint:         endpoint,
+		DisableSSL:       strings.HasPrefix(endpoint, "http://"),
+		S3ForcePathStyle: true,
+	})
+	URL := "s3://" + bucket + "/toolbox/endpoint/test.txt"
+	err := service.Upload(URL, strings.NewReader("test content"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	objects, err := service.List("s3://" + bucket + "/toolbox/endpoint/")
+	assert.Nil(t, err)
+	assert.True(t, len(objects) > 0)
+
+	object, err := service.StorageObject(URL)
+	if !assert.Nil(t, err) {
+		return
+	}
+	reader, err := service.Download(object)
+	if assert.Nil(t, err) {
+		content, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, "test content", string(content))
+	}
+	assert.Nil(t, service.Delete(object))
+}
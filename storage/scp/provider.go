@@ -4,6 +4,7 @@ import (
 	"github.com/viant/toolbox/cred"
 	"github.com/viant/toolbox/secret"
 	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
 	"strings"
 )
 
@@ -14,8 +15,8 @@ const ProviderScheme = "scp"
 const SSHProviderScheme = "ssh"
 
 func init() {
-	storage.Registry().Registry[ProviderScheme] = serviceProvider
-	storage.Registry().Registry[SSHProviderScheme] = serviceProvider
+	storage.RegisterProvider(ProviderScheme, serviceProvider)
+	storage.RegisterProvider(SSHProviderScheme, serviceProvider)
 }
 
 func serviceProvider(credentials string) (storage.Service, error) {
@@ -23,10 +24,24 @@ func serviceProvider(credentials string) (storage.Service, error) {
 	if strings.TrimSpace(credentials) != "" {
 		var err error
 		secrets := secret.New("", false)
-		config, err = secrets.GetCredentials(credentials)
+		if !secret.Secret(credentials).IsLocation() {
+			if config, err = secrets.GetCredentials(credentials); err != nil {
+				return nil, err
+			}
+			return NewService(config), nil
+		}
+		location, err := secrets.CredentialsLocation(credentials)
+		if err != nil {
+			return nil, err
+		}
+		resource := &url.Resource{Credentials: location}
+		credential, err := resource.LoadCredentialConfig(true)
 		if err != nil {
 			return nil, err
 		}
+		if config, err = credential.Config(); err != nil {
+			return nil, err
+		}
 	}
 	return NewService(config), nil
 }
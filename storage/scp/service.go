@@ -101,18 +101,17 @@ func (s *service) List(URL string) ([]storage.Object, error) {
 	var parser = &Parser{IsoTimeStyle: canListWithTimeStyle}
 	var URLPath = parsedURL.Path
 	var result = make([]storage.Object, 0)
-	var lsCommand = ""
-
+	var lsOptions = "ls -dltrT"
 	if canListWithTimeStyle {
-		lsCommand += "ls -dltr --time-style=full-iso " + URLPath
-	} else {
-		lsCommand += "ls -dltrT " + URLPath
+		lsOptions = "ls -dltr --time-style=full-iso"
 	}
+	var lsCommand = lsOptions + " " + URLPath
 	output, _ := s.runCommand(commandSession, URL, lsCommand)
 	var stdout = vtclean.Clean(string(output), false)
 	if strings.Contains(stdout, "unrecognized option") {
 		if canListWithTimeStyle {
-			lsCommand = "ls -dltr --full-time " + URLPath
+			lsOptions = "ls -dltr --full-time"
+			lsCommand = lsOptions + " " + URLPath
 			output, _ = s.runCommand(commandSession, URL, lsCommand)
 			stdout = vtclean.Clean(string(output), false)
 		}
@@ -130,7 +129,7 @@ func (s *service) List(URL string) ([]storage.Object, error) {
 		return nil, err
 	}
 	if len(objects) == 1 && objects[0].FileInfo().IsDir() {
-		output, _ = s.runCommand(commandSession, URL, lsCommand+" "+path.Join(URLPath, "*"))
+		output, _ = s.runCommand(commandSession, URL, lsOptions+" "+path.Join(URLPath, "*"))
 		stdout = vtclean.Clean(string(output), false)
 		directoryObjects, err := parser.Parse(parsedURL, stdout, true)
 		if err != nil {
@@ -282,27 +281,18 @@ func (s *service) Delete(object storage.Object) error {
 		return s.fileService.Delete(storageObject)
 	}
 
-	port := toolbox.AsInt(parsedURL.Port())
-	if port == 0 {
-		port = defaultSSHPort
+	if parsedURL.Path == "/" {
+		return fmt.Errorf("invalid removal path: %v", parsedURL.Path)
 	}
-	service, err := ssh.NewService(parsedURL.Hostname(), toolbox.AsInt(port), s.config)
-	if err != nil {
+	if _, err = s.getService(parsedURL); err != nil {
 		return err
 	}
-
-	//defer service.Close()
-	session, err := service.NewSession()
-	if err != nil {
-		return err
-	}
-	defer session.Close()
-
-	if parsedURL.Path == "/" {
-		return fmt.Errorf("invalid removal path: %v", parsedURL.Path)
+	commandSession := s.getMultiSession(parsedURL)
+	output, _ := s.runCommand(commandSession, object.URL(), "rm -rf "+parsedURL.Path)
+	if strings.Contains(output, "Permission denied") {
+		return fmt.Errorf("failed to remove %v, %v", object.URL(), output)
 	}
-	_, err = session.Output("rm -rf " + parsedURL.Path)
-	return err
+	return nil
 }
 
 //DownloadWithURL downloads content for passed in object URL
@@ -0,0 +1,93 @@
+package scp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/ssh"
+	"github.com/viant/toolbox/storage"
+	"os"
+	"path"
+	"testing"
+)
+
+const replayListOptions = "ls -dltr --time-style=full-iso "
+
+// newReplayTestService returns a service using replay ssh service for supplied host
+func newReplayTestService(t *testing.T, host string, commands map[string]string, content map[string][]byte) *service {
+	replayCommands, err := ssh.NewReplayCommands(path.Join(os.TempDir(), "scp_replay"))
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	for stdin, stdout := range commands {
+		for i := 0; i < 10; i++ { //each replay stdout is returned once
+			replayCommands.Register(stdin+"\n", stdout)
+		}
+	}
+	result := NewService(nil)
+	replayService := ssh.NewReplayService("$", "linux", replayCommands, content)
+	result.services[host] = replayService
+	result.multiSessions[host], _ = replayService.OpenMultiCommandSession(nil)
+	return result
+}
+
+func TestService_Replay(t *testing.T) {
+	const modified = " 2017-11-04 22:29:33.363458941 +0000 "
+	service := newReplayTestService(t, "remote:22", map[string]string{
+		replayListOptions + "/data":          "drwxr-xr-x 3 user user 4096" + modified + "/data",
+		replayListOptions + "/data/*":        "-rw-r--r-- 1 user user 12" + modified + "/data/file.txt\ndrwxr-xr-x 2 user user 4096" + modified + "/data/sub",
+		replayListOptions + "/data/sub":      "drwxr-xr-x 2 user user 4096" + modified + "/data/sub",
+		replayListOptions + "/data/sub/*":    "-rw-r--r-- 1 user user 3" + modified + "/data/sub/nested.txt",
+		replayListOptions + "/data/file.txt": "-rw-r--r-- 1 user user 12" + modified + "/data/file.txt",
+		replayListOptions + "/data/missing":  "ls: cannot access '/data/missing': No such file or directory",
+		"ls -dltr /data/file.txt":            "-rw-r--r-- 1 user user 12 Nov  4 22:29 /data/file.txt",
+		"ls -dltr /data/missing":             "ls: cannot access '/data/missing': No such file or directory",
+		"rm -rf /data/file.txt":              "",
+	}, map[string][]byte{
+		"/data/file.txt":       []byte("test content"),
+		"/data/sub/nested.txt": []byte("abc"),
+	})
+
+	{ //list folder
+		objects, err := service.List("scp://remote:22/data")
+		if assert.Nil(t, err) && assert.Equal(t, 3, len(objects)) {
+			assert.True(t, objects[0].IsFolder())
+			assert.Equal(t, "scp://remote:22/data/file.txt", objects[1].URL())
+			assert.True(t, objects[1].IsContent())
+			assert.Equal(t, int64(12), objects[1].FileInfo().Size())
+			assert.Equal(t, "scp://remote:22/data/sub", objects[2].URL())
+			assert.True(t, objects[2].IsFolder())
+		}
+		_, err = service.List("scp://remote:22/data/missing")
+		assert.Equal(t, NoSuchFileOrDirectoryError, err)
+	}
+	{ //exists
+		exists, err := service.Exists("scp://remote:22/data/file.txt")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		exists, err = service.Exists("scp://remote:22/data/missing")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	}
+	{ //recursive copy
+		memService := storage.NewPrivateMemoryService()
+		err := storage.Copy(service, "scp://remote:22/data", memService, "mem:///scp", nil, nil)
+		assert.Nil(t, err)
+		text, err := storage.DownloadText(memService, "mem:///scp/file.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "test content", text)
+		text, err = storage.DownloadText(memService, "mem:///scp/sub/nested.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", text)
+
+		err = storage.Copy(memService, "mem:///scp/sub", service, "scp://remote:22/upload", nil, nil)
+		assert.Nil(t, err)
+		content, err := service.services["remote:22"].Download("/upload/nested.txt")
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", string(content))
+	}
+	{ //delete
+		object, err := service.StorageObject("scp://remote:22/data/file.txt")
+		if assert.Nil(t, err) {
+			assert.Nil(t, service.Delete(object))
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package storage
+
+import "errors"
+
+//ErrServerSideCopyNotSupported is returned by ServerSideCopier when object can not be copied without streaming content
+var ErrServerSideCopyNotSupported = errors.New("server side copy not supported")
+
+//ServerSideCopier represents an optional service extension that copies content objects without streaming them through the client
+type ServerSideCopier interface {
+	//CopyObject copies source URL content object to destination URL, ErrServerSideCopyNotSupported is returned if object can not be copied server side
+	CopyObject(sourceURL, destinationURL string) error
+}
+
+//serverSideCopier returns ServerSideCopier if both source and destination URL are handled by the same service instance
+func serverSideCopier(sourceService Service, sourceURL string, destinationService Service, destinationURL string) (ServerSideCopier, bool) {
+	source := resolveService(sourceService, sourceURL)
+	if source != resolveService(destinationService, destinationURL) {
+		return nil, false
+	}
+	copier, ok := source.(ServerSideCopier)
+	return copier, ok
+}
@@ -0,0 +1,130 @@
+package storage_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+//serverSideService represents a service counting server side copies and downloads
+type serverSideService struct {
+	storage.Service
+	unsupported bool
+	copies      int32
+	downloads   int32
+}
+
+func (s *serverSideService) CopyObject(sourceURL, destinationURL string) error {
+	if s.unsupported {
+		return storage.ErrServerSideCopyNotSupported
+	}
+	atomic.AddInt32(&s.copies, 1)
+	return s.Service.(storage.ServerSideCopier).CopyObject(sourceURL, destinationURL)
+}
+
+func (s *serverSideService) Download(object storage.Object) (io.ReadCloser, error) {
+	atomic.AddInt32(&s.downloads, 1)
+	return s.Service.Download(object)
+}
+
+func TestCopy_ServerSide(t *testing.T) {
+	var useCases = []struct {
+		description     string
+		unsupported     bool
+		modifier        storage.ModificationHandler
+		sharedService   bool
+		expectCopies    int32
+		expectDownloads int32
+	}{
+		{
+			description:   "fast path",
+			sharedService: true,
+			expectCopies:  20,
+		},
+		{
+			description:     "modification handler forces streaming",
+			sharedService:   true,
+			modifier:        func(reader io.ReadCloser) (io.ReadCloser, error) { return reader, nil },
+			expectDownloads: 20,
+		},
+		{
+			description:     "unsupported falls back to streaming",
+			sharedService:   true,
+			unsupported:     true,
+			expectDownloads: 20,
+		},
+		{
+			description:     "different services stream",
+			expectDownloads: 20,
+		},
+	}
+	for _, useCase := range useCases {
+		memService := storage.NewPrivateMemoryService()
+		files := uploadTestTree(memService, "mem:///source", 20)
+		source := &serverSideService{Service: memService, unsupported: useCase.unsupported}
+		var destination storage.Service = source
+		if !useCase.sharedService {
+			destination = &serverSideService{Service: memService}
+		}
+		err := storage.Copy(source, "mem:///source", destination, "mem:///target", useCase.modifier, nil)
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.expectCopies, source.copies, useCase.description)
+		assert.EqualValues(t, useCase.expectDownloads, source.downloads, useCase.description)
+		for relativePath, expected := range files {
+			reader, err := storage.Download(memService, toolbox.URLPathJoin("mem:///target", relativePath))
+			if !assert.Nil(t, err, useCase.description) {
+				continue
+			}
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.Equal(t, expected, string(content), useCase.description)
+		}
+	}
+}
+
+func TestCopy_ServerSideFileStorage(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_server_side_copy")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	sourceFile := path.Join(parent, "source", "dir", "script.sh")
+	_ = toolbox.CreateDirIfNotExist(path.Dir(sourceFile))
+	assert.Nil(t, ioutil.WriteFile(sourceFile, []byte("echo test"), 0750))
+	service := storage.NewService()
+	err := storage.Copy(service, toolbox.FileSchema+path.Join(parent, "source"), service, toolbox.FileSchema+path.Join(parent, "target"), nil, nil)
+	assert.Nil(t, err)
+	content, err := ioutil.ReadFile(path.Join(parent, "target", "dir", "script.sh"))
+	if assert.Nil(t, err) {
+		assert.Equal(t, "echo test", string(content))
+	}
+}
+
+func BenchmarkCopy_ServerSide(b *testing.B) {
+	memService := storage.NewPrivateMemoryService()
+	_ = memService.Upload("mem:///source/file.bin", strings.NewReader(strings.Repeat("x", 1024*1024)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := storage.Copy(memService, "mem:///source", memService, fmt.Sprintf("mem:///target%v", i%10), nil, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopy_Streaming(b *testing.B) {
+	memService := storage.NewPrivateMemoryService()
+	_ = memService.Upload("mem:///source/file.bin", strings.NewReader(strings.Repeat("x", 1024*1024)))
+	modifier := func(reader io.ReadCloser) (io.ReadCloser, error) { return reader, nil }
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := storage.Copy(memService, "mem:///source", memService, fmt.Sprintf("mem:///target%v", i%10), modifier, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
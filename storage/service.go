@@ -11,6 +11,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var DefaultFileMode os.FileMode = 0755
@@ -39,6 +40,20 @@ type Service interface {
 	Close() error
 }
 
+//Wrapper represents a service decorating another service, optional service extensions are resolved on unwrapped service
+type Wrapper interface {
+	//Unwrap returns decorated service
+	Unwrap() Service
+}
+
+//AttributeSetter represents an optional service extension that sets storage object attributes
+type AttributeSetter interface {
+	//SetMode sets file mode for supplied URL
+	SetMode(URL string, mode os.FileMode) error
+	//SetModTime sets modification time for supplied URL
+	SetModTime(URL string, modTime time.Time) error
+}
+
 type storageService struct {
 	registry map[string]Service
 }
@@ -119,6 +134,24 @@ func (s *storageService) UploadWithMode(URL string, mode os.FileMode, reader io.
 	return service.UploadWithMode(URL, mode, reader)
 }
 
+//UploadWithMetadata uploads content with metadata if underlying service supports it
+func (s *storageService) UploadWithMetadata(URL string, reader io.Reader, meta map[string]string) error {
+	service, err := s.getServiceForSchema(URL)
+	if err != nil {
+		return err
+	}
+	return UploadWithMetadata(service, URL, reader, meta)
+}
+
+//ListWithPattern returns objects matching supplied pattern with underlying service PatternLister if available
+func (s *storageService) ListWithPattern(baseURL string, pattern string) ([]Object, error) {
+	service, err := s.getServiceForSchema(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return ListWithPattern(service, baseURL, pattern)
+}
+
 //Delete remove storage object
 func (s *storageService) Delete(object Object) error {
 	service, err := s.getServiceForSchema(object.URL())
@@ -128,6 +161,47 @@ func (s *storageService) Delete(object Object) error {
 	return service.Delete(object)
 }
 
+//SetMode sets file mode for supplied URL if underlying service supports it
+func (s *storageService) SetMode(URL string, mode os.FileMode) error {
+	service, err := s.getServiceForSchema(URL)
+	if err != nil {
+		return err
+	}
+	if setter, ok := resolveService(service, URL).(AttributeSetter); ok {
+		return setter.SetMode(URL, mode)
+	}
+	return nil
+}
+
+//SetModTime sets modification time for supplied URL if underlying service supports it
+func (s *storageService) SetModTime(URL string, modTime time.Time) error {
+	service, err := s.getServiceForSchema(URL)
+	if err != nil {
+		return err
+	}
+	if setter, ok := resolveService(service, URL).(AttributeSetter); ok {
+		return setter.SetModTime(URL, modTime)
+	}
+	return nil
+}
+
+//Move moves source object to destination URL with underlying service Mover if both URLs share the same service
+func (s *storageService) Move(sourceURL, destinationURL string) error {
+	service, err := s.getServiceForSchema(sourceURL)
+	if err != nil {
+		return err
+	}
+	destinationService, err := s.getServiceForSchema(destinationURL)
+	if err != nil {
+		return err
+	}
+	service, destinationService = resolveService(service, sourceURL), resolveService(destinationService, destinationURL)
+	if mover, ok := service.(Mover); ok && service == destinationService {
+		return mover.Move(sourceURL, destinationURL)
+	}
+	return move(s, sourceURL, destinationURL)
+}
+
 //Close closes resources
 func (s *storageService) Close() error {
 	for _, service := range s.registry {
@@ -155,7 +229,7 @@ func NewService() Service {
 	return result
 }
 
-//NewServiceForURL creates a new storage service for provided URL scheme and optional credential file
+//NewServiceForURL creates a new storage service for provided URL scheme and optional credential file, it returns *UnsupportedSchemeError if scheme has no registered provider or *ProviderError if provider failed
 func NewServiceForURL(URL, credentials string) (Service, error) {
 	parsedURL, err := Parse(URL)
 	if err != nil {
@@ -173,14 +247,14 @@ func NewServiceForURL(URL, credentials string) (Service, error) {
 		}
 		serviceForScheme, err := provider(credentials)
 		if err != nil {
-			return nil, fmt.Errorf("failed lookup service for %v: %v", parsedURL.Scheme, err)
+			return nil, &ProviderError{Scheme: parsedURL.Scheme, Err: err}
 		}
 		err = service.Register(parsedURL.Scheme, serviceForScheme)
 		if err != nil {
 			return nil, err
 		}
 	} else if parsedURL.Scheme != "file" {
-		return nil, fmt.Errorf("unsupported scheme %v", URL)
+		return nil, &UnsupportedSchemeError{Scheme: parsedURL.Scheme, URL: URL, Schemes: Registry().Schemes()}
 	}
 	return service, nil
 }
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//Stater represents an optional service extension returning object file info with a HEAD-like call, without listing or downloading content
+type Stater interface {
+	//Stat returns file info for supplied URL
+	Stat(URL string) (os.FileInfo, error)
+}
+
+//Stat returns file info for supplied URL without downloading content, it uses service Stater if available, otherwise storage object file info
+func Stat(service Service, URL string) (os.FileInfo, error) {
+	if stater, ok := resolveService(service, URL).(Stater); ok {
+		return stater.Stat(URL)
+	}
+	object, err := service.StorageObject(URL)
+	if err != nil {
+		return nil, err
+	}
+	fileInfo := object.FileInfo()
+	if fileInfo == nil {
+		return nil, fmt.Errorf("file info not available for %v", URL)
+	}
+	return fileInfo, nil
+}
+
+//ExistenceErrors represents failed existence checks
+type ExistenceErrors struct {
+	Errors map[string]error //existence check error by URL
+}
+
+//Error returns all failures description
+func (e *ExistenceErrors) Error() string {
+	var URLs = make([]string, 0, len(e.Errors))
+	for URL := range e.Errors {
+		URLs = append(URLs, URL)
+	}
+	sort.Strings(URLs)
+	var failures = make([]string, 0, len(URLs))
+	for _, URL := range URLs {
+		failures = append(failures, fmt.Sprintf("%v: %v", URL, e.Errors[URL]))
+	}
+	return fmt.Sprintf("failed to check %v URL(s): %v", len(failures), strings.Join(failures, "; "))
+}
+
+//ObjectsExist checks supplied URLs existence with concurrency workers, URLs which check failed are excluded from result and reported with *ExistenceErrors
+func ObjectsExist(service Service, URLs []string, concurrency int) (map[string]bool, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var result = make(map[string]bool, len(URLs))
+	var failures = make(map[string]error)
+	var mutex = &sync.Mutex{}
+	var group = &sync.WaitGroup{}
+	var URLChannel = make(chan string, len(URLs))
+	for _, URL := range URLs {
+		URLChannel <- URL
+	}
+	close(URLChannel)
+	for i := 0; i < concurrency && i < len(URLs); i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for URL := range URLChannel {
+				exists, err := service.Exists(URL)
+				mutex.Lock()
+				if err != nil {
+					failures[URL] = err
+				} else {
+					result[URL] = exists
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	group.Wait()
+	if len(failures) > 0 {
+		return result, &ExistenceErrors{Errors: failures}
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//latencyService represents a service delaying existence checks, failing URLs with supplied suffix
+type latencyService struct {
+	storage.Service
+	latency    time.Duration
+	failSuffix string
+	downloads  int32
+}
+
+func (s *latencyService) Exists(URL string) (bool, error) {
+	time.Sleep(s.latency)
+	if s.failSuffix != "" && strings.HasSuffix(URL, s.failSuffix) {
+		return false, errors.New("injected failure")
+	}
+	return s.Service.Exists(URL)
+}
+
+func (s *latencyService) Download(object storage.Object) (io.ReadCloser, error) {
+	atomic.AddInt32(&s.downloads, 1)
+	return s.Service.Download(object)
+}
+
+func TestObjectsExist(t *testing.T) {
+	memService := storage.NewPrivateMemoryService()
+	var URLs = make([]string, 0)
+	for i := 0; i < 20; i++ {
+		URL := fmt.Sprintf("mem:///manifest/file%v.txt", i)
+		if i%2 == 0 {
+			_ = memService.Upload(URL, strings.NewReader("abc"))
+		}
+		URLs = append(URLs, URL)
+	}
+
+	{ //concurrent checks
+		service := &latencyService{Service: memService, latency: 20 * time.Millisecond}
+		started := time.Now()
+		result, err := storage.ObjectsExist(service, URLs, 10)
+		elapsed := time.Now().Sub(started)
+		assert.Nil(t, err)
+		assert.Equal(t, len(URLs), len(result))
+		for i, URL := range URLs {
+			assert.Equal(t, i%2 == 0, result[URL], URL)
+		}
+		assert.True(t, elapsed < 10*service.latency, fmt.Sprintf("elapsed %v", elapsed))
+	}
+	{ //partial failures
+		service := &latencyService{Service: memService, failSuffix: "3.txt"}
+		result, err := storage.ObjectsExist(service, URLs, 4)
+		if assert.NotNil(t, err) {
+			existenceErrors, ok := err.(*storage.ExistenceErrors)
+			if assert.True(t, ok) {
+				assert.Equal(t, 2, len(existenceErrors.Errors))
+				assert.NotNil(t, existenceErrors.Errors["mem:///manifest/file13.txt"])
+			}
+		}
+		assert.Equal(t, len(URLs)-2, len(result))
+		assert.True(t, result["mem:///manifest/file12.txt"])
+		_, ok := result["mem:///manifest/file3.txt"]
+		assert.False(t, ok)
+	}
+}
+
+func TestStat(t *testing.T) {
+	service := &latencyService{Service: storage.NewPrivateMemoryService()}
+	_ = service.Upload("mem:///stat/file.txt", strings.NewReader("hello"))
+	fileInfo, err := storage.Stat(service, "mem:///stat/file.txt")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "file.txt", fileInfo.Name())
+		assert.EqualValues(t, 5, fileInfo.Size())
+		assert.False(t, fileInfo.IsDir())
+	}
+	fileInfo, err = storage.Stat(service, "mem:///stat")
+	if assert.Nil(t, err) {
+		assert.True(t, fileInfo.IsDir())
+	}
+	_, err = storage.Stat(service, "mem:///stat/missing.txt")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 0, service.downloads)
+
+	fileInfo, err = storage.Stat(storage.NewService(), "file:///tmp")
+	if assert.Nil(t, err) {
+		assert.True(t, fileInfo.IsDir())
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+//SymlinkMode represents file storage symbolic link handling mode
+type SymlinkMode int
+
+const (
+	//SymlinkFollow follows symbolic links, object file info describes link target
+	SymlinkFollow SymlinkMode = iota
+	//SymlinkSkip skips symbolic links on copy, object file info describes the link itself
+	SymlinkSkip
+	//SymlinkPreserve recreates symbolic links on copy between file storages, object file info describes the link itself
+	SymlinkPreserve
+)
+
+//isSymlink returns true if supplied object represents a symbolic link
+func isSymlink(object Object) bool {
+	fileInfo := object.FileInfo()
+	return fileInfo != nil && fileInfo.Mode()&os.ModeSymlink != 0
+}
+
+//checkSymlinkCycle returns an error if link target directory is the listed directory or one of its ancestors
+func checkSymlinkCycle(dirPath, linkPath string) error {
+	target, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		return nil
+	}
+	for candidate := dirPath; ; candidate = path.Dir(candidate) {
+		if resolved, err := filepath.EvalSymlinks(candidate); err == nil && resolved == target {
+			return fmt.Errorf("symlink cycle detected: %v -> %v", linkPath, target)
+		}
+		if candidate == "/" || candidate == "." || candidate == "" {
+			return nil
+		}
+	}
+}
+
+//preserveSymlink recreates source symbolic link at destination URL
+func preserveSymlink(object Object, destinationURL string) error {
+	target, err := os.Readlink(toolbox.Filename(object.URL()))
+	if err != nil {
+		return err
+	}
+	destinationPath := toolbox.Filename(destinationURL)
+	if err = toolbox.CreateDirIfNotExist(path.Dir(destinationPath)); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(destinationPath); err == nil {
+		if err = os.Remove(destinationPath); err != nil {
+			return err
+		}
+	}
+	return os.Symlink(target, destinationPath)
+}
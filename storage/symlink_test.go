@@ -0,0 +1,134 @@
+package storage_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func setupSymlinkSource(t *testing.T, parent string, withCycle bool) string {
+	source := path.Join(parent, "source")
+	_ = toolbox.CreateDirIfNotExist(path.Join(source, "sub"))
+	assert.Nil(t, ioutil.WriteFile(path.Join(source, "file.txt"), []byte("abc"), 0644))
+	assert.Nil(t, os.Symlink("file.txt", path.Join(source, "rel.txt")))
+	assert.Nil(t, os.Symlink(path.Join(source, "file.txt"), path.Join(source, "abs.txt")))
+	if withCycle {
+		assert.Nil(t, os.Symlink("..", path.Join(source, "sub", "loop")))
+	}
+	return source
+}
+
+func TestCopyWithOptions_SymlinkMode(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_symlink")
+	service := storage.NewService()
+
+	var useCases = []struct {
+		description string
+		mode        storage.SymlinkMode
+		withCycle   bool
+		hasError    bool
+	}{
+		{
+			description: "follow links",
+			mode:        storage.SymlinkFollow,
+		},
+		{
+			description: "follow links with cycle",
+			mode:        storage.SymlinkFollow,
+			withCycle:   true,
+			hasError:    true,
+		},
+		{
+			description: "skip links",
+			mode:        storage.SymlinkSkip,
+			withCycle:   true,
+		},
+		{
+			description: "preserve links",
+			mode:        storage.SymlinkPreserve,
+			withCycle:   true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		_ = os.RemoveAll(parent)
+		source := setupSymlinkSource(t, parent, useCase.withCycle)
+		target := path.Join(parent, "target")
+		err := storage.CopyWithOptions(service, "file://"+source, service, "file://"+target, &storage.CopyOptions{
+			SymlinkMode: useCase.mode,
+		})
+		if useCase.hasError {
+			if assert.NotNil(t, err, useCase.description) {
+				assert.True(t, strings.Contains(err.Error(), "cycle"), useCase.description)
+			}
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		content, err := ioutil.ReadFile(path.Join(target, "file.txt"))
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, "abc", string(content), useCase.description)
+
+		for _, name := range []string{"rel.txt", "abs.txt", "sub/loop"} {
+			linkPath := path.Join(target, name)
+			info, err := os.Lstat(linkPath)
+			switch useCase.mode {
+			case storage.SymlinkFollow:
+				if name == "sub/loop" {
+					continue
+				}
+				if assert.Nil(t, err, useCase.description) {
+					assert.True(t, info.Mode().IsRegular(), useCase.description+" "+name)
+				}
+			case storage.SymlinkSkip:
+				assert.True(t, os.IsNotExist(err), useCase.description+" "+name)
+			case storage.SymlinkPreserve:
+				if assert.Nil(t, err, useCase.description) {
+					assert.True(t, info.Mode()&os.ModeSymlink != 0, useCase.description+" "+name)
+					expected, _ := os.Readlink(path.Join(source, name))
+					actual, _ := os.Readlink(linkPath)
+					assert.Equal(t, expected, actual, useCase.description+" "+name)
+				}
+			}
+		}
+	}
+	_ = os.RemoveAll(parent)
+}
+
+func TestNewFileStorageWithSymlinkMode(t *testing.T) {
+	parent := path.Join(os.TempDir(), "storage_symlink_list")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	source := setupSymlinkSource(t, parent, false)
+
+	{
+		service := storage.NewFileStorageWithSymlinkMode(storage.SymlinkSkip)
+		object, err := service.StorageObject("file://" + path.Join(source, "rel.txt"))
+		if assert.Nil(t, err) {
+			assert.True(t, object.FileInfo().Mode()&os.ModeSymlink != 0)
+		}
+		objects, err := service.List("file://" + source)
+		assert.Nil(t, err)
+		var links = 0
+		for _, object := range objects {
+			if object.FileInfo().Mode()&os.ModeSymlink != 0 {
+				links++
+			}
+		}
+		assert.Equal(t, 2, links)
+	}
+	{
+		service := storage.NewFileStorage()
+		object, err := service.StorageObject("file://" + path.Join(source, "abs.txt"))
+		if assert.Nil(t, err) {
+			assert.True(t, object.FileInfo().Mode().IsRegular())
+			assert.Equal(t, int64(3), object.FileInfo().Size())
+		}
+	}
+}
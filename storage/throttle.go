@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+//RateLimiter represents a bandwidth limiter, Wait blocks until n bytes can be transferred
+type RateLimiter interface {
+	Wait(n int)
+}
+
+//Clock represents time source used by rate limiter
+type Clock interface {
+	Now() time.Time
+	Sleep(duration time.Duration)
+}
+
+type systemClock struct{}
+
+func (c systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c systemClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+//tokenBucket represents token bucket rate limiter, a token represents a byte
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  Clock
+	mutex  *sync.Mutex
+}
+
+//Wait takes n tokens from the bucket, it sleeps while the bucket is in debt
+func (b *tokenBucket) Wait(n int) {
+	if n <= 0 {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := b.clock.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens < 0 {
+		//sleeping while holding the lock keeps the limit global for all waiting workers
+		b.clock.Sleep(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+}
+
+//NewRateLimiter returns a token bucket rate limiter allowing supplied bytes per second
+func NewRateLimiter(bytesPerSecond int64) RateLimiter {
+	return NewRateLimiterWithClock(bytesPerSecond, systemClock{})
+}
+
+//NewRateLimiterWithClock returns a token bucket rate limiter allowing supplied bytes per second measured with supplied clock
+func NewRateLimiterWithClock(bytesPerSecond int64, clock Clock) RateLimiter {
+	return &tokenBucket{
+		rate:  float64(bytesPerSecond),
+		burst: float64(bytesPerSecond),
+		last:  clock.Now(),
+		clock: clock,
+		mutex: &sync.Mutex{},
+	}
+}
+
+//throttledReader represents a reader taking read bytes from a rate limiter
+type throttledReader struct {
+	io.ReadCloser
+	limiter RateLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.limiter.Wait(n)
+	return n, err
+}
+
+//throttle wraps supplied reader with rate limiter if one is set
+func throttle(reader io.ReadCloser, limiter RateLimiter) io.ReadCloser {
+	if limiter == nil {
+		return reader
+	}
+	return &throttledReader{ReadCloser: reader, limiter: limiter}
+}
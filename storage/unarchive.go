@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"github.com/viant/toolbox"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+//OverwritePolicy represents destination overwrite policy
+type OverwritePolicy int
+
+const (
+	//OverwriteAlways replaces existing destination objects
+	OverwriteAlways OverwritePolicy = iota
+	//OverwriteSkip keeps existing destination objects
+	OverwriteSkip
+	//OverwriteFail fails if destination object already exists
+	OverwriteFail
+)
+
+type unarchiveOptions struct {
+	filter          func(name string) bool
+	stripComponents int
+	overwrite       OverwritePolicy
+}
+
+//UnarchiveOption represents unarchive option
+type UnarchiveOption func(options *unarchiveOptions)
+
+//WithEntryFilter returns an option extracting only archive entries (matched with original entry path) for which predicate returns true
+func WithEntryFilter(predicate func(name string) bool) UnarchiveOption {
+	return func(options *unarchiveOptions) {
+		options.filter = predicate
+	}
+}
+
+//WithStripComponents returns an option removing supplied number of leading path components from entry path, like tar --strip-components
+func WithStripComponents(count int) UnarchiveOption {
+	return func(options *unarchiveOptions) {
+		options.stripComponents = count
+	}
+}
+
+//WithOverwrite returns an option setting destination overwrite policy
+func WithOverwrite(policy OverwritePolicy) UnarchiveOption {
+	return func(options *unarchiveOptions) {
+		options.overwrite = policy
+	}
+}
+
+//Unarchive extracts zip, tar or tar.gz archive (detected by archive URL extension) into destination URL
+func Unarchive(sourceService Service, archiveURL string, destinationService Service, destinationURL string, options ...UnarchiveOption) error {
+	var unarchiveOptions = &unarchiveOptions{}
+	for _, option := range options {
+		option(unarchiveOptions)
+	}
+	var extractor = &extractor{
+		destinationService: destinationService,
+		destinationURL:     destinationURL,
+		options:            unarchiveOptions,
+	}
+	var archiveName = strings.ToLower(archiveURL)
+	var isZip = strings.HasSuffix(archiveName, ".zip")
+	var isTarGz = strings.HasSuffix(archiveName, ".tar.gz") || strings.HasSuffix(archiveName, ".tgz")
+	var isTar = strings.HasSuffix(archiveName, ".tar")
+	var err error
+	switch {
+	case isZip:
+		var readerAt io.ReaderAt
+		var size int64
+		var cleanup func()
+		if readerAt, size, cleanup, err = openArchiveReaderAt(sourceService, archiveURL); err != nil {
+			return err
+		}
+		defer cleanup()
+		err = extractor.unzip(readerAt, size)
+	case isTarGz, isTar:
+		var reader io.ReadCloser
+		if reader, err = sourceService.DownloadWithURL(archiveURL); err != nil {
+			return fmt.Errorf("unable download archive %v, %v", archiveURL, err)
+		}
+		defer reader.Close()
+		if isTar {
+			err = extractor.untar(reader)
+			break
+		}
+		var gzipReader *gzip.Reader
+		if gzipReader, err = gzip.NewReader(reader); err == nil {
+			defer gzipReader.Close()
+			err = extractor.untar(gzipReader)
+		}
+	default:
+		return fmt.Errorf("unsupported archive format: %v", archiveURL)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to unarchive %v -> %v: %v", archiveURL, destinationURL, err)
+	}
+	return err
+}
+
+type extractor struct {
+	destinationService Service
+	destinationURL     string
+	options            *unarchiveOptions
+}
+
+func (e *extractor) untar(reader io.Reader) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if err = e.extract(header.Name, header.FileInfo().Mode(), tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+//unzip extracts zip entries streaming each entry from reader at
+func (e *extractor) unzip(readerAt io.ReaderAt, size int64) error {
+	zipReader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		entryReader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = e.extract(file.Name, file.Mode(), entryReader)
+		_ = entryReader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//extract uploads archive entry to destination URL joined with entry path, leading slash is removed from entry path
+func (e *extractor) extract(name string, mode os.FileMode, reader io.Reader) error {
+	var entryPath = path.Clean(strings.Replace(name, "\\", "/", -1))
+	if entryPath == ".." || strings.HasPrefix(entryPath, "../") {
+		return fmt.Errorf("illegal archive entry path: %v", name)
+	}
+	entryPath = strings.TrimLeft(entryPath, "/")
+	if entryPath == "" || entryPath == "." {
+		return fmt.Errorf("illegal archive entry path: %v", name)
+	}
+	if e.options.filter != nil && !e.options.filter(name) {
+		return nil
+	}
+	if e.options.stripComponents > 0 {
+		var components = strings.Split(entryPath, "/")
+		if len(components) <= e.options.stripComponents {
+			return nil
+		}
+		entryPath = strings.Join(components[e.options.stripComponents:], "/")
+	}
+	var entryURL = toolbox.URLPathJoin(e.destinationURL, entryPath)
+	if e.options.overwrite != OverwriteAlways {
+		exists, err := e.destinationService.Exists(entryURL)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if e.options.overwrite == OverwriteFail {
+				return fmt.Errorf("destination already exists: %v", entryURL)
+			}
+			return nil
+		}
+	}
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+	if err := e.destinationService.UploadWithMode(entryURL, mode.Perm(), reader); err != nil {
+		return fmt.Errorf("unable upload, %v, %v", entryURL, err)
+	}
+	return nil
+}
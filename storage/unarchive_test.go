@@ -0,0 +1,129 @@
+package storage_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func newTarArchive(t *testing.T, entries map[string]string) *bytes.Buffer {
+	buffer := new(bytes.Buffer)
+	writer := tar.NewWriter(buffer)
+	for name, content := range entries {
+		assert.Nil(t, writer.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := writer.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+	return buffer
+}
+
+func TestUnarchive(t *testing.T) {
+	var files = map[string]string{
+		"file1.txt":            "abc",
+		"config/test.prop":     "123",
+		"config/sub/test.json": "{}",
+	}
+	service := storage.NewPrivateMemoryService()
+	for name, content := range files {
+		_ = service.Upload("mem:///source/"+name, strings.NewReader(content))
+	}
+
+	{ //zip round trip
+		buffer := new(bytes.Buffer)
+		archive := zip.NewWriter(buffer)
+		assert.Nil(t, storage.Archive(service, "mem:///source", archive))
+		assert.Nil(t, archive.Close())
+		assert.Nil(t, service.Upload("mem:///archives/source.zip", buffer))
+		err := storage.Unarchive(service, "mem:///archives/source.zip", service, "mem:///zip")
+		assert.Nil(t, err)
+		for name, expected := range files {
+			actual, err := storage.DownloadText(service, "mem:///zip/"+name)
+			assert.Nil(t, err, name)
+			assert.Equal(t, expected, actual, name)
+		}
+	}
+	{ //zip range read from file service
+		parent, err := ioutil.TempDir("", "unarchive")
+		if assert.Nil(t, err) {
+			defer os.RemoveAll(parent)
+			buffer := new(bytes.Buffer)
+			archive := zip.NewWriter(buffer)
+			assert.Nil(t, storage.Archive(service, "mem:///source", archive))
+			assert.Nil(t, archive.Close())
+			assert.Nil(t, ioutil.WriteFile(path.Join(parent, "source.zip"), buffer.Bytes(), 0644))
+			err = storage.Unarchive(storage.NewFileStorage(), "file://"+path.Join(parent, "source.zip"), service, "mem:///file_zip")
+			assert.Nil(t, err)
+			for name, expected := range files {
+				actual, err := storage.DownloadText(service, "mem:///file_zip/"+name)
+				assert.Nil(t, err, name)
+				assert.Equal(t, expected, actual, name)
+			}
+		}
+	}
+	{ //tar.gz round trip
+		buffer := new(bytes.Buffer)
+		assert.Nil(t, storage.TarArchive(service, "mem:///source", buffer, true))
+		assert.Nil(t, service.Upload("mem:///archives/source.tar.gz", buffer))
+		err := storage.Unarchive(service, "mem:///archives/source.tar.gz", service, "mem:///tgz")
+		assert.Nil(t, err)
+		for name, expected := range files {
+			actual, err := storage.DownloadText(service, "mem:///tgz/"+name)
+			assert.Nil(t, err, name)
+			assert.Equal(t, expected, actual, name)
+		}
+	}
+	{ //filter and strip components
+		assert.Nil(t, service.Upload("mem:///archives/release.tar", newTarArchive(t, map[string]string{
+			"release-1.0/bin/app":     "app",
+			"release-1.0/doc/app.txt": "doc",
+			"README":                  "readme",
+		})))
+		err := storage.Unarchive(service, "mem:///archives/release.tar", service, "mem:///release",
+			storage.WithStripComponents(1),
+			storage.WithEntryFilter(func(name string) bool {
+				return !strings.HasSuffix(name, ".txt")
+			}))
+		assert.Nil(t, err)
+		actual, err := storage.DownloadText(service, "mem:///release/bin/app")
+		assert.Nil(t, err)
+		assert.Equal(t, "app", actual)
+		for _, URL := range []string{"mem:///release/doc/app.txt", "mem:///release/README", "mem:///release/release-1.0"} {
+			exists, _ := service.Exists(URL)
+			assert.False(t, exists, URL)
+		}
+	}
+	{ //overwrite policy
+		assert.Nil(t, service.Upload("mem:///archives/update.tar", newTarArchive(t, map[string]string{"file1.txt": "new"})))
+		assert.Nil(t, service.Upload("mem:///existing/file1.txt", strings.NewReader("old")))
+
+		assert.Nil(t, storage.Unarchive(service, "mem:///archives/update.tar", service, "mem:///existing", storage.WithOverwrite(storage.OverwriteSkip)))
+		actual, _ := storage.DownloadText(service, "mem:///existing/file1.txt")
+		assert.Equal(t, "old", actual)
+
+		assert.NotNil(t, storage.Unarchive(service, "mem:///archives/update.tar", service, "mem:///existing", storage.WithOverwrite(storage.OverwriteFail)))
+
+		assert.Nil(t, storage.Unarchive(service, "mem:///archives/update.tar", service, "mem:///existing"))
+		actual, _ = storage.DownloadText(service, "mem:///existing/file1.txt")
+		assert.Equal(t, "new", actual)
+	}
+	{ //path traversal
+		assert.Nil(t, service.Upload("mem:///archives/evil.tar", newTarArchive(t, map[string]string{"../../etc/passwd": "root"})))
+		err := storage.Unarchive(service, "mem:///archives/evil.tar", service, "mem:///evil/target")
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "illegal archive entry path")
+		}
+		exists, _ := service.Exists("mem:///etc/passwd")
+		assert.False(t, exists)
+	}
+	{ //unsupported format
+		assert.NotNil(t, storage.Unarchive(service, "mem:///source/file1.txt", service, "mem:///target"))
+	}
+}
@@ -14,7 +14,7 @@ const (
 	defaultKey    = "default"
 )
 
-var columnMapping = []string{"column", "dateLayout", "dateFormat", "autoincrement", "primaryKey", "sequence", "valueMap", defaultKey, anonymousKey}
+var columnMapping = []string{"column", "dateLayout", "dateFormat", "timeLayout", "strftimeFormat", "autoincrement", "primaryKey", "sequence", "valueMap", defaultKey, anonymousKey}
 
 // ScanStructFunc scan supplied struct methods
 func ScanStructMethods(structOrItsType interface{}, depth int, handler func(method reflect.Method) error) error {
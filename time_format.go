@@ -1,8 +1,18 @@
 package toolbox
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // DateFormatKeyword constant 'dateFormat' key
@@ -11,98 +21,733 @@ var DateFormatKeyword = "dateFormat"
 // DateLayoutKeyword constant 'dateLayout' key
 var DateLayoutKeyword = "dateLayout"
 
+// TimeLayoutKeyword constant 'timeLayout' key, alias of DateLayoutKeyword
+var TimeLayoutKeyword = "timeLayout"
+
 // DateFormatToLayout converts java date format https://docs.oracle.com/javase/6/docs/api/java/text/SimpleDateFormat.html#rfc822timezone into go date layout
+// Each run of the same pattern letter is converted as a whole, quoted text is copied literally, hh is converted to 24 hour clock unless the format has am/pm marker
 func DateFormatToLayout(dateFormat string) string {
+	return DateFormatToLayoutWithOptions(dateFormat, nil)
+}
 
-	dateFormat = strings.Replace(dateFormat, "ddd", "_2", 1)
-	dateFormat = strings.Replace(dateFormat, "dd", "02", 1)
-	dateFormat = strings.Replace(dateFormat, "d", "2", 1)
+// DateFormatLayoutOptions represents java date format conversion options
+type DateFormatLayoutOptions struct {
+	VariableFraction bool //converts S runs to 9 runs, so that layout parses fractions of any width and formats them without trailing zeros
+}
 
-	dateFormat = strings.Replace(dateFormat, "HH", "15", 1) //0-23
+// DateFormatToLayoutWithOptions converts java date format into go date layout like DateFormatToLayout with supplied options
+func DateFormatToLayoutWithOptions(dateFormat string, options *DateFormatLayoutOptions) string {
+	tokens := tokenizeDateFormat(dateFormat)
+	var twelveHour = false
+	for _, token := range tokens {
+		if token.letter == 'a' {
+			twelveHour = true
+		}
+	}
+	var result = new(bytes.Buffer)
+	for _, token := range tokens {
+		if token.letter == 'S' && options != nil && options.VariableFraction {
+			result.WriteString(strings.Repeat("9", token.count))
+			continue
+		}
+		result.WriteString(token.layout(twelveHour))
+	}
+	return result.String()
+}
 
-	dateFormat = strings.Replace(dateFormat, "hh", "03", 1) //0-12
-	dateFormat = strings.Replace(dateFormat, "h", "3", 1)
+// ParseTimeLenientFraction parses supplied value with layout, fractional seconds in the value may have any width (including none) regardless of layout fraction width
+func ParseTimeLenientFraction(value, layout string) (time.Time, error) {
+	return time.Parse(variableFractionLayout(layout), value)
+}
 
-	dateFormat = strings.Replace(dateFormat, "mm", "04", 1)
-	dateFormat = strings.Replace(dateFormat, "m", "4", 1)
+// variableFractionLayout replaces fixed width ,000 or .000 fractional seconds layout fragments with variable width ones
+func variableFractionLayout(layout string) string {
+	var result = []byte(layout)
+	for i := 0; i+1 < len(result); i++ {
+		if (result[i] != '.' && result[i] != ',') || result[i+1] != '0' {
+			continue
+		}
+		j := i + 1
+		for j < len(result) && result[j] == '0' {
+			j++
+		}
+		if j < len(result) && result[j] >= '0' && result[j] <= '9' {
+			continue
+		}
+		for k := i + 1; k < j; k++ {
+			result[k] = '9'
+		}
+		i = j - 1
+	}
+	return string(result)
+}
 
-	dateFormat = strings.Replace(dateFormat, "ss", "05", 1)
-	dateFormat = strings.Replace(dateFormat, "s", "5", 1)
+// DateFormatToLayoutChecked converts java date format into go date layout like DateFormatToLayout,
+// but returns an error listing pattern letters without go layout equivalent, i.e. week (w, W), ISO day of week (u), quarter (Q) or era (G)
+func DateFormatToLayoutChecked(dateFormat string) (string, error) {
+	var unsupported = make([]string, 0)
+	for _, token := range tokenizeDateFormat(dateFormat) {
+		if token.letter != 0 && !token.hasLayout() {
+			if fragment := strings.Repeat(string(token.letter), token.count); !HasSliceAnyElements(unsupported, fragment) {
+				unsupported = append(unsupported, fragment)
+			}
+		}
+	}
+	if len(unsupported) > 0 {
+		return "", fmt.Errorf("unsupported date format tokens %v in %q", strings.Join(unsupported, ", "), dateFormat)
+	}
+	return DateFormatToLayout(dateFormat), nil
+}
 
-	dateFormat = strings.Replace(dateFormat, "yyyy", "2006", 1)
-	dateFormat = strings.Replace(dateFormat, "yy", "06", 1)
-	dateFormat = strings.Replace(dateFormat, "y", "06", 1)
+// FormatTime formats supplied time with java date format, pattern letters without go layout equivalent are computed:
+// D (day of year), w (ISO week), W (week of month), Y (ISO week year), u (ISO day of week), Q (quarter) and G (era)
+func FormatTime(t time.Time, dateFormat string) (string, error) {
+	tokens := tokenizeDateFormat(dateFormat)
+	var twelveHour = false
+	for _, token := range tokens {
+		if token.letter == 'a' {
+			twelveHour = true
+		}
+	}
+	var result = new(bytes.Buffer)
+	for _, token := range tokens {
+		if token.letter == 0 {
+			result.WriteString(token.literal)
+			continue
+		}
+		if value, ok := token.format(t); ok {
+			result.WriteString(value)
+			continue
+		}
+		if !token.hasLayout() {
+			return "", fmt.Errorf("unsupported date format token %v in %q", strings.Repeat(string(token.letter), token.count), dateFormat)
+		}
+		result.WriteString(t.Format(token.layout(twelveHour)))
+	}
+	return result.String(), nil
+}
 
-	dateFormat = strings.Replace(dateFormat, "SSS", "000", 1)
+// ZoneIDLayout represents IANA time zone id layout token, i.e. Europe/Warsaw, it is converted from java VV and supported only by ParseTimeInLocation
+const ZoneIDLayout = "VV"
 
-	dateFormat = strings.Replace(dateFormat, "a", "pm", 1)
-	dateFormat = strings.Replace(dateFormat, "aa", "PM", 1)
+// ParseTimeInLocation parses supplied value with layout in IANA location, i.e. Europe/Warsaw, empty location name means UTC,
+// zone id matched by ZoneIDLayout in the layout takes precedence over location name, local times ambiguous or skipped by DST transition follow time.ParseInLocation
+func ParseTimeInLocation(value, layout, locationName string) (time.Time, error) {
+	if index := strings.Index(layout, ZoneIDLayout); index != -1 {
+		return parseTimeWithZoneID(value, layout[:index], layout[index+len(ZoneIDLayout):])
+	}
+	location, err := time.LoadLocation(locationName)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to load location %v, %v", locationName, err)
+	}
+	return time.ParseInLocation(layout, value, location)
+}
 
-	dateFormat = strings.Replace(dateFormat, "MMMM", "January", 1)
-	dateFormat = strings.Replace(dateFormat, "MMM", "Jan", 1)
-	dateFormat = strings.Replace(dateFormat, "MM", "01", 1)
-	dateFormat = strings.Replace(dateFormat, "M", "1", 1)
+// parseTimeWithZoneID finds zone id in supplied value and parses the remainder with layout without zone id token in the zone id location
+func parseTimeWithZoneID(value, layoutPrefix, layoutSuffix string) (time.Time, error) {
+	var err = fmt.Errorf("zone id not found in %v", value)
+	for i := 0; i < len(value); i++ {
+		if !isZoneIDStart(value, i) {
+			continue
+		}
+		end := i
+		for end < len(value) && isZoneIDChar(value[end]) {
+			end++
+		}
+		location, loadErr := time.LoadLocation(value[i:end])
+		if loadErr != nil || value[i:end] == "" {
+			continue
+		}
+		var timeValue time.Time
+		if timeValue, err = time.ParseInLocation(layoutPrefix+layoutSuffix, value[:i]+value[end:], location); err == nil {
+			return timeValue, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func isZoneIDStart(value string, position int) bool {
+	r := value[position]
+	if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+		return false
+	}
+	return position == 0 || !isZoneIDChar(value[position-1])
+}
 
-	dateFormat = strings.Replace(dateFormat, "ZZ", "-0700", 1)
+func isZoneIDChar(r byte) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '/' || r == '_' || r == '-' || r == '+'
+}
 
-	dateFormat = strings.Replace(dateFormat, "Z", "-07", 1)
+// layoutTokens represents go date layout tokens with java date format equivalents, tokens without equivalent have empty format, longer tokens come first
+var layoutTokens = []struct {
+	layout string
+	format string
+}{
+	{"January", "MMMM"},
+	{"Jan", "MMM"},
+	{"Monday", "EEEE"},
+	{"Mon", "EEE"},
+	{"MST", "z"},
+	{ZoneIDLayout, "VV"},
+	{"2006", "yyyy"},
+	{"002", "DDD"},
+	{"__2", "DDD"},
+	{"_2006", "'_'yyyy"},
+	{"_2", "ddd"},
+	{"01", "MM"},
+	{"02", "dd"},
+	{"03", "hh"},
+	{"04", "mm"},
+	{"05", "ss"},
+	{"06", "yy"},
+	{"15", "HH"},
+	{"1", "M"},
+	{"2", "d"},
+	{"3", "h"},
+	{"4", "m"},
+	{"5", "s"},
+	{"PM", "a"},
+	{"pm", "a"},
+	{"Z070000", ""},
+	{"Z07:00:00", ""},
+	{"Z0700", "XX"},
+	{"Z07:00", "XXX"},
+	{"Z07", "X"},
+	{"-070000", ""},
+	{"-07:00:00", ""},
+	{"-0700", "ZZ"},
+	{"-07:00", "ZZZ"},
+	{"-07", "Z"},
+}
+
+// LayoutToDateFormat converts go date layout into java date format, it is inverse of DateFormatToLayout,
+// literal letters and layout fragments without java equivalent are quoted
+func LayoutToDateFormat(layout string) string {
+	var result = new(bytes.Buffer)
+	var quoted = new(bytes.Buffer)
+	var flushQuoted = func() {
+		if quoted.Len() > 0 {
+			result.WriteString("'" + quoted.String() + "'")
+			quoted.Reset()
+		}
+	}
+	for i := 0; i < len(layout); {
+		if fraction := layoutFractionLength(layout, i); fraction > 0 {
+			flushQuoted()
+			result.WriteString(layout[i:i+1] + strings.Repeat("S", fraction))
+			i += fraction + 1
+			continue
+		}
+		matched := false
+		for _, token := range layoutTokens {
+			if !strings.HasPrefix(layout[i:], token.layout) {
+				continue
+			}
+			if token.format == "" {
+				quoted.WriteString(token.layout)
+			} else {
+				flushQuoted()
+				result.WriteString(token.format)
+			}
+			i += len(token.layout)
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(layout[i:])
+		switch {
+		case r == '\'' && quoted.Len() > 0:
+			quoted.WriteString("''")
+		case r == '\'':
+			result.WriteString("''")
+		case isDateFormatLetter(r):
+			quoted.WriteRune(r)
+		default:
+			flushQuoted()
+			result.WriteRune(r)
+		}
+		i += size
+	}
+	flushQuoted()
+	return result.String()
+}
 
-	dateFormat = strings.Replace(dateFormat, "zz:zz", "Z07:00", 1)
-	dateFormat = strings.Replace(dateFormat, "zzzz", "Z0700", 1)
-	dateFormat = strings.Replace(dateFormat, "z", "MST", 1)
+// layoutFractionLength returns fractional seconds digits count if layout has fractional seconds at supplied position, otherwise zero
+func layoutFractionLength(layout string, position int) int {
+	if position+1 >= len(layout) || (layout[position] != '.' && layout[position] != ',') {
+		return 0
+	}
+	digit := layout[position+1]
+	if digit != '0' && digit != '9' {
+		return 0
+	}
+	end := position + 1
+	for end < len(layout) && layout[end] == digit {
+		end++
+	}
+	if end < len(layout) && layout[end] >= '0' && layout[end] <= '9' {
+		return 0
+	}
+	return end - position - 1
+}
 
-	dateFormat = strings.Replace(dateFormat, "EEEE", "Monday", 1)
-	dateFormat = strings.Replace(dateFormat, "E", "Mon", 1)
-	return dateFormat
+// dateFormatToken represents either a run of the same java date format pattern letter or a literal text
+type dateFormatToken struct {
+	letter  rune
+	count   int
+	literal string
 }
 
-// GetTimeLayout returns time laout from passed in map, first it check if DateLayoutKeyword is defined is so it returns it, otherwise it check DateFormatKeyword and if exists converts it to  dateLayout
-// If neithers keys exists it panics, please use HasTimeLayout to avoid panic
-func GetTimeLayout(input interface{}) string {
-	switch settings := input.(type) {
-	case map[string]string:
-		if value, found := settings[DateLayoutKeyword]; found {
-			return value
+// layout returns go date layout fragment for the token, unsupported pattern letters are returned unchanged
+func (t *dateFormatToken) layout(twelveHour bool) string {
+	switch t.letter {
+	case 0:
+		return t.literal
+	case 'y':
+		if t.count == 2 {
+			return "06"
 		}
+		return "2006"
+	case 'M':
+		switch t.count {
+		case 1:
+			return "1"
+		case 2:
+			return "01"
+		case 3:
+			return "Jan"
+		}
+		return "January"
+	case 'd':
+		switch t.count {
+		case 1:
+			return "2"
+		case 2:
+			return "02"
+		}
+		return "_2"
+	case 'D':
+		return "002"
+	case 'E':
+		if t.count < 4 {
+			return "Mon"
+		}
+		return "Monday"
+	case 'H':
+		return "15"
+	case 'h':
+		if !twelveHour {
+			return "15"
+		}
+		if t.count == 1 {
+			return "3"
+		}
+		return "03"
+	case 'm':
+		if t.count == 1 {
+			return "4"
+		}
+		return "04"
+	case 's':
+		if t.count == 1 {
+			return "5"
+		}
+		return "05"
+	case 'S':
+		return strings.Repeat("0", t.count)
+	case 'a':
+		return "PM"
+	case 'Z':
+		switch t.count {
+		case 1:
+			return "-07"
+		case 2:
+			return "-0700"
+		}
+		return "-07:00"
+	case 'z':
+		if t.count < 4 {
+			return "MST"
+		}
+		return "Z0700"
+	case 'V':
+		return ZoneIDLayout
+	case 'X':
+		switch t.count {
+		case 1:
+			return "Z07"
+		case 2:
+			return "Z0700"
+		}
+		return "Z07:00"
+	}
+	return strings.Repeat(string(t.letter), t.count)
+}
 
-		if value, found := settings[DateFormatKeyword]; found {
-			return DateFormatToLayout(value)
+// hasLayout returns true if the token pattern letter has go date layout equivalent
+func (t *dateFormatToken) hasLayout() bool {
+	return strings.ContainsRune("yMdDEHhmsSaZzVX", t.letter)
+}
+
+// format returns time value for pattern letters without go layout equivalent or formatted differently than go layout, false otherwise
+func (t *dateFormatToken) format(value time.Time) (string, bool) {
+	switch t.letter {
+	case 'D':
+		return fmt.Sprintf("%0*d", t.count, value.YearDay()), true
+	case 'w':
+		_, week := value.ISOWeek()
+		return fmt.Sprintf("%0*d", t.count, week), true
+	case 'W':
+		firstWeekday := (int(value.AddDate(0, 0, 1-value.Day()).Weekday()) + 6) % 7
+		return fmt.Sprintf("%0*d", t.count, (value.Day()-1+firstWeekday)/7+1), true
+	case 'Y':
+		year, _ := value.ISOWeek()
+		if t.count == 2 {
+			return fmt.Sprintf("%02d", year%100), true
+		}
+		return fmt.Sprintf("%0*d", t.count, year), true
+	case 'u':
+		return strconv.Itoa((int(value.Weekday())+6)%7 + 1), true
+	case 'Q':
+		quarter := (int(value.Month())-1)/3 + 1
+		switch t.count {
+		case 1, 2:
+			return fmt.Sprintf("%0*d", t.count, quarter), true
+		case 3:
+			return fmt.Sprintf("Q%d", quarter), true
 		}
+		return fmt.Sprintf("%v quarter", []string{"1st", "2nd", "3rd", "4th"}[quarter-1]), true
+	case 'G':
+		if value.Year() <= 0 {
+			return "BC", true
+		}
+		return "AD", true
+	case 'V':
+		return value.Location().String(), true
+	}
+	return "", false
+}
 
-	case map[string]interface{}:
-		if value, found := settings[DateLayoutKeyword]; found {
-			return AsString(value)
+// isDateFormatLetter returns true if supplied rune is reserved java date format pattern letter
+func isDateFormatLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// tokenizeDateFormat splits java date format into pattern letter runs and literals, text in single quotes is literal and two single quotes represent a quote
+func tokenizeDateFormat(dateFormat string) []*dateFormatToken {
+	var result = make([]*dateFormatToken, 0)
+	var runes = []rune(dateFormat)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			literal := new(bytes.Buffer)
+			j := i + 1
+			if j < len(runes) && runes[j] == '\'' {
+				literal.WriteRune('\'')
+				j++
+			} else {
+				for ; j < len(runes); j++ {
+					if runes[j] == '\'' {
+						if j+1 < len(runes) && runes[j+1] == '\'' {
+							literal.WriteRune('\'')
+							j++
+							continue
+						}
+						j++
+						break
+					}
+					literal.WriteRune(runes[j])
+				}
+			}
+			result = append(result, &dateFormatToken{literal: literal.String()})
+			i = j
+		case isDateFormatLetter(r):
+			j := i
+			for j < len(runes) && runes[j] == r {
+				j++
+			}
+			if r == 'z' && j-i == 2 && strings.HasPrefix(string(runes[j:]), ":zz") { //zz:zz represents ISO 8601 zone with colon
+				result = append(result, &dateFormatToken{literal: "Z07:00"})
+				i = j + 3
+				continue
+			}
+			result = append(result, &dateFormatToken{letter: r, count: j - i})
+			i = j
+		default:
+			result = append(result, &dateFormatToken{literal: string(r)})
+			i++
 		}
+	}
+	return result
+}
+
+// StrictTimeLayout enables TryGetTimeLayout validation of layout settings with ValidateTimeLayout and ValidateDateFormat, so that invalid struct tags fail conversion
+var StrictTimeLayout = false
 
-		if value, found := settings[DateFormatKeyword]; found {
-			return DateFormatToLayout(AsString(value))
+// TryGetTimeLayout returns time layout from passed in settings, DateLayoutKeyword or TimeLayoutKeyword value is returned as is,
+// otherwise DateFormatKeyword or StrftimeFormatKeyword value is converted to layout, it returns an error listing present keys if none of them is defined
+func TryGetTimeLayout(settings map[string]string) (string, error) {
+	for _, key := range []string{DateLayoutKeyword, TimeLayoutKeyword} {
+		if value, found := settings[key]; found {
+			if StrictTimeLayout {
+				if err := ValidateTimeLayout(value); err != nil {
+					return "", err
+				}
+			}
+			return value, nil
+		}
+	}
+	if value, found := settings[DateFormatKeyword]; found {
+		if StrictTimeLayout {
+			if err := ValidateDateFormat(value); err != nil {
+				return "", err
+			}
 		}
+		return DateFormatToLayout(value), nil
+	}
+	if value, found := settings[StrftimeFormatKeyword]; found {
+		return StrftimeToLayout(value)
+	}
+	var keys = make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return "", fmt.Errorf("time layout not defined, expected one of %v, %v, %v, %v keys, but had: [%v]", DateLayoutKeyword, TimeLayoutKeyword, DateFormatKeyword, StrftimeFormatKeyword, strings.Join(keys, ", "))
+}
 
+// hasTimeLayoutSetting returns true if any time layout key is present in settings
+func hasTimeLayoutSetting(settings map[string]string) bool {
+	for _, key := range []string{DateLayoutKeyword, TimeLayoutKeyword, DateFormatKeyword, StrftimeFormatKeyword} {
+		if _, found := settings[key]; found {
+			return true
+		}
 	}
-	return ""
+	return false
 }
 
-// HasTimeLayout checks if dateLayout can be taken from the passed in setting map
-func HasTimeLayout(input interface{}) bool {
+// ValidateDateFormat checks java date format for unknown pattern letters, letters without go layout equivalent and common mistakes
+// like YYYY week year instead of yyyy year, mm minutes in date only pattern or DD day of year instead of dd, it returns all problems in one error
+func ValidateDateFormat(dateFormat string) error {
+	tokens := tokenizeDateFormat(dateFormat)
+	var letters = make(map[rune]bool)
+	for _, token := range tokens {
+		letters[token.letter] = true
+	}
+	var hasTime = letters['H'] || letters['h'] || letters['k'] || letters['K']
+	var problems = make([]string, 0)
+	var reported = make(map[string]bool)
+	var report = func(problem string) {
+		if !reported[problem] {
+			reported[problem] = true
+			problems = append(problems, problem)
+		}
+	}
+	for _, token := range tokens {
+		if token.letter == 0 {
+			continue
+		}
+		fragment := strings.Repeat(string(token.letter), token.count)
+		switch {
+		case token.letter == 'Y':
+			report(fmt.Sprintf("%v is week year, did you mean %v", fragment, strings.Repeat("y", token.count)))
+		case token.letter == 'm' && !hasTime:
+			report(fmt.Sprintf("%v is minutes in date only pattern, did you mean %v", fragment, strings.Repeat("M", token.count)))
+		case token.letter == 'D' && (letters['M'] || letters['m'] && !hasTime) && !letters['d']:
+			report(fmt.Sprintf("%v is day of year, did you mean %v", fragment, strings.Repeat("d", token.count)))
+		case token.letter == 'h' && !letters['a']:
+			report(fmt.Sprintf("%v is 12 hour clock without am/pm marker a, did you mean %v", fragment, strings.Repeat("H", token.count)))
+		case strings.ContainsRune("GLwWFukKQ", token.letter) && !token.hasLayout():
+			report(fmt.Sprintf("%v has no go layout equivalent", fragment))
+		case !token.hasLayout():
+			report(fmt.Sprintf("unknown pattern letter %v, quote literal text with '", string(token.letter)))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid date format %q: %v", dateFormat, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ValidateTimeLayout checks that go layout has time elements and round-trips the reference time, that is layout parses its own formatted output into a time formatted the same way
+func ValidateTimeLayout(layout string) error {
+	reference := time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.FixedZone("MST", -7*3600))
+	formatted := reference.Format(layout)
+	if formatted == time.Date(2011, 11, 22, 10, 33, 44, 0, time.UTC).Format(layout) {
+		if strings.ContainsAny(layout, "yMdHms") {
+			return fmt.Errorf("invalid time layout %q: no time elements, it looks like java date format, use DateFormatToLayout", layout)
+		}
+		return fmt.Errorf("invalid time layout %q: no time elements", layout)
+	}
+	if hasHour := reference.Add(time.Hour).Format(layout) != formatted; hasHour && reference.Add(-12*time.Hour).Format(layout) == formatted {
+		return fmt.Errorf("invalid time layout %q: 12 hour clock without PM marker, did you mean 15", layout)
+	}
+	parsed, err := time.Parse(layout, formatted)
+	if err != nil {
+		return fmt.Errorf("invalid time layout %q: unable to parse own output %q, %v", layout, formatted, err)
+	}
+	if reformatted := parsed.Format(layout); reformatted != formatted {
+		return fmt.Errorf("invalid time layout %q: reference time formatted as %q, but round-tripped as %q", layout, formatted, reformatted)
+	}
+	return nil
+}
+
+// timeLayoutSettings returns settings map for map[string]string or map[string]interface{} input
+func timeLayoutSettings(input interface{}) map[string]string {
 	switch settings := input.(type) {
 	case map[string]string:
-		if _, found := settings[DateLayoutKeyword]; found {
-			return true
-		}
-		if _, found := settings[DateFormatKeyword]; found {
-			return true
+		return settings
+	case map[string]interface{}:
+		var result = make(map[string]string, len(settings))
+		for key, value := range settings {
+			result[key] = AsString(value)
 		}
+		return result
+	}
+	return nil
+}
 
+// GetTimeLayout returns time layout from passed in map with TryGetTimeLayout, it returns empty string if layout is not defined
+func GetTimeLayout(input interface{}) string {
+	layout, _ := TryGetTimeLayout(timeLayoutSettings(input))
+	return layout
+}
+
+// HasTimeLayout checks if dateLayout can be taken from the passed in setting map
+func HasTimeLayout(input interface{}) bool {
+	_, err := TryGetTimeLayout(timeLayoutSettings(input))
+	return err == nil
+}
+
+// ToTime converts value to time, time values are passed through, text is parsed with supplied layout or with detected layout if layout is empty,
+// numeric values including json.Number are unix epoch with seconds, milliseconds, microseconds or nanoseconds unit detected by magnitude
+func ToTime(value interface{}, dateLayout string) (*time.Time, error) {
+	if value == nil {
+		return nil, errors.New("values was empty")
+	}
+	switch actual := value.(type) {
+	case time.Time:
+		return &actual, nil
+	case *time.Time:
+		return actual, nil
+	case json.Number:
+		return numberToTime(string(actual))
+	case int, int8, int16, int32, int64:
+		return epochToTime(reflect.ValueOf(actual).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return epochToTime(int64(reflect.ValueOf(actual).Uint())), nil
+	case float32:
+		return floatEpochToTime(float64(actual)), nil
+	case float64:
+		return floatEpochToTime(actual), nil
+	case *float32, *float64, *int, *int8, *int16, *int32, *int64, *uint, *uint8, *uint16, *uint32, *uint64:
+		return ToTime(DereferenceValue(actual), dateLayout)
+	case string:
+		return textToTime(actual, dateLayout)
 	case map[string]interface{}:
-		if _, found := settings[DateLayoutKeyword]; found {
-			return true
+		if len(actual) == 0 {
+			return nil, nil
 		}
-		if _, found := settings[DateFormatKeyword]; found {
-			return true
+	}
+	textValue := AsString(DereferenceValue(value))
+	return textToTime(textValue, dateLayout)
+}
+
+// epochToTime converts unix epoch to time, unit is detected by magnitude: seconds below 1e11, milliseconds below 1e14, microseconds below 1e17, nanoseconds otherwise
+func epochToTime(epoch int64) *time.Time {
+	var magnitude = epoch
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+	var timeValue time.Time
+	switch {
+	case magnitude < 1e11:
+		timeValue = time.Unix(epoch, 0)
+	case magnitude < 1e14:
+		timeValue = time.Unix(0, epoch*int64(time.Millisecond))
+	case magnitude < 1e17:
+		timeValue = time.Unix(0, epoch*int64(time.Microsecond))
+	default:
+		timeValue = time.Unix(0, epoch)
+	}
+	return &timeValue
+}
+
+// floatEpochToTime converts unix epoch to time, fraction of epoch seconds is kept
+func floatEpochToTime(epoch float64) *time.Time {
+	if seconds := math.Floor(epoch); seconds != epoch && math.Abs(epoch) < 1e11 {
+		timeValue := time.Unix(int64(seconds), int64((epoch-seconds)*float64(time.Second)))
+		return &timeValue
+	}
+	return epochToTime(int64(epoch))
+}
+
+// numberToTime converts numeric text to time
+func numberToTime(value string) (*time.Time, error) {
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return epochToTime(epoch), nil
+	}
+	epoch, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %v to time, %v", value, err)
+	}
+	return floatEpochToTime(epoch), nil
+}
+
+func textToTime(value, dateLayout string) (*time.Time, error) {
+	if timeValue, err := numberToTime(value); err == nil {
+		return timeValue, nil
+	}
+	rawValue := value
+	var layouts = []string{dateLayout}
+	timeValue, err := ParseTime(value, dateLayout)
+	if err != nil {
+		if dateLayout != "" {
+			updated := value
+			if len(value) > len(dateLayout) {
+				updated = string(value[:len(dateLayout)])
+			}
+			timeValue, err = ParseTime(updated, dateLayout)
+		} else {
+			layouts[0] = DefaultDateLayout
+		}
+
+		if err != nil { //JSON default time format fallback
+			layouts = append(layouts, time.RFC3339)
+			if timeValue, err = ParseTime(value, time.RFC3339); err == nil {
+				return &timeValue, err
+			}
+
+			if msIndex := strings.LastIndex(rawValue, "."); msIndex != -1 && msIndex < len(value)-1 {
+				ms := value[msIndex+1:]
+				i := 0
+				for ; i < len(ms); i++ {
+					if ms[i] < '0' || ms[i] > '9' {
+						break
+					}
+				}
+				msLayout := fmt.Sprintf("2006-01-02T15:04:05.%sZ07:00", strings.Repeat("9", i))
+				layouts = append(layouts, msLayout)
+				if timeValue, err = ParseTime(rawValue, msLayout); err == nil {
+					return &timeValue, err
+				}
+			}
+			if dateLayout == "" {
+				layouts = append(layouts, "detected layout")
+				if layout, ok := DetectTimeLayout(value); ok {
+					if timeValue, err = parseTimeWithLayout(layout, value); err == nil {
+						return &timeValue, nil
+					}
+				}
+			}
+			return nil, fmt.Errorf("unable to convert %q to time, attempted layouts: %v, %v", value, strings.Join(layouts, ", "), err)
 		}
 
 	}
-	return false
+	return &timeValue, nil
 }
 
 // TimestampToString formats timestamp to passed in java style date format
@@ -111,3 +756,388 @@ func TimestampToString(dateFormat string, unixTimestamp, unixNanoTimestamp int64
 	dateLayout := DateFormatToLayout(dateFormat)
 	return t.Format(dateLayout)
 }
+
+// EpochSecondsLayout represents pseudo layout of unix epoch seconds detected numerically
+const EpochSecondsLayout = "epochSeconds"
+
+// EpochMillisLayout represents pseudo layout of unix epoch milliseconds detected numerically
+const EpochMillisLayout = "epochMillis"
+
+var timeLayoutCatalog = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"20060102150405",
+	"20060102",
+	"01/02/2006 15:04:05",
+	"02/01/2006 15:04:05",
+	"01/02/2006",
+	"02/01/2006",
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+	"02-Jan-2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.ANSIC,
+	EpochSecondsLayout,
+	EpochMillisLayout,
+}
+var timeLayoutCatalogMutex = &sync.RWMutex{}
+
+// RegisterTimeLayout adds supplied layout to the time layout detection catalog
+func RegisterTimeLayout(layout string) {
+	timeLayoutCatalogMutex.Lock()
+	defer timeLayoutCatalogMutex.Unlock()
+	for _, candidate := range timeLayoutCatalog {
+		if candidate == layout {
+			return
+		}
+	}
+	timeLayoutCatalog = append(timeLayoutCatalog, layout)
+}
+
+func timeLayouts() []string {
+	timeLayoutCatalogMutex.RLock()
+	defer timeLayoutCatalogMutex.RUnlock()
+	return append([]string{}, timeLayoutCatalog...)
+}
+
+// parseTimeWithLayout parses supplied value with layout or epoch pseudo layout
+func parseTimeWithLayout(layout, value string) (time.Time, error) {
+	switch layout {
+	case EpochSecondsLayout, EpochMillisLayout:
+		if len(value) == 0 || strings.Trim(value, "0123456789") != "" {
+			return time.Time{}, fmt.Errorf("invalid %v: %v", layout, value)
+		}
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == EpochSecondsLayout && len(value) >= 9 && len(value) <= 10 {
+			return time.Unix(epoch, 0).UTC(), nil
+		}
+		if layout == EpochMillisLayout && len(value) >= 12 && len(value) <= 13 {
+			return time.Unix(0, epoch*int64(time.Millisecond)).UTC(), nil
+		}
+		return time.Time{}, fmt.Errorf("invalid %v: %v", layout, value)
+	}
+	return time.Parse(layout, value)
+}
+
+// DetectTimeLayout returns the first catalog layout parsing supplied sample, epoch samples return EpochSecondsLayout or EpochMillisLayout,
+// it returns false if no layout parses the sample or the sample is ambiguous, that is matching layouts parse it into different times
+func DetectTimeLayout(sample string) (string, bool) {
+	return DetectTimeLayoutFromSamples([]string{sample})
+}
+
+// DetectTimeLayoutFromSamples returns the first catalog layout parsing all supplied samples, layouts that do not parse any sample are excluded,
+// so that day/month ambiguity is resolved when any sample has day greater than 12
+func DetectTimeLayoutFromSamples(samples []string) (string, bool) {
+	layouts := matchTimeLayouts(samples)
+	if len(layouts) == 0 || isTimeLayoutAmbiguous(layouts, samples) {
+		return "", false
+	}
+	return layouts[0], true
+}
+
+// matchTimeLayouts returns catalog layouts parsing all supplied samples
+func matchTimeLayouts(samples []string) []string {
+	if len(samples) == 0 {
+		return nil
+	}
+	layouts := timeLayouts()
+	for _, sample := range samples {
+		var matched = make([]string, 0, len(layouts))
+		for _, layout := range layouts {
+			if _, err := parseTimeWithLayout(layout, sample); err == nil {
+				matched = append(matched, layout)
+			}
+		}
+		layouts = matched
+	}
+	return layouts
+}
+
+// isTimeLayoutAmbiguous returns true if supplied layouts parse any sample into different times
+func isTimeLayoutAmbiguous(layouts []string, samples []string) bool {
+	for _, sample := range samples {
+		expected, _ := parseTimeWithLayout(layouts[0], sample)
+		for _, layout := range layouts[1:] {
+			if parsed, _ := parseTimeWithLayout(layout, sample); !parsed.Equal(expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NormalizeTimestampOptions represents timestamp normalization options
+type NormalizeTimestampOptions struct {
+	FailOnAmbiguous bool //returns an error instead of using the first catalog layout when detected layouts parse a value into different times, i.e. 03/04/2021
+}
+
+// NormalizeTimestamp converts time, epoch number or text in any detectable layout into target layout text in supplied location (UTC if nil),
+// text without zone is interpreted in supplied location
+func NormalizeTimestamp(value interface{}, targetLayout string, location *time.Location, options ...*NormalizeTimestampOptions) (string, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	textValue, ok := DereferenceValue(value).(string)
+	if !ok {
+		timeValue, err := ToTime(value, "")
+		if err != nil {
+			return "", err
+		}
+		if timeValue == nil {
+			return "", fmt.Errorf("unable to normalize %v to time", value)
+		}
+		return timeValue.In(location).Format(targetLayout), nil
+	}
+	layout, err := detectNormalizationLayout([]string{textValue}, options)
+	if err != nil {
+		return "", err
+	}
+	return formatNormalizedTimestamp(textValue, layout, targetLayout, location)
+}
+
+// NormalizeTimestampColumn normalizes column values of supplied records in place with NormalizeTimestamp rules, nil and missing values are skipped,
+// text layout is detected once per value shape, with all column values of that shape as samples, so that day/month ambiguity is resolved across the column
+func NormalizeTimestampColumn(records []map[string]interface{}, column, targetLayout string, location *time.Location, options ...*NormalizeTimestampOptions) error {
+	if location == nil {
+		location = time.UTC
+	}
+	var samples = make(map[string][]string)
+	for _, record := range records {
+		if textValue, ok := DereferenceValue(record[column]).(string); ok {
+			shape := timestampShape(textValue)
+			samples[shape] = append(samples[shape], textValue)
+		}
+	}
+	var layouts = make(map[string]string, len(samples))
+	for shape, shapeSamples := range samples {
+		layout, err := detectNormalizationLayout(shapeSamples, options)
+		if err != nil {
+			return err
+		}
+		layouts[shape] = layout
+	}
+	for i, record := range records {
+		value, ok := record[column]
+		if !ok || value == nil {
+			continue
+		}
+		var normalized string
+		var err error
+		if textValue, ok := DereferenceValue(value).(string); ok {
+			normalized, err = formatNormalizedTimestamp(textValue, layouts[timestampShape(textValue)], targetLayout, location)
+		} else {
+			normalized, err = NormalizeTimestamp(value, targetLayout, location, options...)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to normalize %v at record %v, %v", column, i, err)
+		}
+		record[column] = normalized
+	}
+	return nil
+}
+
+// timestampShape returns value with digits replaced by 0, values of the same shape share time layout
+func timestampShape(value string) string {
+	var result = []byte(value)
+	for i, b := range result {
+		if b >= '0' && b <= '9' {
+			result[i] = '0'
+		}
+	}
+	return string(result)
+}
+
+// detectNormalizationLayout returns catalog layout parsing all samples, empty layout for numeric samples converted as epoch by ToTime
+func detectNormalizationLayout(samples []string, options []*NormalizeTimestampOptions) (string, error) {
+	if _, err := strconv.ParseFloat(samples[0], 64); err == nil {
+		return "", nil
+	}
+	layouts := matchTimeLayouts(samples)
+	if len(layouts) == 0 {
+		return "", fmt.Errorf("unable to detect time layout of %q", samples[0])
+	}
+	if len(options) > 0 && options[0] != nil && options[0].FailOnAmbiguous && isTimeLayoutAmbiguous(layouts, samples) {
+		return "", fmt.Errorf("ambiguous time layout of %q, candidates: %v", samples[0], strings.Join(layouts, ", "))
+	}
+	return layouts[0], nil
+}
+
+// formatNormalizedTimestamp parses value with source layout in location and formats it with target layout
+func formatNormalizedTimestamp(value, layout, targetLayout string, location *time.Location) (string, error) {
+	var timeValue time.Time
+	var err error
+	switch layout {
+	case "":
+		var epochTime *time.Time
+		if epochTime, err = numberToTime(value); err == nil {
+			timeValue = *epochTime
+		}
+	case EpochSecondsLayout, EpochMillisLayout:
+		timeValue, err = parseTimeWithLayout(layout, value)
+	default:
+		timeValue, err = time.ParseInLocation(layout, value, location)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to convert %q to time, %v", value, err)
+	}
+	return timeValue.In(location).Format(targetLayout), nil
+}
+
+// StrftimeFormatKeyword constant 'strftimeFormat' key
+var StrftimeFormatKeyword = "strftimeFormat"
+
+// strftimeDirectives represents strftime directives with go date layout equivalents
+var strftimeDirectives = map[rune]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'z': "-0700",
+	'Z': "MST",
+	'j': "002",
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'D': "01/02/06",
+	'R': "15:04",
+	'n': "\n",
+	't': "\t",
+	'%': "%",
+}
+
+// StrftimeToLayout converts strftime format i.e. %Y-%m-%dT%H:%M:%S%z into go date layout, %f microseconds has to follow . or , separator,
+// it returns an error naming unsupported directive
+func StrftimeToLayout(format string) (string, error) {
+	var result = new(bytes.Buffer)
+	var runes = []rune(format)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			result.WriteRune(runes[i])
+			continue
+		}
+		if i+1 >= len(runes) {
+			return "", fmt.Errorf("incomplete strftime directive at the end of %v", format)
+		}
+		i++
+		directive := runes[i]
+		if directive == 'f' {
+			if i < 2 || (runes[i-2] != '.' && runes[i-2] != ',') {
+				return "", fmt.Errorf("unsupported strftime directive: %%f not following . or , in %v", format)
+			}
+			result.WriteString("000000")
+			continue
+		}
+		layout, ok := strftimeDirectives[directive]
+		if !ok {
+			return "", fmt.Errorf("unsupported strftime directive: %v in %v", "%"+string(directive), format)
+		}
+		result.WriteString(layout)
+	}
+	return result.String(), nil
+}
+
+// layoutStrftimeTokens represents go date layout tokens with strftime equivalents, tokens without equivalent have empty directive, longer tokens come first
+var layoutStrftimeTokens = []struct {
+	layout    string
+	directive string
+}{
+	{"January", "%B"},
+	{"Jan", "%b"},
+	{"Monday", "%A"},
+	{"Mon", "%a"},
+	{"MST", "%Z"},
+	{"2006", "%Y"},
+	{"002", "%j"},
+	{"__2", ""},
+	{"_2", "%e"},
+	{"01", "%m"},
+	{"02", "%d"},
+	{"03", "%I"},
+	{"04", "%M"},
+	{"05", "%S"},
+	{"06", "%y"},
+	{"15", "%H"},
+	{"1", ""},
+	{"2", ""},
+	{"3", ""},
+	{"4", ""},
+	{"5", ""},
+	{"PM", "%p"},
+	{"pm", ""},
+	{"Z070000", ""},
+	{"Z07:00:00", ""},
+	{"Z0700", ""},
+	{"Z07:00", ""},
+	{"Z07", ""},
+	{"-070000", ""},
+	{"-07:00:00", ""},
+	{"-0700", "%z"},
+	{"-07:00", ""},
+	{"-07", ""},
+}
+
+// LayoutToStrftime converts go date layout into strftime format, it is inverse of StrftimeToLayout, it returns an error naming layout fragment without strftime equivalent
+func LayoutToStrftime(layout string) (string, error) {
+	var result = new(bytes.Buffer)
+	for i := 0; i < len(layout); {
+		if fraction := layoutFractionLength(layout, i); fraction > 0 {
+			if fraction != 6 {
+				return "", fmt.Errorf("unsupported layout fragment: %v in %v", layout[i:i+fraction+1], layout)
+			}
+			result.WriteString(layout[i:i+1] + "%f")
+			i += fraction + 1
+			continue
+		}
+		matched := false
+		for _, token := range layoutStrftimeTokens {
+			if !strings.HasPrefix(layout[i:], token.layout) {
+				continue
+			}
+			if token.directive == "" {
+				return "", fmt.Errorf("unsupported layout fragment: %v in %v", token.layout, layout)
+			}
+			result.WriteString(token.directive)
+			i += len(token.layout)
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+		if layout[i] == '%' {
+			result.WriteString("%%")
+		} else {
+			result.WriteByte(layout[i])
+		}
+		i++
+	}
+	return result.String(), nil
+}
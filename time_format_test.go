@@ -1,6 +1,7 @@
 package toolbox_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/toolbox"
@@ -116,6 +117,175 @@ func TestTimeFormat(t *testing.T) {
 
 }
 
+func TestDateFormatToLayout(t *testing.T) {
+	timeValue := time.Date(2021, 3, 7, 14, 5, 9, 123456789, time.FixedZone("PST", -8*3600))
+	var useCases = []struct {
+		description string
+		format      string
+		layout      string
+		formatted   string
+	}{
+		{"ISO-8601", "yyyy-MM-dd'T'HH:mm:ss.SSSXXX", "2006-01-02T15:04:05.000Z07:00", "2021-03-07T14:05:09.123-08:00"},
+		{"ISO-8601 basic zone", "yyyy-MM-dd'T'HH:mm:ssZZ", "2006-01-02T15:04:05-0700", "2021-03-07T14:05:09-0800"},
+		{"ISO-8601 hour zone", "yyyy-MM-dd'T'HH:mm:ssX", "2006-01-02T15:04:05Z07", "2021-03-07T14:05:09-08"},
+		{"ISO-8601 zone with colon", "yyyy-MM-dd HH:mm:ss zz:zz", "2006-01-02 15:04:05 Z07:00", "2021-03-07 14:05:09 -08:00"},
+		{"RFC-822", "EEE, dd MMM yy HH:mm z", "Mon, 02 Jan 06 15:04 MST", "Sun, 07 Mar 21 14:05 PST"},
+		{"RFC-822 numeric zone", "EEE, dd MMM yy HH:mm:ss ZZ", "Mon, 02 Jan 06 15:04:05 -0700", "Sun, 07 Mar 21 14:05:09 -0800"},
+		{"repeated token", "yyyy-MM-dd HH:mm:ss.SSS zzz MM", "2006-01-02 15:04:05.000 MST 01", "2021-03-07 14:05:09.123 PST 03"},
+		{"repeated day", "dd/MM/yyyy (dd)", "02/01/2006 (02)", "07/03/2021 (07)"},
+		{"month name", "MMMM d, yyyy", "January 2, 2006", "March 7, 2021"},
+		{"day name", "EEEE, MMMM dd", "Monday, January 02", "Sunday, March 07"},
+		{"12 hour clock", "hh:mm a", "03:04 PM", "02:05 PM"},
+		{"12 hour clock no padding", "h:mm a", "3:04 PM", "2:05 PM"},
+		{"hh without marker", "yyyy-MM-dd hh:mm:ss", "2006-01-02 15:04:05", "2021-03-07 14:05:09"},
+		{"two digit year", "dd.MM.yy", "02.01.06", "07.03.21"},
+		{"compact", "yyyyMMddHHmmss", "20060102150405", "20210307140509"},
+		{"quoted letters", "'Date:' yyyy/M/d", "Date: 2006/1/2", "Date: 2021/3/7"},
+		{"escaped quote", "''yy'' 'o''clock' H", "'06' o'clock 15", "'21' o'clock 14"},
+		{"microseconds", "HH:mm:ss.SSSSSS", "15:04:05.000000", "14:05:09.123456"},
+		{"deciseconds", "HH:mm:ss.S", "15:04:05.0", "14:05:09.1"},
+		{"centiseconds", "HH:mm:ss,SS", "15:04:05,00", "14:05:09,12"},
+		{"nanoseconds", "HH:mm:ss.SSSSSSSSS", "15:04:05.000000000", "14:05:09.123456789"},
+	}
+	for _, useCase := range useCases {
+		layout := toolbox.DateFormatToLayout(useCase.format)
+		assert.Equal(t, useCase.layout, layout, useCase.description)
+		assert.Equal(t, useCase.formatted, timeValue.Format(layout), useCase.description)
+	}
+}
+
+func TestDateFormatToLayoutWithOptions(t *testing.T) {
+	options := &toolbox.DateFormatLayoutOptions{VariableFraction: true}
+	layout := toolbox.DateFormatToLayoutWithOptions("yyyy-MM-dd HH:mm:ss.SSSSSS", options)
+	assert.Equal(t, "2006-01-02 15:04:05.999999", layout)
+	timeValue, err := time.Parse(layout, "2021-03-07 14:05:09.12")
+	if assert.Nil(t, err) {
+		assert.Equal(t, 120000000, timeValue.Nanosecond())
+	}
+	assert.Equal(t, "2006-01-02 15:04:05.000000", toolbox.DateFormatToLayoutWithOptions("yyyy-MM-dd HH:mm:ss.SSSSSS", nil))
+}
+
+func TestParseTimeLenientFraction(t *testing.T) {
+	expected := time.Date(2021, 3, 7, 14, 5, 9, 500000000, time.UTC)
+	for _, layout := range []string{"2006-01-02T15:04:05.000Z07:00", "2006-01-02T15:04:05.0Z07:00", "2006-01-02T15:04:05.000000000Z07:00", "2006-01-02T15:04:05Z07:00"} {
+		for _, value := range []string{"2021-03-07T14:05:09.5Z", "2021-03-07T14:05:09.500Z", "2021-03-07T14:05:09.500000Z", "2021-03-07T14:05:09.500000000Z"} {
+			actual, err := toolbox.ParseTimeLenientFraction(value, layout)
+			if assert.Nil(t, err, value+" "+layout) {
+				assert.True(t, expected.Equal(actual), value+" "+layout)
+			}
+		}
+		actual, err := toolbox.ParseTimeLenientFraction("2021-03-07T14:05:09Z", layout)
+		if assert.Nil(t, err, layout) {
+			assert.True(t, expected.Truncate(time.Second).Equal(actual), layout)
+		}
+	}
+	_, err := time.Parse("2006-01-02T15:04:05.000Z07:00", "2021-03-07T14:05:09.5Z")
+	assert.NotNil(t, err)
+}
+
+func TestDateFormatToLayoutChecked(t *testing.T) {
+	layout, err := toolbox.DateFormatToLayoutChecked("yyyy-DDD")
+	assert.Nil(t, err)
+	assert.Equal(t, "2006-002", layout)
+
+	_, err = toolbox.DateFormatToLayoutChecked("YYYY-'W'ww-u")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "YYYY, ww, u"), err.Error())
+	}
+	_, err = toolbox.DateFormatToLayoutChecked("G yyyy QQQ")
+	assert.NotNil(t, err)
+}
+
+func TestFormatTime(t *testing.T) {
+	var useCases = []struct {
+		description string
+		time        time.Time
+		format      string
+		expected    string
+	}{
+		{description: "day of year", time: time.Date(2021, 2, 5, 0, 0, 0, 0, time.UTC), format: "yyyy-DDD", expected: "2021-036"},
+		{description: "unpadded day of year", time: time.Date(2021, 2, 5, 0, 0, 0, 0, time.UTC), format: "yyyy.D", expected: "2021.36"},
+		{description: "ISO week date", time: time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), format: "YYYY-'W'ww-u", expected: "2020-W53-7"},
+		{description: "ISO week date monday", time: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), format: "YYYY-'W'ww-u", expected: "2021-W01-1"},
+		{description: "week of month", time: time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC), format: "MMM W", expected: "Mar 3"},
+		{description: "quarter", time: time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), format: "yyyy QQQ", expected: "2021 Q3"},
+		{description: "long quarter", time: time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC), format: "QQQQ yy", expected: "4th quarter 21"},
+		{description: "era", time: time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), format: "G yyyy", expected: "AD 2021"},
+		{description: "standard tokens", time: time.Date(2021, 8, 1, 15, 4, 5, 0, time.UTC), format: "yyyy-MM-dd'T'HH:mm:ss", expected: "2021-08-01T15:04:05"},
+		{description: "quoted literals", time: time.Date(2021, 8, 1, 15, 4, 5, 0, time.UTC), format: "'Day' D 'of' yyyy", expected: "Day 213 of 2021"},
+	}
+	for _, useCase := range useCases {
+		actual, err := toolbox.FormatTime(useCase.time, useCase.format)
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expected, actual, useCase.description)
+		}
+	}
+	_, err := toolbox.FormatTime(time.Now(), "yyyy-F")
+	assert.NotNil(t, err)
+}
+
+func TestLayoutToDateFormat(t *testing.T) {
+	assert.Equal(t, "yyyy-MM-dd HH:mm:ss.SSS z", toolbox.LayoutToDateFormat("2006-01-02 15:04:05.000 MST"))
+
+	var formats = []string{
+		"yyyy-MM-dd",
+		"yyyy-MM-dd HH:mm:ss",
+		"yyyy-MM-dd HH:mm:ss.SSS z",
+		"yyyy-MM-dd'T'HH:mm:ss.SSSXXX",
+		"yyyy-MM-dd'T'HH:mm:ssXX",
+		"yyyy-MM-dd'T'HH:mm:ssX",
+		"yyyy-MM-dd HH:mm:ss.SSSSSS ZZ",
+		"yyyy-MM-dd HH:mm:ss ZZZ",
+		"yyyyMMddHHmmss",
+		"dd/MM/yyyy",
+		"MM/dd/yy hh:mm a",
+		"h:mm a",
+		"EEE, dd MMM yyyy HH:mm:ss z",
+		"EEEE, MMMM d, yyyy",
+		"MMM ddd HH:mm:ss",
+		"yyyy.DDD",
+		"HH:mm:ss,SSS",
+		"M/d/yyyy HH:m:s",
+		"yyyy 'o''clock'",
+	}
+	for _, format := range formats {
+		layout := toolbox.DateFormatToLayout(format)
+		assert.Equal(t, format, toolbox.LayoutToDateFormat(layout), layout)
+	}
+
+	var layouts = []string{
+		time.ANSIC,
+		time.RFC822,
+		time.RFC822Z,
+		time.RFC1123,
+		time.RFC1123Z,
+		time.RFC3339,
+		time.Kitchen,
+		time.StampMicro,
+		"2006-01-02 15:04:05.000 MST",
+		"Jan 2, 2006 at 3:04pm",
+	}
+	for _, layout := range layouts {
+		format := toolbox.LayoutToDateFormat(layout)
+		assert.Equal(t, strings.Replace(layout, "pm", "PM", 1), toolbox.DateFormatToLayout(format), format)
+	}
+
+	var useCases = []struct {
+		layout string
+		format string
+	}{
+		{time.RFC3339Nano, "yyyy-MM-dd'T'HH:mm:ss.SSSSSSSSSXXX"},
+		{"Jan 2, 2006 at 3:04pm", "MMM d, yyyy 'at' h:mma"},
+		{"15:04:05 -07:00:00", "HH:mm:ss '-07:00:00'"},
+		{"2006-01-02T15:04:05Z070000", "yyyy-MM-dd'T'HH:mm:ss'Z070000'"},
+		{"2006 o'clock", "yyyy 'o''clock'"},
+		{"'06", "''yy"},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.format, toolbox.LayoutToDateFormat(useCase.layout), useCase.layout)
+	}
+}
+
 func TestGetTimeLayout(t *testing.T) {
 	{
 		settings := map[string]string{
@@ -138,6 +308,121 @@ func TestGetTimeLayout(t *testing.T) {
 	}
 }
 
+func TestTryGetTimeLayout(t *testing.T) {
+	layout, err := toolbox.TryGetTimeLayout(map[string]string{toolbox.TimeLayoutKeyword: "2006-01-02"})
+	assert.Nil(t, err)
+	assert.Equal(t, "2006-01-02", layout)
+	assert.True(t, toolbox.HasTimeLayout(map[string]interface{}{toolbox.TimeLayoutKeyword: "2006-01-02"}))
+
+	layout, err = toolbox.TryGetTimeLayout(map[string]string{toolbox.DateFormatKeyword: "yyyy-MM-dd"})
+	assert.Nil(t, err)
+	assert.Equal(t, "2006-01-02", layout)
+
+	_, err = toolbox.TryGetTimeLayout(map[string]string{"column": "created", "default": "now"})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "[column, default]"), err.Error())
+	}
+	_, err = toolbox.TryGetTimeLayout(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "", toolbox.GetTimeLayout(map[string]string{}))
+	assert.Equal(t, "", toolbox.GetTimeLayout(nil))
+}
+
+func TestConverter_TimeLayoutTag(t *testing.T) {
+	type event struct {
+		Name      string
+		Created   time.Time  `dateFormat:"dd/MM/yyyy"`
+		Updated   *time.Time `timeLayout:"2006.01.02"`
+		Scheduled time.Time
+	}
+	converter := toolbox.NewConverter(toolbox.DefaultDateLayout, "")
+	var target = &event{}
+	err := converter.AssignConverted(target, map[string]interface{}{
+		"Name":      "release",
+		"Created":   "25/12/2021",
+		"Updated":   "2021.12.26",
+		"Scheduled": "2021-12-27 10:00:00.000",
+	})
+	if assert.Nil(t, err) {
+		assert.Equal(t, time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC), target.Created)
+		if assert.NotNil(t, target.Updated) {
+			assert.Equal(t, time.Date(2021, 12, 26, 0, 0, 0, 0, time.UTC), *target.Updated)
+		}
+		assert.Equal(t, time.Date(2021, 12, 27, 10, 0, 0, 0, time.UTC), target.Scheduled)
+	}
+	assert.Equal(t, toolbox.DefaultDateLayout, converter.DateLayout)
+}
+
+func TestValidateDateFormat(t *testing.T) {
+	var useCases = []struct {
+		description string
+		format      string
+		problems    []string
+	}{
+		{description: "valid date", format: "yyyy-MM-dd"},
+		{description: "valid ISO-8601", format: "yyyy-MM-dd'T'HH:mm:ss.SSSXXX"},
+		{description: "valid 12 hour clock", format: "hh:mm a"},
+		{description: "week year", format: "YYYY-MM-dd", problems: []string{"YYYY is week year, did you mean yyyy"}},
+		{description: "minutes in date", format: "yyyy-mm-dd", problems: []string{"mm is minutes in date only pattern, did you mean MM"}},
+		{description: "day of year", format: "yyyy-MM-DD", problems: []string{"DD is day of year, did you mean dd"}},
+		{description: "12 hour clock without marker", format: "yyyy-MM-dd hh:mm:ss", problems: []string{"hh is 12 hour clock without am/pm marker a, did you mean HH"}},
+		{description: "unquoted literal", format: "yyyy-MM-ddTHH:mm", problems: []string{"unknown pattern letter T"}},
+		{description: "no go equivalent", format: "yyyy-'W'ww", problems: []string{"ww has no go layout equivalent"}},
+		{description: "all problems", format: "YYYY/mm/DD", problems: []string{"YYYY is week year", "mm is minutes", "DD is day of year"}},
+	}
+	for _, useCase := range useCases {
+		err := toolbox.ValidateDateFormat(useCase.format)
+		if len(useCase.problems) == 0 {
+			assert.Nil(t, err, useCase.description)
+			continue
+		}
+		if !assert.NotNil(t, err, useCase.description) {
+			continue
+		}
+		for _, problem := range useCase.problems {
+			assert.True(t, strings.Contains(err.Error(), problem), useCase.description+": "+err.Error())
+		}
+	}
+}
+
+func TestValidateTimeLayout(t *testing.T) {
+	var useCases = []struct {
+		description string
+		layout      string
+		hasError    bool
+	}{
+		{description: "RFC3339", layout: time.RFC3339},
+		{description: "date", layout: "2006-01-02"},
+		{description: "12 hour clock", layout: "Jan _2 03:04:05.000 PM"},
+		{description: "java date format", layout: "yyyy-MM-dd HH:mm", hasError: true},
+		{description: "empty", layout: "", hasError: true},
+		{description: "12 hour clock without marker", layout: "2006-01-02 03:04:05", hasError: true},
+		{description: "conflicting hours", layout: "2006-01-02 15 03", hasError: true},
+	}
+	for _, useCase := range useCases {
+		err := toolbox.ValidateTimeLayout(useCase.layout)
+		assert.Equal(t, useCase.hasError, err != nil, useCase.description)
+	}
+}
+
+func TestStrictTimeLayout(t *testing.T) {
+	toolbox.StrictTimeLayout = true
+	defer func() { toolbox.StrictTimeLayout = false }()
+	_, err := toolbox.TryGetTimeLayout(map[string]string{toolbox.DateFormatKeyword: "YYYY-MM-dd"})
+	assert.NotNil(t, err)
+	_, err = toolbox.TryGetTimeLayout(map[string]string{toolbox.TimeLayoutKeyword: "yyyy-MM-dd"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "", toolbox.GetTimeLayout(map[string]string{toolbox.DateFormatKeyword: "yyyy-mm-dd"}))
+	assert.Equal(t, "2006-01-02", toolbox.GetTimeLayout(map[string]string{toolbox.DateFormatKeyword: "yyyy-MM-dd"}))
+
+	type event struct {
+		Created time.Time `dateFormat:"YYYY/MM/dd"`
+	}
+	converter := toolbox.NewConverter(toolbox.DefaultDateLayout, "")
+	err = converter.AssignConverted(&event{}, map[string]interface{}{"Created": "2021/12/25"})
+	assert.NotNil(t, err)
+}
+
 func TestTimestampToString(t *testing.T) {
 
 	{
@@ -151,3 +436,316 @@ func TestTimestampToString(t *testing.T) {
 	}
 
 }
+
+func TestDetectTimeLayout(t *testing.T) {
+	var useCases = []struct {
+		sample string
+		layout string
+		ok     bool
+	}{
+		{"2021-03-07T14:05:09Z", time.RFC3339Nano, true},
+		{"2021-03-07T14:05:09.123456+01:00", time.RFC3339Nano, true},
+		{"2021-03-07", "2006-01-02", true},
+		{"2021-03-07 14:05:09", "2006-01-02 15:04:05.999999999", true},
+		{"2021/03/07", "2006/01/02", true},
+		{"20210307", "20060102", true},
+		{"13/04/2021", "02/01/2006", true},
+		{"04/13/2021", "01/02/2006", true},
+		{"Sun, 07 Mar 2021 14:05:09 -0800", time.RFC1123Z, true},
+		{"Mar 7, 2021", "Jan 2, 2006", true},
+		{"1615125909", toolbox.EpochSecondsLayout, true},
+		{"1615125909123", toolbox.EpochMillisLayout, true},
+		{"03/04/2021", "", false},
+		{"not a date", "", false},
+		{"2021-13-45", "", false},
+		{"12345", "", false},
+	}
+	for _, useCase := range useCases {
+		layout, ok := toolbox.DetectTimeLayout(useCase.sample)
+		assert.Equal(t, useCase.ok, ok, useCase.sample)
+		assert.Equal(t, useCase.layout, layout, useCase.sample)
+	}
+}
+
+func TestDetectTimeLayoutFromSamples(t *testing.T) {
+	layout, ok := toolbox.DetectTimeLayoutFromSamples([]string{"03/04/2021", "05/06/2021", "25/06/2021"})
+	assert.True(t, ok)
+	assert.Equal(t, "02/01/2006", layout)
+
+	layout, ok = toolbox.DetectTimeLayoutFromSamples([]string{"03/04/2021", "05/06/2021", "06/25/2021"})
+	assert.True(t, ok)
+	assert.Equal(t, "01/02/2006", layout)
+
+	_, ok = toolbox.DetectTimeLayoutFromSamples([]string{"03/04/2021", "05/06/2021"})
+	assert.False(t, ok)
+
+	_, ok = toolbox.DetectTimeLayoutFromSamples([]string{"13/04/2021", "04/13/2021"})
+	assert.False(t, ok)
+
+	_, ok = toolbox.DetectTimeLayoutFromSamples([]string{"2021-03-07", "2021-03-07T14:05:09Z"})
+	assert.False(t, ok)
+
+	_, ok = toolbox.DetectTimeLayoutFromSamples(nil)
+	assert.False(t, ok)
+}
+
+func TestNormalizeTimestamp(t *testing.T) {
+	layout := "2006-01-02 15:04:05"
+	var useCases = []struct {
+		description string
+		value       interface{}
+		expected    string
+	}{
+		{"RFC3339", "2021-03-07T14:05:09Z", "2021-03-07 14:05:09"},
+		{"RFC3339 with offset", "2021-03-07T15:05:09+01:00", "2021-03-07 14:05:09"},
+		{"epoch millis", int64(1615125909000), "2021-03-07 14:05:09"},
+		{"epoch millis text", "1615125909000", "2021-03-07 14:05:09"},
+		{"epoch seconds json number", json.Number("1615125909"), "2021-03-07 14:05:09"},
+		{"date only", "2021-03-07", "2021-03-07 00:00:00"},
+		{"time value", time.Date(2021, 3, 7, 14, 5, 9, 0, time.UTC), "2021-03-07 14:05:09"},
+		{"guessed ambiguous", "03/04/2021", "2021-03-04 00:00:00"},
+	}
+	for _, useCase := range useCases {
+		actual, err := toolbox.NormalizeTimestamp(useCase.value, layout, time.UTC)
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expected, actual, useCase.description)
+		}
+	}
+
+	location, _ := time.LoadLocation("America/New_York")
+	actual, err := toolbox.NormalizeTimestamp("2021-03-07", time.RFC3339, location)
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-03-07T00:00:00-05:00", actual)
+
+	_, err = toolbox.NormalizeTimestamp("03/04/2021", layout, time.UTC, &toolbox.NormalizeTimestampOptions{FailOnAmbiguous: true})
+	assert.NotNil(t, err)
+	_, err = toolbox.NormalizeTimestamp("not a time", layout, time.UTC)
+	assert.NotNil(t, err)
+}
+
+func TestNormalizeTimestampColumn(t *testing.T) {
+	options := &toolbox.NormalizeTimestampOptions{FailOnAmbiguous: true}
+	var records = []map[string]interface{}{
+		{"id": 1, "ts": "2021-03-07T14:05:09Z"},
+		{"id": 2, "ts": int64(1615125909000)},
+		{"id": 3, "ts": "03/04/2021"},
+		{"id": 4, "ts": "25/04/2021"},
+		{"id": 5, "ts": nil},
+		{"id": 6},
+		{"id": 7, "ts": 1615125909.5},
+	}
+	err := toolbox.NormalizeTimestampColumn(records, "ts", "2006-01-02", time.UTC, options)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "2021-03-07", records[0]["ts"])
+		assert.Equal(t, "2021-03-07", records[1]["ts"])
+		assert.Equal(t, "2021-04-03", records[2]["ts"])
+		assert.Equal(t, "2021-04-25", records[3]["ts"])
+		assert.Nil(t, records[4]["ts"])
+		_, ok := records[5]["ts"]
+		assert.False(t, ok)
+		assert.Equal(t, "2021-03-07", records[6]["ts"])
+	}
+
+	var ambiguous = []map[string]interface{}{
+		{"ts": "03/04/2021"},
+		{"ts": "05/06/2021"},
+	}
+	assert.NotNil(t, toolbox.NormalizeTimestampColumn(ambiguous, "ts", "2006-01-02", time.UTC, options))
+	assert.Equal(t, "03/04/2021", ambiguous[0]["ts"])
+	assert.Nil(t, toolbox.NormalizeTimestampColumn(ambiguous, "ts", "2006-01-02", time.UTC))
+	assert.Equal(t, "2021-03-04", ambiguous[0]["ts"])
+}
+
+func TestRegisterTimeLayout(t *testing.T) {
+	_, ok := toolbox.DetectTimeLayout("2021|03|07")
+	assert.False(t, ok)
+	toolbox.RegisterTimeLayout("2006|01|02")
+	layout, ok := toolbox.DetectTimeLayout("2021|03|07")
+	assert.True(t, ok)
+	assert.Equal(t, "2006|01|02", layout)
+}
+
+func TestToTime(t *testing.T) {
+	expected := time.Date(2021, 3, 7, 14, 5, 9, 0, time.UTC)
+	var useCases = []struct {
+		description string
+		value       interface{}
+		layout      string
+		expected    time.Time
+	}{
+		{"time", expected, "", expected},
+		{"time pointer", &expected, "", expected},
+		{"layout", "2021/03/07 14:05:09", "2006/01/02 15:04:05", expected},
+		{"default layout", "2021-03-07 14:05:09.000", "", expected},
+		{"RFC3339", "2021-03-07T14:05:09Z", "", expected},
+		{"detected layout", "Sun, 07 Mar 2021 14:05:09 +0000", "", expected},
+		{"epoch seconds", int64(1615125909), "", expected},
+		{"epoch millis", int64(1615125909123), "", expected.Add(123 * time.Millisecond)},
+		{"epoch micros", int64(1615125909123456), "", expected.Add(123456 * time.Microsecond)},
+		{"epoch nanos", int64(1615125909123456789), "", expected.Add(123456789 * time.Nanosecond)},
+		{"epoch uint", uint32(1615125909), "", expected},
+		{"epoch float", float64(1615125909123), "", expected.Add(123 * time.Millisecond)},
+		{"epoch float fraction", 1615125909.5, "", expected.Add(500 * time.Millisecond)},
+		{"epoch text", "1615125909", "", expected},
+		{"json number", json.Number("1615125909"), "", expected},
+		{"json number millis", json.Number("1615125909123"), "", expected.Add(123 * time.Millisecond)},
+		{"json number fraction", json.Number("1615125909.25"), "", expected.Add(250 * time.Millisecond)},
+	}
+	for _, useCase := range useCases {
+		actual, err := toolbox.ToTime(useCase.value, useCase.layout)
+		if assert.Nil(t, err, useCase.description) {
+			assert.True(t, useCase.expected.Equal(*actual), fmt.Sprintf("%v: %v", useCase.description, actual))
+		}
+	}
+
+	var decoded map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{"created":1615125909, "updated":1615125909123}`))
+	decoder.UseNumber()
+	assert.Nil(t, decoder.Decode(&decoded))
+	created, err := toolbox.ToTime(decoded["created"], "")
+	if assert.Nil(t, err) {
+		assert.True(t, expected.Equal(*created))
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"updated":1615125909123}`), &decoded))
+	updated, err := toolbox.ToTime(decoded["updated"], "")
+	if assert.Nil(t, err) {
+		assert.True(t, expected.Add(123*time.Millisecond).Equal(*updated))
+	}
+
+	_, err = toolbox.ToTime("03/04/2021", "")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), `"03/04/2021"`), err.Error())
+		assert.True(t, strings.Contains(err.Error(), "attempted layouts"), err.Error())
+	}
+	_, err = toolbox.ToTime("yesterday-ish", "2006-01-02")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "2006-01-02"), err.Error())
+	}
+	_, err = toolbox.ToTime(nil, "")
+	assert.NotNil(t, err)
+}
+
+func TestParseTimeInLocation(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if !assert.Nil(t, err) {
+		return
+	}
+	{ //location name
+		timeValue, err := toolbox.ParseTimeInLocation("2023-06-01 12:00:00", "2006-01-02 15:04:05", "Europe/Warsaw")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC), timeValue.UTC())
+		timeValue, err = toolbox.ParseTimeInLocation("2023-06-01 12:00:00", "2006-01-02 15:04:05", "")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC), timeValue.UTC())
+		_, err = toolbox.ParseTimeInLocation("2023-06-01 12:00:00", "2006-01-02 15:04:05", "Europe/Atlantis")
+		assert.NotNil(t, err)
+	}
+	{ //java VV zone id
+		layout := toolbox.DateFormatToLayout("yyyy-MM-dd HH:mm:ss VV")
+		assert.Equal(t, "2006-01-02 15:04:05 VV", layout)
+		assert.Equal(t, "yyyy-MM-dd HH:mm:ss VV", toolbox.LayoutToDateFormat(layout))
+		timeValue, err := toolbox.ParseTimeInLocation("2023-06-01 12:00:00 Europe/Warsaw", layout, "America/New_York")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC), timeValue.UTC())
+		assert.Equal(t, "Europe/Warsaw", timeValue.Location().String())
+
+		timeValue, err = toolbox.ParseTimeInLocation("America/New_York 2023-01-15T08:30", toolbox.DateFormatToLayout("VV yyyy-MM-dd'T'HH:mm"), "")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, 1, 15, 13, 30, 0, 0, time.UTC), timeValue.UTC())
+
+		_, err = toolbox.ParseTimeInLocation("2023-06-01 12:00:00 Mars/Olympus", layout, "")
+		assert.NotNil(t, err)
+	}
+	{ //DST transitions follow time.ParseInLocation
+		layout := "2006-01-02 15:04"
+		skipped, err := toolbox.ParseTimeInLocation("2023-03-26 02:30", layout, "Europe/Warsaw")
+		assert.Nil(t, err)
+		expected, _ := time.ParseInLocation(layout, "2023-03-26 02:30", warsaw)
+		assert.True(t, expected.Equal(skipped))
+		assert.Equal(t, time.Date(2023, 3, 26, 2, 30, 0, 0, warsaw), skipped)
+
+		ambiguous, err := toolbox.ParseTimeInLocation("2023-10-29 02:30", layout, "Europe/Warsaw")
+		assert.Nil(t, err)
+		expected, _ = time.ParseInLocation(layout, "2023-10-29 02:30", warsaw)
+		assert.True(t, expected.Equal(ambiguous))
+		_, offset := ambiguous.Zone()
+		assert.True(t, offset == 3600 || offset == 7200, fmt.Sprintf("offset %v", offset))
+
+		before, _ := toolbox.ParseTimeInLocation("2023-10-29 01:30", layout, "Europe/Warsaw")
+		after, _ := toolbox.ParseTimeInLocation("2023-10-29 03:30", layout, "Europe/Warsaw")
+		assert.Equal(t, 3*time.Hour, after.Sub(before))
+	}
+}
+
+func TestStrftimeToLayout(t *testing.T) {
+	timeValue := time.Date(2021, 3, 7, 14, 5, 9, 123456789, time.FixedZone("PST", -8*3600))
+	var useCases = []struct {
+		format    string
+		layout    string
+		formatted string
+	}{
+		{"%Y-%m-%dT%H:%M:%S%z", "2006-01-02T15:04:05-0700", "2021-03-07T14:05:09-0800"},
+		{"%y%m%d", "060102", "210307"},
+		{"%e %b %Y", "_2 Jan 2006", " 7 Mar 2021"},
+		{"%I:%M %p", "03:04 PM", "02:05 PM"},
+		{"%a, %d %b %Y %H:%M:%S %Z", "Mon, 02 Jan 2006 15:04:05 MST", "Sun, 07 Mar 2021 14:05:09 PST"},
+		{"%A %B %d", "Monday January 02", "Sunday March 07"},
+		{"%j", "002", "066"},
+		{"%H:%M:%S.%f", "15:04:05.000000", "14:05:09.123456"},
+		{"%H%%", "15%", "14%"},
+		{"%F %T", "2006-01-02 15:04:05", "2021-03-07 14:05:09"},
+		{"%D %R", "01/02/06 15:04", "03/07/21 14:05"},
+	}
+	for _, useCase := range useCases {
+		layout, err := toolbox.StrftimeToLayout(useCase.format)
+		if !assert.Nil(t, err, useCase.format) {
+			continue
+		}
+		assert.Equal(t, useCase.layout, layout, useCase.format)
+		assert.Equal(t, useCase.formatted, timeValue.Format(layout), useCase.format)
+	}
+
+	for _, format := range []string{"%Y-%m-%d %Q", "%Y%", "%f"} {
+		_, err := toolbox.StrftimeToLayout(format)
+		assert.NotNil(t, err, format)
+	}
+	_, err := toolbox.StrftimeToLayout("%Y %U")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "%U"), err.Error())
+	}
+
+	layout, err := toolbox.TryGetTimeLayout(map[string]string{toolbox.StrftimeFormatKeyword: "%Y/%m/%d"})
+	assert.Nil(t, err)
+	assert.Equal(t, "2006/01/02", layout)
+	_, err = toolbox.TryGetTimeLayout(map[string]string{toolbox.StrftimeFormatKeyword: "%Q"})
+	assert.NotNil(t, err)
+}
+
+func TestLayoutToStrftime(t *testing.T) {
+	var formats = []string{
+		"%Y-%m-%dT%H:%M:%S%z",
+		"%y%m%d",
+		"%e %b %Y",
+		"%I:%M %p",
+		"%a, %d %b %Y %H:%M:%S %Z",
+		"%A %B %d",
+		"%j",
+		"%H:%M:%S.%f",
+		"%H%%",
+	}
+	for _, format := range formats {
+		layout, err := toolbox.StrftimeToLayout(format)
+		assert.Nil(t, err, format)
+		actual, err := toolbox.LayoutToStrftime(layout)
+		assert.Nil(t, err, layout)
+		assert.Equal(t, format, actual, layout)
+	}
+	actual, err := toolbox.LayoutToStrftime(time.RFC1123Z)
+	assert.Nil(t, err)
+	assert.Equal(t, "%a, %d %b %Y %H:%M:%S %z", actual)
+
+	for _, layout := range []string{time.RFC3339, time.Kitchen, "15:04:05.000", "2006-1-2"} {
+		_, err := toolbox.LayoutToStrftime(layout)
+		assert.NotNil(t, err, layout)
+	}
+}
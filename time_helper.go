@@ -3,6 +3,8 @@ package toolbox
 import (
 	"fmt"
 	"log"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -11,18 +13,23 @@ const (
 	Now       = "now"
 	Tomorrow  = "tomorrow"
 	Yesterday = "yesterday"
+	Today     = "today"
 
 	//TimeAtTwoHoursAgo   = "2hoursAgo"
 	//TimeAtHourAhead     = "hourAhead"
 	//TimeAtTwoHoursAhead = "2hoursAhead"
 
+	DurationYear            = "year"
+	DurationMonth           = "month"
 	DurationWeek            = "week"
 	DurationDay             = "day"
 	DurationHour            = "hour"
+	DurationHourAbbr        = "h"
 	DurationMinute          = "minute"
 	DurationMinuteAbbr      = "min"
 	DurationSecond          = "second"
 	DurationSecondAbbr      = "sec"
+	DurationSecondShortAbbr = "s"
 	DurationMillisecond     = "millisecond"
 	DurationMillisecondAbbr = "ms"
 	DurationMicrosecond     = "microsecond"
@@ -178,11 +185,11 @@ func NewDuration(value int, unit string) (time.Duration, error) {
 		duration = time.Hour * 24 * 7
 	case DurationDay:
 		duration = time.Hour * 24
-	case DurationHour:
+	case DurationHour, DurationHourAbbr:
 		duration = time.Hour
 	case DurationMinute, DurationMinuteAbbr:
 		duration = time.Minute
-	case DurationSecond, DurationSecondAbbr:
+	case DurationSecond, DurationSecondAbbr, DurationSecondShortAbbr:
 		duration = time.Second
 	case DurationMillisecond, DurationMillisecondAbbr:
 		duration = time.Millisecond
@@ -196,6 +203,160 @@ func NewDuration(value int, unit string) (time.Duration, error) {
 	return time.Duration(value) * duration, nil
 }
 
+//extendedDurationUnits represents ParseExtendedDuration units, mo is approximated as 30 days
+var extendedDurationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour,
+}
+
+//isPlainNumber returns true if input is digits[.digits] number without sign, exponent, NaN or Inf
+func isPlainNumber(input string) bool {
+	var digits, fraction = 0, -1
+	for i := 0; i < len(input); i++ {
+		switch {
+		case '0' <= input[i] && input[i] <= '9':
+			if fraction >= 0 {
+				fraction++
+			} else {
+				digits++
+			}
+		case input[i] == '.' && fraction < 0 && digits > 0:
+			fraction = 0
+		default:
+			return false
+		}
+	}
+	return digits > 0 && fraction != 0
+}
+
+//ParseExtendedDuration parses duration like time.ParseDuration, additionally supporting d (24h), w (7d), mo (30d approximation) units,
+//composite forms i.e. "1w2d12h" or "2d 3h 15m", and plain numbers interpreted with optional default unit
+func ParseExtendedDuration(text string, defaultUnit ...time.Duration) (time.Duration, error) {
+	input := strings.TrimSpace(text)
+	if input == "" {
+		return 0, fmt.Errorf("invalid duration: %q", text)
+	}
+	var isNegative = false
+	switch input[0] {
+	case '-':
+		isNegative = true
+		fallthrough
+	case '+':
+		input = strings.TrimSpace(input[1:])
+	}
+	if isPlainNumber(input) {
+		value, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q, %v", text, err)
+		}
+		if value != 0 && len(defaultUnit) == 0 {
+			return 0, fmt.Errorf("missing unit in duration: %q", text)
+		}
+		if value != 0 {
+			value *= float64(defaultUnit[0])
+		}
+		if isNegative {
+			value *= -1
+		}
+		if value >= math.MaxInt64 || value < math.MinInt64 {
+			return 0, fmt.Errorf("invalid duration: %q, overflow", text)
+		}
+		return time.Duration(value), nil
+	}
+	var result int64
+	for i := 0; i < len(input); {
+		if input[i] == ' ' {
+			i++
+			continue
+		}
+		start := i
+		for ; i < len(input) && '0' <= input[i] && input[i] <= '9'; i++ {
+		}
+		integer := input[start:i]
+		var fraction = ""
+		if i < len(input) && input[i] == '.' {
+			start = i
+			for i++; i < len(input) && '0' <= input[i] && input[i] <= '9'; i++ {
+			}
+			fraction = input[start:i]
+		}
+		start = i
+		for ; i < len(input) && input[i] != ' ' && input[i] != '.' && (input[i] < '0' || input[i] > '9'); i++ {
+		}
+		if integer == "" && len(fraction) < 2 {
+			return 0, fmt.Errorf("invalid duration: %q", text)
+		}
+		unit, ok := extendedDurationUnits[input[start:i]]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q in duration: %q", input[start:i], text)
+		}
+		value, _ := strconv.ParseInt("0"+integer, 10, 64)
+		if value > (math.MaxInt64-result)/int64(unit) {
+			return 0, fmt.Errorf("invalid duration: %q, overflow", text)
+		}
+		result += value * int64(unit)
+		if fraction != "" {
+			fractionValue, _ := strconv.ParseFloat("0"+fraction, 64)
+			result += int64(fractionValue * float64(unit))
+			if result < 0 {
+				return 0, fmt.Errorf("invalid duration: %q, overflow", text)
+			}
+		}
+	}
+	if isNegative {
+		result *= -1
+	}
+	return time.Duration(result), nil
+}
+
+//formatDurationUnits represents FormatDuration units in descending order
+var formatDurationUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+//FormatDuration returns human readable duration i.e. "2d 3h 15m" with up to maxUnits most significant units (all if maxUnits <= 0), remainder is truncated
+func FormatDuration(d time.Duration, maxUnits int) string {
+	if d == 0 {
+		return "0s"
+	}
+	var sign = ""
+	remainder := uint64(d)
+	if d < 0 {
+		sign = "-"
+		remainder = uint64(-d)
+	}
+	var fragments = make([]string, 0)
+	for _, candidate := range formatDurationUnits {
+		if maxUnits > 0 && len(fragments) == maxUnits {
+			break
+		}
+		value := remainder / uint64(candidate.unit)
+		if value == 0 {
+			continue
+		}
+		remainder -= value * uint64(candidate.unit)
+		fragments = append(fragments, fmt.Sprintf("%d%v", value, candidate.suffix))
+	}
+	return sign + strings.Join(fragments, " ")
+}
+
 const (
 	eofToken     = -1
 	invalidToken = iota
@@ -210,16 +371,32 @@ const (
 	positiveModifierToken
 	negativeModifierToken
 	timezoneToken
+	todayToken
+	weekdayToken
+	startOfToken
+	endOfToken
+	plusToken
+	minusToken
+	atToken
+	clockToken
 )
 
 var timeAtExpressionMatchers = map[int]Matcher{
 	timeValueToken:        NewIntMatcher(),
 	whitespacesToken:      CharactersMatcher{" \n\t"},
-	durationToken:         NewKeywordsMatcher(false, DurationWeek, DurationDay, DurationHour, DurationMinute, DurationMinuteAbbr, DurationSecond, DurationSecondAbbr, DurationMillisecond, DurationMillisecondAbbr, DurationMicrosecond, DurationMicrosecondAbbr, DurationNanosecond, DurationNanosecondAbbr),
+	durationToken:         NewKeywordsMatcher(false, DurationMillisecond, DurationMicrosecond, DurationNanosecond, DurationMinute, DurationMonth, DurationSecond, DurationWeek, DurationDay, DurationHour, DurationYear, DurationMinuteAbbr, DurationSecondAbbr, DurationMillisecondAbbr, DurationMicrosecondAbbr, DurationNanosecondAbbr, DurationHourAbbr, DurationSecondShortAbbr),
 	durationPluralToken:   NewKeywordsMatcher(false, "s"),
 	nowToken:              NewKeywordsMatcher(false, Now),
+	todayToken:            NewKeywordsMatcher(false, Today),
 	yesterdayToken:        NewKeywordsMatcher(false, Yesterday),
 	tomorrowToken:         NewKeywordsMatcher(false, Tomorrow),
+	weekdayToken:          NewKeywordsMatcher(false, "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"),
+	startOfToken:          NewKeywordsMatcher(false, "start of", "startof", "beginning of"),
+	endOfToken:            NewKeywordsMatcher(false, "end of", "endof"),
+	plusToken:             NewKeywordsMatcher(false, "+"),
+	minusToken:            NewKeywordsMatcher(false, "-"),
+	atToken:               NewKeywordsMatcher(false, "at"),
+	clockToken:            CharactersMatcher{"0123456789:"},
 	positiveModifierToken: NewKeywordsMatcher(false, "onward", "ahead", "after", "later", "in the future", "inthefuture"),
 	negativeModifierToken: NewKeywordsMatcher(false, "past", "ago", "before", "earlier", "in the past", "inthepast"),
 	inTimezoneToken:       NewKeywordsMatcher(false, "in"),
@@ -227,77 +404,222 @@ var timeAtExpressionMatchers = map[int]Matcher{
 	eofToken:              &EOFMatcher{},
 }
 
-//TimeAt returns time of now supplied offsetExpression, this function uses TimeDiff
-func TimeAt(offsetExpression string) (*time.Time, error) {
-	return TimeDiff(time.Now(), offsetExpression)
+//timeAtStep represents a single time expression transformation
+type timeAtStep func(t time.Time) time.Time
+
+//TimeAt returns time for supplied expression relative to now or optional base time, this function uses TimeDiff
+func TimeAt(expression string, base ...time.Time) (*time.Time, error) {
+	if len(base) > 0 {
+		return TimeDiff(base[0], expression)
+	}
+	return TimeDiff(time.Now(), expression)
 }
 
-//TimeDiff returns time for supplied base time and literal, the supported literal now, yesterday, tomorrow, or the following template:
-// 	- [timeValueToken]  durationToken past_or_future_modifier [IN tz]
+//TimeDiff returns time for supplied base time and expression, the expression is a sequence of the following steps applied in order:
+// 	- anchors: now, today, yesterday, tomorrow, monday..sunday (the nearest upcoming weekday, today inclusive)
+// 	- truncation: start of|end of day|week|month|year (week starts on monday)
+// 	- offsets: +|- [timeValueToken] durationToken or [timeValueToken] durationToken past_or_future_modifier
+// 	- clock: at HH[:MM[:SS]]
+// optionally followed by IN tz, i.e. "now - 3 days", "tomorrow at 14:00", "start of month", "2 hours ago in UTC"
 // where time modifier can be any of the following:  "onward", "ahead", "after", "later", or "past", "ago", "before", "earlier", "in the future", "in the past") )
 func TimeDiff(base time.Time, expression string) (*time.Time, error) {
 	if expression == "" {
 		return nil, fmt.Errorf("expression was empty")
 	}
-	var delta time.Duration
-	var isNegative = false
-
 	tokenizer := NewTokenizer(expression, invalidToken, eofToken, timeAtExpressionMatchers)
-	var val = 1
-	var isTimeExtracted = false
-	token, err := ExpectToken(tokenizer, "", timeValueToken, nowToken, yesterdayToken, tomorrowToken)
-	if err == nil {
+	tokenizer.SetSkipTokens(whitespacesToken)
+	var steps = make([]timeAtStep, 0)
+	for {
+		token, err := ExpectToken(tokenizer, "expected time anchor, offset or eofToken", nowToken, todayToken, yesterdayToken, tomorrowToken, weekdayToken, startOfToken, endOfToken, plusToken, minusToken, timeValueToken, durationToken, atToken, inTimezoneToken, eofToken)
+		if err != nil {
+			return nil, err
+		}
 		switch token.Token {
-		case timeValueToken:
-			val, _ = ToInt(token.Matched)
+		case nowToken, todayToken:
 		case yesterdayToken:
-			isNegative = true
-			fallthrough
+			steps = append(steps, addDateStep(0, 0, -1))
 		case tomorrowToken:
-			delta, _ = NewDuration(1, DurationDay)
-			fallthrough
-		case nowToken:
-			isTimeExtracted = true
+			steps = append(steps, addDateStep(0, 0, 1))
+		case weekdayToken:
+			steps = append(steps, weekdayStep(strings.ToLower(token.Matched)))
+		case startOfToken, endOfToken:
+			step, err := truncateStep(tokenizer, token.Token == endOfToken)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		case plusToken, minusToken:
+			step, err := offsetStep(tokenizer, nil, token.Token == minusToken)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		case timeValueToken, durationToken:
+			step, err := offsetStep(tokenizer, token, false)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		case atToken:
+			step, err := clockStep(tokenizer)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		case inTimezoneToken:
+			if token, err = ExpectToken(tokenizer, "expected timezone", timezoneToken); err != nil {
+				return nil, err
+			}
+			tz := strings.TrimSpace(token.Matched)
+			tzLocation, err := time.LoadLocation(tz)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load timezone tzLocation: %v, %v", tz, err)
+			}
+			base = base.In(tzLocation)
+			if _, err = ExpectToken(tokenizer, "expected eofToken", eofToken); err != nil {
+				return nil, err
+			}
+			return applyTimeAtSteps(base, steps), nil
+		case eofToken:
+			return applyTimeAtSteps(base, steps), nil
 		}
 	}
+}
 
-	if !isTimeExtracted {
-		token, err = ExpectTokenOptionallyFollowedBy(tokenizer, whitespacesToken, "expected time unit", durationToken)
-		if err != nil {
-			return nil, err
+func applyTimeAtSteps(base time.Time, steps []timeAtStep) *time.Time {
+	for _, step := range steps {
+		base = step(base)
+	}
+	return &base
+}
+
+//offsetStep parses offset with optional value token, unsigned offsets require past or future modifier
+func offsetStep(tokenizer *Tokenizer, token *Token, isNegative bool) (timeAtStep, error) {
+	var value = 1
+	var isSigned = token == nil
+	var err error
+	if token == nil || token.Token == timeValueToken {
+		if token == nil {
+			token, _ = ExpectOptionalToken(tokenizer, timeValueToken)
 		}
-		delta, _ = NewDuration(val, strings.ToLower(token.Matched))
-		_, _ = ExpectToken(tokenizer, "", durationPluralToken)
-		token, err = ExpectTokenOptionallyFollowedBy(tokenizer, whitespacesToken, "expected time modifier", positiveModifierToken, negativeModifierToken)
-		if err != nil {
-			return nil, err
+		if token != nil {
+			value, _ = ToInt(token.Matched)
 		}
-		if token.Token == negativeModifierToken {
-			isNegative = true
+		if token, err = ExpectToken(tokenizer, "expected time unit", durationToken); err != nil {
+			return nil, err
 		}
 	}
-
-	if token, err = ExpectTokenOptionallyFollowedBy(tokenizer, whitespacesToken, "expected in", inTimezoneToken); err == nil {
-		token, err = ExpectToken(tokenizer, "epected timezone", timezoneToken)
+	unit := strings.ToLower(token.Matched)
+	_, _ = ExpectOptionalToken(tokenizer, durationPluralToken)
+	if !isSigned {
+		modifier, err := ExpectToken(tokenizer, "expected time modifier", positiveModifierToken, negativeModifierToken)
 		if err != nil {
 			return nil, err
 		}
-		tz := strings.TrimSpace(token.Matched)
-		tzLocation, err := time.LoadLocation(tz)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load timezone tzLocation: %v, %v", tz, err)
+		isNegative = modifier.Token == negativeModifierToken
+	}
+	if isNegative {
+		value *= -1
+	}
+	switch unit {
+	case DurationYear:
+		return addDateStep(value, 0, 0), nil
+	case DurationMonth:
+		return addDateStep(0, value, 0), nil
+	case DurationWeek:
+		return addDateStep(0, 0, 7*value), nil
+	case DurationDay:
+		return addDateStep(0, 0, value), nil
+	}
+	delta, err := NewDuration(value, unit)
+	if err != nil {
+		return nil, NewIllegalTokenError(err.Error(), []int{durationToken}, token.Offset, token, tokenizer)
+	}
+	return func(t time.Time) time.Time {
+		return t.Add(delta)
+	}, nil
+}
+
+//truncateStep parses start of or end of period
+func truncateStep(tokenizer *Tokenizer, isEnd bool) (timeAtStep, error) {
+	token, err := ExpectToken(tokenizer, "expected day, week, month or year", durationToken)
+	if err != nil {
+		return nil, err
+	}
+	var years, months, days int
+	period := strings.ToLower(token.Matched)
+	switch period {
+	case DurationDay:
+		days = 1
+	case DurationWeek:
+		days = 7
+	case DurationMonth:
+		months = 1
+	case DurationYear:
+		years = 1
+	default:
+		return nil, NewIllegalTokenError("expected day, week, month or year", []int{durationToken}, token.Offset, token, tokenizer)
+	}
+	return func(t time.Time) time.Time {
+		start := truncateTime(t, period)
+		if isEnd {
+			return start.AddDate(years, months, days).Add(-time.Nanosecond)
 		}
-		base = base.In(tzLocation)
+		return start
+	}, nil
+}
+
+//truncateTime returns start of day, week (starting on monday), month or year period for supplied time in its location
+func truncateTime(t time.Time, period string) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case DurationWeek:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	case DurationMonth:
+		start = start.AddDate(0, 0, 1-start.Day())
+	case DurationYear:
+		start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
 	}
-	token, err = ExpectToken(tokenizer, "expected eofToken", eofToken)
+	return start
+}
+
+//clockStep parses at HH[:MM[:SS]] clock time
+func clockStep(tokenizer *Tokenizer) (timeAtStep, error) {
+	token, err := ExpectToken(tokenizer, "expected clock time", clockToken)
 	if err != nil {
 		return nil, err
 	}
-	if isNegative {
-		delta *= -1
+	var clock time.Time
+	for _, layout := range []string{"15:04:05", "15:04", "15"} {
+		if clock, err = time.Parse(layout, token.Matched); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, NewIllegalTokenError("invalid clock time", []int{clockToken}, token.Offset, token, tokenizer)
+	}
+	return func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, t.Location())
+	}, nil
+}
+
+//weekdayStep moves time to the nearest upcoming weekday, or keeps it if it is already that weekday
+func weekdayStep(name string) timeAtStep {
+	var weekday time.Weekday
+	for candidate := time.Sunday; candidate <= time.Saturday; candidate++ {
+		if strings.EqualFold(candidate.String(), name) {
+			weekday = candidate
+		}
+	}
+	return func(t time.Time) time.Time {
+		return t.AddDate(0, 0, (int(weekday)-int(t.Weekday())+7)%7)
+	}
+}
+
+func addDateStep(years, months, days int) timeAtStep {
+	return func(t time.Time) time.Time {
+		return t.AddDate(years, months, days)
 	}
-	base = base.Add(delta)
-	return &base, nil
 }
 
 //ElapsedToday returns elapsed today time percent, it takes optionally timezone
@@ -330,6 +652,8 @@ func RemainingToday(tz string) (float64, error) {
 
 //TimeWindow represents a time window
 type TimeWindow struct {
+	Start      time.Time
+	End        time.Time
 	Loopback   *Duration
 	StartDate  string
 	startTime  *time.Time
@@ -387,6 +711,9 @@ func (w *TimeWindow) Layout() string {
 
 //StartTime returns time window start time
 func (w *TimeWindow) StartTime() (*time.Time, error) {
+	if !w.Start.IsZero() {
+		return &w.Start, nil
+	}
 	if w.StartDate != "" {
 		if w.startTime != nil {
 			return w.startTime, nil
@@ -416,6 +743,9 @@ func (w *TimeWindow) StartTime() (*time.Time, error) {
 
 //EndTime returns time window end time
 func (w *TimeWindow) EndTime() (*time.Time, error) {
+	if !w.End.IsZero() {
+		return &w.End, nil
+	}
 	if w.EndDate != "" {
 		if w.endTime != nil {
 			return w.endTime, nil
@@ -431,3 +761,68 @@ func (w *TimeWindow) EndTime() (*time.Time, error) {
 	now := time.Now()
 	return &now, nil
 }
+
+//timeWindowIterator represents consecutive time windows iterator
+type timeWindowIterator struct {
+	next    time.Time
+	to      time.Time
+	advance func(t time.Time) time.Time
+}
+
+//HasNext returns true if there is a window before iterator end time
+func (i *timeWindowIterator) HasNext() bool {
+	return i.next.Before(i.to)
+}
+
+//Next sets *TimeWindow, **TimeWindow or *interface{} item pointer with next window, the last window is truncated to iterator end time
+func (i *timeWindowIterator) Next(itemPointer interface{}) error {
+	if !i.HasNext() {
+		return fmt.Errorf("no more time windows")
+	}
+	window := &TimeWindow{Start: i.next, End: i.advance(i.next)}
+	if window.End.After(i.to) {
+		window.End = i.to
+	}
+	i.next = window.End
+	switch actual := itemPointer.(type) {
+	case *TimeWindow:
+		*actual = *window
+	case **TimeWindow:
+		*actual = window
+	case *interface{}:
+		*actual = window
+	default:
+		return fmt.Errorf("unsupported item pointer type: %T, expected %T", itemPointer, window)
+	}
+	return nil
+}
+
+func (i *timeWindowIterator) safe() {}
+
+//NewTimeWindowIterator returns an iterator of consecutive step long time windows between from and to
+func NewTimeWindowIterator(from, to time.Time, step time.Duration) (Iterator, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid time window step: %v", step)
+	}
+	return &timeWindowIterator{next: from, to: to, advance: func(t time.Time) time.Time {
+		return t.Add(step)
+	}}, nil
+}
+
+//NewCalendarWindowIterator returns an iterator of time windows between from and to aligned to day, week (starting on monday) or month boundaries in from location
+func NewCalendarWindowIterator(from, to time.Time, unit string) (Iterator, error) {
+	var months, days int
+	switch unit {
+	case DurationDay:
+		days = 1
+	case DurationWeek:
+		days = 7
+	case DurationMonth:
+		months = 1
+	default:
+		return nil, fmt.Errorf("unsupported calendar window unit: %v", unit)
+	}
+	return &timeWindowIterator{next: from, to: to.In(from.Location()), advance: func(t time.Time) time.Time {
+		return truncateTime(t, unit).AddDate(0, months, days)
+	}}, nil
+}
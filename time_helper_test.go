@@ -344,6 +344,78 @@ func TestNewDuration(t *testing.T) {
 
 }
 
+func TestParseExtendedDuration(t *testing.T) {
+	var useCases = []struct {
+		description string
+		text        string
+		defaultUnit []time.Duration
+		expected    time.Duration
+		hasError    bool
+	}{
+		{description: "standard", text: "1h30m", expected: 90 * time.Minute},
+		{description: "days", text: "1d", expected: 24 * time.Hour},
+		{description: "weeks", text: "2w", expected: 14 * 24 * time.Hour},
+		{description: "months", text: "1mo", expected: 30 * 24 * time.Hour},
+		{description: "composite", text: "1w2d12h", expected: 9*24*time.Hour + 12*time.Hour},
+		{description: "composite with spaces", text: "2d 3h 15m", expected: 51*time.Hour + 15*time.Minute},
+		{description: "fraction", text: "1.5d", expected: 36 * time.Hour},
+		{description: "sub second", text: "1s250ms", expected: 1250 * time.Millisecond},
+		{description: "negative", text: "-1d12h", expected: -36 * time.Hour},
+		{description: "zero", text: "0", expected: 0},
+		{description: "plain number", text: "90", defaultUnit: []time.Duration{time.Second}, expected: 90 * time.Second},
+		{description: "plain negative number", text: "-2", defaultUnit: []time.Duration{24 * time.Hour}, expected: -48 * time.Hour},
+		{description: "plain number without unit", text: "90", hasError: true},
+		{description: "empty", text: "", hasError: true},
+		{description: "unknown unit", text: "3y", hasError: true},
+		{description: "missing value", text: "d", hasError: true},
+		{description: "trailing number", text: "1d3", hasError: true},
+		{description: "nonsense", text: "abc", hasError: true},
+		{description: "overflow", text: "100000000w", hasError: true},
+		{description: "plain fraction", text: "1.5", defaultUnit: []time.Duration{time.Second}, expected: 1500 * time.Millisecond},
+		{description: "plain nan", text: "nan", defaultUnit: []time.Duration{time.Second}, hasError: true},
+		{description: "plain inf", text: "-Inf", defaultUnit: []time.Duration{time.Second}, hasError: true},
+		{description: "plain exponent", text: "1e3", defaultUnit: []time.Duration{time.Second}, hasError: true},
+		{description: "double minus sign", text: "--5", defaultUnit: []time.Duration{time.Second}, hasError: true},
+		{description: "mixed signs", text: "-+5", defaultUnit: []time.Duration{time.Second}, hasError: true},
+		{description: "plain overflow", text: "3000000", defaultUnit: []time.Duration{time.Hour}, hasError: true},
+		{description: "plain negative overflow", text: "-3000000", defaultUnit: []time.Duration{time.Hour}, hasError: true},
+	}
+	for _, useCase := range useCases {
+		actual, err := ParseExtendedDuration(useCase.text, useCase.defaultUnit...)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expected, actual, useCase.description)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	var useCases = []struct {
+		description string
+		duration    time.Duration
+		maxUnits    int
+		expected    string
+	}{
+		{description: "zero", duration: 0, expected: "0s"},
+		{description: "all units", duration: 51*time.Hour + 15*time.Minute + 20*time.Second, expected: "2d 3h 15m 20s"},
+		{description: "max units", duration: 51*time.Hour + 15*time.Minute + 20*time.Second, maxUnits: 3, expected: "2d 3h 15m"},
+		{description: "skipped units", duration: 24*time.Hour + 5*time.Second, maxUnits: 2, expected: "1d 5s"},
+		{description: "sub second", duration: 1500 * time.Microsecond, expected: "1ms 500us"},
+		{description: "negative", duration: -90 * time.Minute, expected: "-1h 30m"},
+	}
+	for _, useCase := range useCases {
+		actual := FormatDuration(useCase.duration, useCase.maxUnits)
+		assert.Equal(t, useCase.expected, actual, useCase.description)
+		parsed, err := ParseExtendedDuration(actual)
+		if assert.Nil(t, err, useCase.description) && useCase.maxUnits == 0 {
+			assert.Equal(t, useCase.duration, parsed, useCase.description)
+		}
+	}
+}
+
 func TestIdMatcher_Match(t *testing.T) {
 	{
 		ts, err := TimeAt("1 sec ahead")
@@ -469,6 +541,58 @@ func TestTimeDiff(t *testing.T) {
 
 }
 
+func TestTimeAt(t *testing.T) {
+	base := time.Date(2021, 3, 17, 10, 30, 15, 0, time.UTC) //wednesday
+	var useCases = []struct {
+		description string
+		expression  string
+		expected    time.Time
+		errorAt     int
+	}{
+		{description: "now", expression: "now", expected: base},
+		{description: "signed offset", expression: "now - 3 days", expected: time.Date(2021, 3, 14, 10, 30, 15, 0, time.UTC)},
+		{description: "signed offset without space", expression: "now+2h", expected: time.Date(2021, 3, 17, 12, 30, 15, 0, time.UTC)},
+		{description: "yesterday", expression: "yesterday", expected: time.Date(2021, 3, 16, 10, 30, 15, 0, time.UTC)},
+		{description: "tomorrow at", expression: "tomorrow at 14:00", expected: time.Date(2021, 3, 18, 14, 0, 0, 0, time.UTC)},
+		{description: "today at", expression: "today at 9", expected: time.Date(2021, 3, 17, 9, 0, 0, 0, time.UTC)},
+		{description: "start of month", expression: "start of month", expected: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{description: "end of month", expression: "end of month", expected: time.Date(2021, 3, 31, 23, 59, 59, 999999999, time.UTC)},
+		{description: "start of week", expression: "start of week", expected: time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{description: "end of week", expression: "end of week", expected: time.Date(2021, 3, 21, 23, 59, 59, 999999999, time.UTC)},
+		{description: "start of day", expression: "start of day", expected: time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{description: "end of year", expression: "end of year", expected: time.Date(2021, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+		{description: "hours ago", expression: "2 hours ago", expected: time.Date(2021, 3, 17, 8, 30, 15, 0, time.UTC)},
+		{description: "month offset", expression: "now + 1 month", expected: time.Date(2021, 4, 17, 10, 30, 15, 0, time.UTC)},
+		{description: "year offset", expression: "1 year ago", expected: time.Date(2020, 3, 17, 10, 30, 15, 0, time.UTC)},
+		{description: "seconds offset", expression: "now + 45s", expected: time.Date(2021, 3, 17, 10, 31, 0, 0, time.UTC)},
+		{description: "weekday", expression: "friday at 08:15", expected: time.Date(2021, 3, 19, 8, 15, 0, 0, time.UTC)},
+		{description: "current weekday", expression: "wednesday", expected: base},
+		{description: "weekday next week", expression: "monday", expected: time.Date(2021, 3, 22, 10, 30, 15, 0, time.UTC)},
+		{description: "chained steps", expression: "start of month - 1 day", expected: time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{description: "timezone", expression: "start of day in America/New_York", expected: time.Date(2021, 3, 17, 4, 0, 0, 0, time.UTC)},
+		{description: "invalid anchor", expression: "now - 3 days later", errorAt: 13},
+		{description: "invalid unit", expression: "now - 3 fortnights", errorAt: 8},
+		{description: "invalid period", expression: "start of hour", errorAt: 9},
+		{description: "invalid clock", expression: "tomorrow at 25:00", errorAt: 12},
+	}
+	for _, useCase := range useCases {
+		actual, err := TimeAt(useCase.expression, base)
+		if useCase.errorAt > 0 {
+			if assert.NotNil(t, err, useCase.description) {
+				illegalTokenError, ok := err.(*IllegalTokenError)
+				if assert.True(t, ok, useCase.description) {
+					assert.Equal(t, useCase.errorAt, illegalTokenError.Position, useCase.description)
+				}
+			}
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.True(t, useCase.expected.Equal(*actual), "%v: %v", useCase.description, actual)
+	}
+}
+
 func TestDayElapsedInPct(t *testing.T) {
 
 	t0, _ := time.Parse(DateFormatToLayout("yyyy-MM-dd hh:mm:ss"), "2017-01-01 12:00:00")
@@ -547,6 +671,94 @@ func TestTimeWindow_Range(t *testing.T) {
 }
 
 
+func TestNewTimeWindowIterator(t *testing.T) {
+	from := time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC)
+	{ //exact multiple of the step
+		iterator, err := NewTimeWindowIterator(from, from.Add(6*time.Hour), 2*time.Hour)
+		if assert.Nil(t, err) {
+			var windows = make([]*TimeWindow, 0)
+			for iterator.HasNext() {
+				window := &TimeWindow{}
+				assert.Nil(t, iterator.Next(window))
+				windows = append(windows, window)
+			}
+			if assert.Equal(t, 3, len(windows)) {
+				assert.Equal(t, from, windows[0].Start)
+				assert.Equal(t, from.Add(2*time.Hour), windows[0].End)
+				assert.Equal(t, from.Add(6*time.Hour), windows[2].End)
+			}
+		}
+	}
+	{ //truncated last window
+		iterator, err := NewTimeWindowIterator(from, from.Add(6*time.Hour), 4*time.Hour)
+		if assert.Nil(t, err) {
+			var window *TimeWindow
+			var count = 0
+			for ; iterator.HasNext(); count++ {
+				assert.Nil(t, iterator.Next(&window))
+			}
+			assert.Equal(t, 2, count)
+			assert.Equal(t, from.Add(4*time.Hour), window.Start)
+			assert.Equal(t, from.Add(6*time.Hour), window.End)
+			assert.NotNil(t, iterator.Next(&window))
+		}
+	}
+	_, err := NewTimeWindowIterator(from, from.Add(time.Hour), 0)
+	assert.NotNil(t, err)
+	_, err = NewTimeWindowIterator(from, from.Add(time.Hour), -time.Minute)
+	assert.NotNil(t, err)
+}
+
+func TestNewCalendarWindowIterator(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Warsaw")
+	if !assert.Nil(t, err) {
+		return
+	}
+	{ //daily windows across DST change on 2021-03-28
+		iterator, err := NewCalendarWindowIterator(time.Date(2021, 3, 1, 0, 0, 0, 0, location), time.Date(2021, 4, 1, 0, 0, 0, 0, location), DurationDay)
+		if assert.Nil(t, err) {
+			var count = 0
+			for ; iterator.HasNext(); count++ {
+				window := &TimeWindow{}
+				assert.Nil(t, iterator.Next(window))
+				assert.Equal(t, 0, window.Start.Hour())
+				assert.Equal(t, 0, window.End.Hour())
+				if window.Start.Day() == 28 {
+					assert.Equal(t, 23*time.Hour, window.End.Sub(window.Start))
+				}
+			}
+			assert.Equal(t, 31, count)
+		}
+	}
+	{ //monthly windows with partial first and last month
+		iterator, err := NewCalendarWindowIterator(time.Date(2021, 1, 15, 12, 0, 0, 0, location), time.Date(2021, 3, 10, 0, 0, 0, 0, location), DurationMonth)
+		if assert.Nil(t, err) {
+			var windows = make([]*TimeWindow, 0)
+			for iterator.HasNext() {
+				var window *TimeWindow
+				assert.Nil(t, iterator.Next(&window))
+				windows = append(windows, window)
+			}
+			if assert.Equal(t, 3, len(windows)) {
+				assert.Equal(t, time.Date(2021, 2, 1, 0, 0, 0, 0, location), windows[0].End)
+				assert.Equal(t, time.Date(2021, 3, 1, 0, 0, 0, 0, location), windows[1].End)
+				assert.Equal(t, time.Date(2021, 3, 10, 0, 0, 0, 0, location), windows[2].End)
+			}
+		}
+	}
+	{ //weekly windows start on monday
+		iterator, err := NewCalendarWindowIterator(time.Date(2021, 3, 17, 0, 0, 0, 0, location), time.Date(2021, 3, 31, 0, 0, 0, 0, location), DurationWeek)
+		if assert.Nil(t, err) {
+			window := &TimeWindow{}
+			assert.Nil(t, iterator.Next(window))
+			assert.Equal(t, time.Monday, window.End.Weekday())
+		}
+	}
+	_, err = NewCalendarWindowIterator(time.Now(), time.Now(), DurationHour)
+	assert.NotNil(t, err)
+}
+
+
 func TestN(t *testing.T) {
 
 
@@ -2,8 +2,10 @@ package toolbox
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 //Matcher represents a matcher, that matches input from offset position, it returns number of characters matched.
@@ -16,50 +18,116 @@ type Matcher interface {
 type Token struct {
 	Token   int
 	Matched string
+	Offset  int //start offset of the matched input
+	Length  int //length of the matched input
+}
+
+//End returns end offset of the matched input
+func (t *Token) End() int {
+	return t.Offset + t.Length
 }
 
 //Tokenizer represents a token scanner.
 type Tokenizer struct {
 	matchers       map[int]Matcher
+	skipTokens     []int
 	Input          string
 	Index          int
 	InvalidToken   int
 	EndOfFileToken int
 }
 
-//Nexts matches the first of the candidates
+//SetSkipTokens sets tokens that are transparently skipped before matching candidates, i.e. whitespace or comments
+func (t *Tokenizer) SetSkipTokens(tokens ...int) {
+	for _, token := range tokens {
+		if _, ok := t.matchers[token]; !ok {
+			panic(fmt.Sprintf("failed to lookup matcher for %v", token))
+		}
+	}
+	t.skipTokens = tokens
+}
+
+//skip advances index past any skip tokens, skip tokens explicitly requested as candidates are not skipped
+func (t *Tokenizer) skip(candidates ...int) {
+	for matched := len(t.skipTokens) > 0; matched; {
+		matched = false
+	outer:
+		for _, skipToken := range t.skipTokens {
+			for _, candidate := range candidates {
+				if candidate == skipToken {
+					continue outer
+				}
+			}
+			if matchedSize := t.matchers[skipToken].Match(t.Input, t.Index); matchedSize > 0 {
+				t.Index += matchedSize
+				matched = true
+			}
+		}
+	}
+}
+
+//Fork returns an independent tokenizer sharing input, matchers and skip tokens, but with its own Index
+func (t *Tokenizer) Fork() *Tokenizer {
+	fork := *t
+	return &fork
+}
+
+//Sync adopts the fork position
+func (t *Tokenizer) Sync(fork *Tokenizer) {
+	t.Index = fork.Index
+}
+
+//Nexts matches the first of the candidates, once all input is consumed it returns EndOfFileToken
 func (t *Tokenizer) Nexts(candidates ...int) *Token {
+	t.skip(candidates...)
+	if !(t.Index < len(t.Input)) {
+		return &Token{Token: t.EndOfFileToken, Offset: t.Index}
+	}
+	offset := t.Index
 	for _, candidate := range candidates {
-		result := t.Next(candidate)
-		if result.Token != t.InvalidToken {
-			return result
-
+		if candidate == t.EndOfFileToken {
+			continue
+		}
+		if matchedSize := t.match(candidate); matchedSize > 0 {
+			t.Index += matchedSize
+			return &Token{Token: candidate, Matched: t.Input[offset : offset+matchedSize], Offset: offset, Length: matchedSize}
 		}
 	}
-	return &Token{t.InvalidToken, ""}
+	return &Token{Token: t.InvalidToken, Offset: t.Index}
 }
 
-//Next tries to match a candidate, it returns token if imatching is successful.
+//Peek matches the first of the candidates without advancing the tokenizer
+func (t *Tokenizer) Peek(candidates ...int) *Token {
+	index := t.Index
+	defer func() { t.Index = index }()
+	return t.Nexts(candidates...)
+}
+
+//Next tries to match a candidate, it returns token if imatching is successful, once all input is consumed it returns EndOfFileToken.
 func (t *Tokenizer) Next(candidate int) *Token {
+	t.skip(candidate)
 	offset := t.Index
 	if !(offset < len(t.Input)) {
-		return &Token{t.EndOfFileToken, ""}
+		return &Token{Token: t.EndOfFileToken, Offset: t.Index}
 	}
 
 	if candidate == t.EndOfFileToken {
-		return &Token{t.InvalidToken, ""}
+		return &Token{Token: t.InvalidToken, Offset: t.Index}
 	}
-	if matcher, ok := t.matchers[candidate]; ok {
-		matchedSize := matcher.Match(t.Input, offset)
-		if matchedSize > 0 {
-			t.Index = t.Index + matchedSize
-			return &Token{candidate, t.Input[offset : offset+matchedSize]}
-		}
+	if matchedSize := t.match(candidate); matchedSize > 0 {
+		t.Index = t.Index + matchedSize
+		return &Token{Token: candidate, Matched: t.Input[offset : offset+matchedSize], Offset: offset, Length: matchedSize}
+	}
+	return &Token{Token: t.InvalidToken, Offset: t.Index}
+}
 
-	} else {
+//match returns number of bytes matched by candidate matcher at the current index
+func (t *Tokenizer) match(candidate int) int {
+	matcher, ok := t.matchers[candidate]
+	if !ok {
 		panic(fmt.Sprintf("failed to lookup matcher for %v", candidate))
 	}
-	return &Token{t.InvalidToken, ""}
+	return matcher.Match(t.Input, t.Index)
 }
 
 //NewTokenizer creates a new NewTokenizer, it takes input, invalidToken, endOfFileToeken, and matchers.
@@ -84,15 +152,11 @@ func (m CharactersMatcher) Match(input string, offset int) int {
 	if offset >= len(input) {
 		return matched
 	}
-outer:
 	for _, r := range input[offset:] {
-		for _, candidate := range m.Chars {
-			if candidate == r {
-				matched++
-				continue outer
-			}
+		if !strings.ContainsRune(m.Chars, r) {
+			break
 		}
-		break
+		matched++
 	}
 	return matched
 }
@@ -102,15 +166,106 @@ func NewCharactersMatcher(chars string) Matcher {
 	return &CharactersMatcher{Chars: chars}
 }
 
+//CharsetMatcher represents a matcher, that matches runs of characters defined with a charset supporting ranges i.e. a-z0-9_-, leading ^ negates the charset
+type CharsetMatcher struct {
+	Charset string
+	negated bool
+	chars   map[rune]bool
+	ranges  [][2]rune
+}
+
+func (m *CharsetMatcher) isMatching(r rune) bool {
+	matching := m.chars[r]
+	if !matching {
+		for _, runeRange := range m.ranges {
+			if r >= runeRange[0] && r <= runeRange[1] {
+				matching = true
+				break
+			}
+		}
+	}
+	return matching != m.negated
+}
+
+//Match matches any characters defined in the charset, it returns number of bytes matched.
+func (m *CharsetMatcher) Match(input string, offset int) int {
+	var matched = 0
+	if offset >= len(input) {
+		return matched
+	}
+	for i, r := range input[offset:] {
+		if !m.isMatching(r) {
+			break
+		}
+		matched = i + utf8.RuneLen(r)
+	}
+	return matched
+}
+
+//NewCharsetMatcher creates a new charset matcher, '-' is matched literally when it is the first or the last character of the charset
+func NewCharsetMatcher(charset string) Matcher {
+	result := &CharsetMatcher{
+		Charset: charset,
+		chars:   make(map[rune]bool),
+		ranges:  make([][2]rune, 0),
+	}
+	runes := []rune(charset)
+	if len(runes) > 1 && runes[0] == '^' {
+		result.negated = true
+		runes = runes[1:]
+	}
+	for i := 0; i < len(runes); i++ {
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			from, to := runes[i], runes[i+2]
+			if from > to {
+				from, to = to, from
+			}
+			result.ranges = append(result.ranges, [2]rune{from, to})
+			i += 2
+			continue
+		}
+		result.chars[runes[i]] = true
+	}
+	return result
+}
+
+//WhitespaceMatcher represents a matcher that finds a run of unicode whitespace
+type WhitespaceMatcher struct {
+	IncludeNewlines bool //when false matching stops before \n or \r
+}
+
+//Match matches whitespace in the input, it returns number of bytes matched.
+func (m WhitespaceMatcher) Match(input string, offset int) int {
+	var matched = 0
+	if offset >= len(input) {
+		return matched
+	}
+	for i, r := range input[offset:] {
+		if !unicode.IsSpace(r) {
+			break
+		}
+		if !m.IncludeNewlines && (r == '\n' || r == '\r') {
+			break
+		}
+		matched = i + utf8.RuneLen(r)
+	}
+	return matched
+}
+
+//NewWhitespaceMatcher creates a new whitespace matcher, if includeNewlines is false new line characters are not matched,
+//so that they can be matched as a separate token
+func NewWhitespaceMatcher(includeNewlines bool) Matcher {
+	return &WhitespaceMatcher{IncludeNewlines: includeNewlines}
+}
+
 //EOFMatcher represents end of input matcher
+//
+//Deprecated: end of input is detected by Tokenizer itself, it returns EndOfFileToken once all input is consumed.
 type EOFMatcher struct {
 }
 
-//Match returns 1 if end of input has been reached otherwise 0
+//Match never consumes any input, it returns 0
 func (m EOFMatcher) Match(input string, offset int) int {
-	if offset+1 == len(input) {
-		return 1
-	}
 	return 0
 }
 
@@ -141,7 +296,9 @@ var dotRune = rune('.')
 var underscoreRune = rune('_')
 
 //LiteralMatcher represents a matcher that finds any literals in the input
-type LiteralMatcher struct{}
+type LiteralMatcher struct {
+	AllowDot bool //AllowDot accepts '.' after the first position, i.e. a.b is matched as a single literal
+}
 
 //Match matches a literal in the input, it returns number of character matched.
 func (m LiteralMatcher) Match(input string, offset int) int {
@@ -154,7 +311,7 @@ func (m LiteralMatcher) Match(input string, offset int) int {
 			if !unicode.IsLetter(r) {
 				break
 			}
-		} else if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == dotRune || r == underscoreRune) {
+		} else if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == underscoreRune || (m.AllowDot && r == dotRune)) {
 			break
 		}
 		matched++
@@ -162,28 +319,52 @@ func (m LiteralMatcher) Match(input string, offset int) int {
 	return matched
 }
 
-//LiteralMatcher represents a matcher that finds any literals in the input
-type IdMatcher struct{}
+//CustomLiteralMatcher represents a matcher that finds literals starting with a letter or any of StartChars, followed by letters, digits or any of BodyChars
+type CustomLiteralMatcher struct {
+	StartChars string //additional characters allowed at the first position
+	BodyChars  string //additional characters allowed after the first position
+}
 
-//Match matches a literal in the input, it returns number of character matched.
-func (m IdMatcher) Match(input string, offset int) int {
+//Match matches a literal in the input, it returns number of bytes matched.
+func (m *CustomLiteralMatcher) Match(input string, offset int) int {
 	var matched = 0
 	if offset >= len(input) {
 		return matched
 	}
 	for i, r := range input[offset:] {
 		if i == 0 {
-			if !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if !(unicode.IsLetter(r) || strings.ContainsRune(m.StartChars, r)) {
 				break
 			}
-		} else if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == dotRune || r == underscoreRune) {
+		} else if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(m.BodyChars, r)) {
 			break
 		}
-		matched++
+		matched = i + utf8.RuneLen(r)
 	}
 	return matched
 }
 
+//NewLiteralMatcher creates a new literal matcher with additional start and body characters, i.e. NewLiteralMatcher("_$", "_")
+func NewLiteralMatcher(startChars string, bodyChars string) Matcher {
+	return &CustomLiteralMatcher{StartChars: startChars, BodyChars: bodyChars}
+}
+
+var idMatcher = &CustomLiteralMatcher{StartChars: "0123456789", BodyChars: "_"}
+var dottedIdMatcher = &CustomLiteralMatcher{StartChars: "0123456789", BodyChars: "._"}
+
+//IdMatcher represents a matcher that finds any literals starting with a letter or digit in the input
+type IdMatcher struct {
+	AllowDot bool //AllowDot accepts '.' after the first position, i.e. a.b is matched as a single id
+}
+
+//Match matches a literal in the input, it returns number of character matched.
+func (m IdMatcher) Match(input string, offset int) int {
+	if m.AllowDot {
+		return dottedIdMatcher.Match(input, offset)
+	}
+	return idMatcher.Match(input, offset)
+}
+
 //SequenceMatcher represents a matcher that finds any sequence until find provided terminators
 type SequenceMatcher struct {
 	Terminators            []string
@@ -199,12 +380,10 @@ func (m *SequenceMatcher) hasTerminator(candidate string) bool {
 		if len(terminator) > candidateLength {
 			continue
 		}
-		if !m.CaseSensitive {
-			if strings.ToLower(terminator) == strings.ToLower(string(candidate[:terminatorLength])) {
-				return true
-			}
+		if candidate[:terminatorLength] == terminator {
+			return true
 		}
-		if terminator == string(candidate[:terminatorLength]) {
+		if !m.CaseSensitive && strings.EqualFold(candidate[:terminatorLength], terminator) {
 			return true
 		}
 	}
@@ -223,7 +402,7 @@ func (m *SequenceMatcher) Match(input string, offset int) int {
 	}
 	var i = 0
 	for ; i < len(input)-offset; i++ {
-		if m.hasTerminator(string(input[offset+i:])) {
+		if m.hasTerminator(input[offset+i:]) {
 			hasTerminator = true
 			break
 		}
@@ -348,30 +527,111 @@ func NewCustomIdMatcher(allowedChars ...string) Matcher {
 	return result
 }
 
-//LiteralMatcher represents a matcher that finds any literals in the input
+//DelimitedFieldMatcher represents a matcher that finds a single delimited field, either quoted one with doubled quote escaping (RFC 4180) or a raw one up to a delimiter or a new line
+type DelimitedFieldMatcher struct {
+	Delimiter byte
+	Quote     byte
+}
+
+//Match matches a field in the input, it returns number of bytes matched including closing quote, but excluding delimiter
+func (m *DelimitedFieldMatcher) Match(input string, offset int) (matched int) {
+	if offset >= len(input) {
+		return 0
+	}
+	if input[offset] == m.Quote {
+		for i := offset + 1; i < len(input); i++ {
+			if input[i] != m.Quote {
+				continue
+			}
+			if i+1 < len(input) && input[i+1] == m.Quote {
+				i++
+				continue
+			}
+			return i + 1 - offset
+		}
+		return 0
+	}
+	i := offset
+	for ; i < len(input); i++ {
+		if input[i] == m.Delimiter || input[i] == '\n' || input[i] == '\r' {
+			break
+		}
+	}
+	return i - offset
+}
+
+//NewDelimitedFieldMatcher creates a new delimited field matcher
+func NewDelimitedFieldMatcher(delimiter byte, quote byte) Matcher {
+	return &DelimitedFieldMatcher{Delimiter: delimiter, Quote: quote}
+}
+
+//SplitDelimitedFields splits a delimited record into unquoted fields, quoted fields may contain delimiters and new lines
+func SplitDelimitedFields(record string, delimiter byte, quote byte) ([]string, error) {
+	record = strings.TrimRight(record, "\r\n")
+	matcher := &DelimitedFieldMatcher{Delimiter: delimiter, Quote: quote}
+	var result = make([]string, 0)
+	var offset = 0
+	for {
+		matched := matcher.Match(record, offset)
+		if matched == 0 && offset < len(record) && record[offset] == quote {
+			return nil, fmt.Errorf("unterminated quoted field at %v", offset)
+		}
+		field := record[offset : offset+matched]
+		if matched > 0 && field[0] == quote {
+			doubleQuote := string([]byte{quote, quote})
+			field = strings.Replace(field[1:len(field)-1], doubleQuote, string(quote), -1)
+		}
+		result = append(result, field)
+		offset += matched
+		if offset >= len(record) {
+			break
+		}
+		if record[offset] != delimiter {
+			return nil, fmt.Errorf("expected delimiter at %v, but had: %q", offset, record[offset])
+		}
+		offset++
+	}
+	return result, nil
+}
+
+//BodyMatcher represents a matcher that finds a body enclosed with Begin and End, sequences enclosed with Quotes are skipped
 type BodyMatcher struct {
-	Begin string
-	End   string
+	Begin  string
+	End    string
+	Quotes []string
 }
 
 //Match matches a literal in the input, it returns number of character matched.
 func (m *BodyMatcher) Match(input string, offset int) (matched int) {
+	matched, _ = m.match(input, offset)
+	return matched
+}
+
+func (m *BodyMatcher) match(input string, offset int) (matched int, terminated bool) {
 	beginLen := len(m.Begin)
 	endLen := len(m.End)
 	uniEnclosed := m.Begin == m.End
 
 	if offset+beginLen >= len(input) {
-		return 0
+		return 0, false
 	}
 	if input[offset:offset+beginLen] != m.Begin {
-		return 0
+		return 0, false
 	}
 	var depth = 1
 	var i = 1
 	for ; i < len(input)-offset; i++ {
 		canCheckEnd := offset+i+endLen <= len(input)
 		if !canCheckEnd {
-			return 0
+			return 0, false
+		}
+		if quote := matchAnyPrefix(input, offset+i, m.Quotes); quote != "" {
+			quoteEnd := strings.Index(input[offset+i+len(quote):], quote)
+			if quoteEnd == -1 {
+				return 0, false
+			}
+			i += len(quote) + quoteEnd + len(quote) - 1
+			continue
 		}
 		if !uniEnclosed {
 			canCheckBegin := offset+i+beginLen <= len(input)
@@ -386,15 +646,116 @@ func (m *BodyMatcher) Match(input string, offset int) (matched int) {
 		}
 		if depth == 0 {
 			i += endLen
+			terminated = true
 			break
 		}
 	}
-	return i
+	return i, terminated
+}
+
+//NewBodyMatcher creates a new body matcher, sequences enclosed with optional quotes are skipped
+func NewBodyMatcher(begin, end string, quotes ...string) Matcher {
+	return &BodyMatcher{Begin: begin, End: end, Quotes: quotes}
+}
+
+//ExtractDelimitedFragment returns content enclosed with begin and end delimiters starting at offset and the offset just past the closing delimiter,
+//nested delimiters are balanced and sequences enclosed with quotes are skipped
+func ExtractDelimitedFragment(input string, offset int, begin, end string, quotes []string) (fragment string, endOffset int, err error) {
+	if offset < 0 || offset > len(input) {
+		return "", 0, fmt.Errorf("offset %v out of range [0, %v]", offset, len(input))
+	}
+	if !strings.HasPrefix(input[offset:], begin) {
+		return "", 0, fmt.Errorf("expected %q at %v, near: %v", begin, offset, illegalTokenContext(input, offset, IllegalTokenContextSize))
+	}
+	matcher := &BodyMatcher{Begin: begin, End: end, Quotes: quotes}
+	matched, terminated := matcher.match(input, offset)
+	if !terminated || matched < len(begin)+len(end) {
+		return "", 0, fmt.Errorf("unterminated fragment at %v, expected %q, near: %v", offset, end, illegalTokenContext(input, offset, IllegalTokenContextSize))
+	}
+	endOffset = offset + matched
+	return input[offset+len(begin) : endOffset-len(end)], endOffset, nil
+}
+
+//BalancedMatcher represents a matcher that finds balanced sequence of multiple delimiter pairs i.e. ( [ ] ), sequences enclosed with quotes are skipped
+type BalancedMatcher struct {
+	Pairs   map[string]string //opening to closing delimiter
+	Quotes  []string
+	openers []string
+	closers []string
+}
+
+func matchAnyPrefix(input string, offset int, candidates []string) string {
+	for _, candidate := range candidates {
+		if strings.HasPrefix(input[offset:], candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+//Match matches a balanced sequence in the input, it returns number of bytes matched or 0 if delimiters are not balanced or mismatched
+func (m *BalancedMatcher) Match(input string, offset int) (matched int) {
+	if offset >= len(input) {
+		return 0
+	}
+	opener := matchAnyPrefix(input, offset, m.openers)
+	if opener == "" {
+		return 0
+	}
+	var stack = []string{m.Pairs[opener]}
+	for i := offset + len(opener); i < len(input); {
+		if quote := matchAnyPrefix(input, i, m.Quotes); quote != "" {
+			end := strings.Index(input[i+len(quote):], quote)
+			if end == -1 {
+				return 0
+			}
+			i += len(quote) + end + len(quote)
+			continue
+		}
+		if expected := stack[len(stack)-1]; strings.HasPrefix(input[i:], expected) {
+			stack = stack[:len(stack)-1]
+			i += len(expected)
+			if len(stack) == 0 {
+				return i - offset
+			}
+			continue
+		}
+		if opener := matchAnyPrefix(input, i, m.openers); opener != "" {
+			stack = append(stack, m.Pairs[opener])
+			i += len(opener)
+			continue
+		}
+		if closer := matchAnyPrefix(input, i, m.closers); closer != "" {
+			return 0
+		}
+		i++
+	}
+	return 0
 }
 
-//NewBodyMatcher creates a new body matcher
-func NewBodyMatcher(begin, end string) Matcher {
-	return &BodyMatcher{Begin: begin, End: end}
+//NewBalancedMatcher creates a new balanced matcher for supplied opening to closing delimiter pairs and optional quotes
+func NewBalancedMatcher(pairs map[string]string, quotes ...string) Matcher {
+	result := &BalancedMatcher{
+		Pairs:   pairs,
+		Quotes:  quotes,
+		openers: make([]string, 0),
+		closers: make([]string, 0),
+	}
+	for opener, closer := range pairs {
+		result.openers = append(result.openers, opener)
+		result.closers = append(result.closers, closer)
+	}
+	byLengthDesc := func(candidates []string) func(i, j int) bool {
+		return func(i, j int) bool {
+			if len(candidates[i]) == len(candidates[j]) {
+				return candidates[i] < candidates[j]
+			}
+			return len(candidates[i]) > len(candidates[j])
+		}
+	}
+	sort.Slice(result.openers, byLengthDesc(result.openers))
+	sort.Slice(result.closers, byLengthDesc(result.closers))
+	return result
 }
 
 // Parses SQL Begin End blocks
@@ -513,7 +874,7 @@ func (m KeywordMatcher) Match(input string, offset int) (matched int) {
 			return len(m.Keyword)
 		}
 	} else {
-		if strings.ToLower(input[offset:offset+len(m.Keyword)]) == strings.ToLower(m.Keyword) {
+		if strings.EqualFold(input[offset:offset+len(m.Keyword)], m.Keyword) {
 			return len(m.Keyword)
 		}
 	}
@@ -537,7 +898,7 @@ func (m KeywordsMatcher) Match(input string, offset int) (matched int) {
 				return len(keyword)
 			}
 		} else {
-			if strings.ToLower(input[offset:offset+len(keyword)]) == strings.ToLower(keyword) {
+			if strings.EqualFold(input[offset:offset+len(keyword)], keyword) {
 				return len(keyword)
 			}
 		}
@@ -550,26 +911,66 @@ func NewKeywordsMatcher(caseSensitive bool, keywords ...string) Matcher {
 	return &KeywordsMatcher{CaseSensitive: caseSensitive, Keywords: keywords}
 }
 
+//IllegalTokenContextSize represents max number of characters before and after illegal token position included in the error context
+var IllegalTokenContextSize = 20
+
 //IllegalTokenError represents illegal token error
 type IllegalTokenError struct {
 	Illegal  *Token
 	Message  string
 	Expected []int
 	Position int
+	Context  string //input excerpt around position, position is marked with >>>
 }
 
 func (e *IllegalTokenError) Error() string {
-	return fmt.Sprintf("%v; illegal token at %v [%v], expected %v, but had: %v", e.Message, e.Position, e.Illegal.Matched, e.Expected, e.Illegal.Token)
+	result := fmt.Sprintf("%v; illegal token at %v [%v], expected %v, but had: %v", e.Message, e.Position, e.Illegal.Matched, e.Expected, e.Illegal.Token)
+	if e.Context != "" {
+		result += fmt.Sprintf(", near: %v", e.Context)
+	}
+	return result
 }
 
-//NewIllegalTokenError create a new illegal token error
-func NewIllegalTokenError(message string, expected []int, position int, found *Token) error {
-	return &IllegalTokenError{
+//NewIllegalTokenError create a new illegal token error, if tokenizer is supplied error context is populated from its input
+func NewIllegalTokenError(message string, expected []int, position int, found *Token, tokenizer ...*Tokenizer) error {
+	result := &IllegalTokenError{
 		Message:  message,
 		Illegal:  found,
 		Expected: expected,
 		Position: position,
 	}
+	if len(tokenizer) > 0 && tokenizer[0] != nil {
+		result.Context = illegalTokenContext(tokenizer[0].Input, position, IllegalTokenContextSize)
+	}
+	return result
+}
+
+//illegalTokenContext returns up to size characters before and after position with position marked
+func illegalTokenContext(input string, position, size int) string {
+	if position < 0 {
+		position = 0
+	}
+	if position > len(input) {
+		position = len(input)
+	}
+	before := []rune(input[:position])
+	after := []rune(input[position:])
+	prefix, suffix := "", ""
+	if len(before) > size {
+		before = before[len(before)-size:]
+		prefix = "..."
+	}
+	if len(after) > size {
+		after = after[:size]
+		suffix = "..."
+	}
+	return prefix + escapeTokenContext(string(before)) + ">>>" + escapeTokenContext(string(after)) + suffix
+}
+
+func escapeTokenContext(text string) string {
+	text = strings.Replace(text, "\r", "\\r", -1)
+	text = strings.Replace(text, "\n", "\\n", -1)
+	return strings.Replace(text, "\t", "\\t", -1)
 }
 
 //ExpectTokenOptionallyFollowedBy returns second matched token or error if first and second group was not matched
@@ -583,7 +984,27 @@ func ExpectToken(tokenizer *Tokenizer, errorMessage string, candidates ...int) (
 	token := tokenizer.Nexts(candidates...)
 	hasMatch := HasSliceAnyElements(candidates, token.Token)
 	if !hasMatch {
-		return nil, NewIllegalTokenError(errorMessage, candidates, tokenizer.Index, token)
+		return nil, NewIllegalTokenError(errorMessage, candidates, token.Offset, token, tokenizer)
 	}
 	return token, nil
 }
+
+//ExpectOptionalToken returns the matched token and true, if no candidate is matched tokenizer is not advanced
+func ExpectOptionalToken(tokenizer *Tokenizer, candidates ...int) (*Token, bool) {
+	index := tokenizer.Index
+	token := tokenizer.Nexts(candidates...)
+	if !HasSliceAnyElements(candidates, token.Token) {
+		tokenizer.Index = index
+		return nil, false
+	}
+	return token, true
+}
+
+//AssertNextToken returns an error if the next token is not any of the candidates, it never advances tokenizer
+func AssertNextToken(tokenizer *Tokenizer, errorMessage string, candidates ...int) error {
+	token := tokenizer.Peek(candidates...)
+	if !HasSliceAnyElements(candidates, token.Token) {
+		return NewIllegalTokenError(errorMessage, candidates, token.Offset, token, tokenizer)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package toolbox_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,6 +27,124 @@ func TestNewTokenizer(t *testing.T) {
 
 }
 
+type lineCommentMatcher struct{}
+
+func (m lineCommentMatcher) Match(input string, offset int) int {
+	if !strings.HasPrefix(input[offset:], "//") {
+		return 0
+	}
+	if index := strings.Index(input[offset:], "\n"); index != -1 {
+		return index
+	}
+	return len(input) - offset
+}
+
+func TestTokenizer_SetSkipTokens(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		wordToken
+		whitespaceToken
+		commentToken
+		assignToken
+		intToken
+	)
+	input := "  // header\nname = 10 // value\n\t// next\n  age=3"
+	tokenizer := toolbox.NewTokenizer(input, invalidToken, eofToken, map[int]toolbox.Matcher{
+		wordToken:       toolbox.LiteralMatcher{},
+		whitespaceToken: toolbox.NewWhitespaceMatcher(true),
+		commentToken:    lineCommentMatcher{},
+		assignToken:     toolbox.NewCharactersMatcher("="),
+		intToken:        toolbox.NewIntMatcher(),
+	})
+	tokenizer.SetSkipTokens(whitespaceToken, commentToken)
+
+	var expected = []struct {
+		token    int
+		matched  string
+		position int
+	}{
+		{wordToken, "name", 12},
+		{assignToken, "=", 17},
+		{intToken, "10", 19},
+		{wordToken, "age", 42},
+		{assignToken, "=", 45},
+		{intToken, "3", 46},
+	}
+	for _, useCase := range expected {
+		assert.Equal(t, useCase.token, tokenizer.Peek(wordToken, assignToken, intToken).Token)
+		token, err := toolbox.ExpectToken(tokenizer, "expected token", wordToken, assignToken, intToken)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, useCase.token, token.Token)
+		assert.Equal(t, useCase.matched, token.Matched)
+		assert.Equal(t, useCase.position, token.Offset)
+		assert.Equal(t, len(useCase.matched), token.Length)
+		assert.Equal(t, tokenizer.Index, token.End())
+	}
+	token := tokenizer.Nexts(wordToken, eofToken)
+	assert.Equal(t, eofToken, token.Token)
+	assert.Equal(t, len(input), token.Offset)
+
+	tokenizer.Index = 0
+	assert.Equal(t, commentToken, tokenizer.Nexts(commentToken, wordToken).Token, "skip token requested as candidate should not be skipped")
+}
+
+func TestTokenizer_Fork(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		wordToken
+		whitespaceToken
+		assignToken
+		callToken
+		intToken
+	)
+	matchers := map[int]toolbox.Matcher{
+		wordToken:       toolbox.LiteralMatcher{},
+		whitespaceToken: toolbox.NewWhitespaceMatcher(true),
+		assignToken:     toolbox.NewCharactersMatcher("="),
+		callToken:       toolbox.NewBodyMatcher("(", ")"),
+		intToken:        toolbox.NewIntMatcher(),
+	}
+	parseAssignment := func(tokenizer *toolbox.Tokenizer) error {
+		if _, err := toolbox.ExpectToken(tokenizer, "expected name", wordToken); err != nil {
+			return err
+		}
+		if _, err := toolbox.ExpectToken(tokenizer, "expected =", assignToken); err != nil {
+			return err
+		}
+		_, err := toolbox.ExpectToken(tokenizer, "expected value", intToken)
+		return err
+	}
+	parseCall := func(tokenizer *toolbox.Tokenizer) error {
+		if _, err := toolbox.ExpectToken(tokenizer, "expected name", wordToken); err != nil {
+			return err
+		}
+		_, err := toolbox.ExpectToken(tokenizer, "expected arguments", callToken)
+		return err
+	}
+
+	for _, input := range []string{"msg = 10; next", "print(msg) ; next"} {
+		tokenizer := toolbox.NewTokenizer(input, invalidToken, eofToken, matchers)
+		tokenizer.SetSkipTokens(whitespaceToken)
+		matched := 0
+		for _, parser := range []func(tokenizer *toolbox.Tokenizer) error{parseAssignment, parseCall} {
+			index := tokenizer.Index
+			fork := tokenizer.Fork()
+			if err := parser(fork); err != nil {
+				assert.Equal(t, index, tokenizer.Index, "fork should not advance parent")
+				continue
+			}
+			matched++
+			tokenizer.Sync(fork)
+		}
+		assert.Equal(t, 1, matched, input)
+		assert.Equal(t, strings.Index(input, ";"), tokenizer.Nexts(wordToken).Offset, input)
+	}
+}
+
 func Test_NewCustomIdMatcher(t *testing.T) {
 	{
 		matcher := toolbox.NewCustomIdMatcher("$")
@@ -65,17 +185,169 @@ func TestMatchWhitespace(t *testing.T) {
 
 }
 
+func TestCharsetMatcher(t *testing.T) {
+	{
+		matcher := toolbox.NewCharsetMatcher("a-z0-9_")
+		assert.Equal(t, 8, matcher.Match("ab_z09x9-y", 0))
+		assert.Equal(t, 0, matcher.Match("ABC", 0))
+		assert.Equal(t, 0, matcher.Match("abc", 3))
+	}
+	{ //negated
+		matcher := toolbox.NewCharsetMatcher("^ \t,")
+		assert.Equal(t, 5, matcher.Match("ab-cd, ef", 0))
+		assert.Equal(t, 0, matcher.Match(", ef", 0))
+		assert.Equal(t, 2, matcher.Match(", ef", 2))
+	}
+	{ //literal dash
+		matcher := toolbox.NewCharsetMatcher("a-z-")
+		assert.Equal(t, 7, matcher.Match("my-name1", 0))
+		matcher = toolbox.NewCharsetMatcher("-0-9")
+		assert.Equal(t, 5, matcher.Match("12-34a", 0))
+		matcher = toolbox.NewCharsetMatcher("^")
+		assert.Equal(t, 2, matcher.Match("^^a", 0))
+	}
+	{ //multi byte runes
+		matcher := toolbox.NewCharsetMatcher("żółw")
+		assert.Equal(t, len("żółwż"), matcher.Match("żółwż x", 0))
+		assert.Equal(t, 0, matcher.Match("zolw", 0))
+		matcher = toolbox.NewCharsetMatcher("^ż")
+		assert.Equal(t, 3, matcher.Match("abcż", 0))
+	}
+}
+
+func TestWhitespaceMatcher(t *testing.T) {
+	{
+		matcher := toolbox.NewWhitespaceMatcher(false)
+		assert.Equal(t, 0, matcher.Match("abc", 0))
+		assert.Equal(t, 2, matcher.Match("a \t\nb", 1))
+		assert.Equal(t, 0, matcher.Match("a\r\nb", 1))
+		assert.Equal(t, 3, matcher.Match("a\u00a0 b", 1))
+	}
+	{
+		matcher := toolbox.NewWhitespaceMatcher(true)
+		assert.Equal(t, 4, matcher.Match("a \t\r\nb", 1))
+		assert.Equal(t, 0, matcher.Match("a", 1))
+	}
+}
+
+func TestWhitespaceMatcher_Newline(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		wordToken
+		whitespaceToken
+		newLineToken
+	)
+	tokenizer := toolbox.NewTokenizer("first  \r\n\tsecond",
+		invalidToken,
+		eofToken,
+		map[int]toolbox.Matcher{
+			wordToken:       toolbox.LiteralMatcher{},
+			whitespaceToken: toolbox.NewWhitespaceMatcher(false),
+			newLineToken:    toolbox.NewCharactersMatcher("\r\n"),
+		},
+	)
+	var actual = []int{}
+	var indexes = []int{}
+	for {
+		token := tokenizer.Nexts(wordToken, newLineToken, whitespaceToken)
+		if token.Token == eofToken || token.Token == invalidToken {
+			break
+		}
+		if token.Token != whitespaceToken {
+			actual = append(actual, token.Token)
+			indexes = append(indexes, tokenizer.Index)
+		}
+	}
+	assert.Equal(t, []int{wordToken, newLineToken, wordToken}, actual)
+	assert.Equal(t, []int{5, 9, 16}, indexes)
+}
+
 func TestLiteralMatcher(t *testing.T) {
 	matcher := toolbox.LiteralMatcher{}
 	assert.Equal(t, 0, matcher.Match(" abc ", 0))
 	assert.Equal(t, 4, matcher.Match(" a1bc", 1))
+	assert.Equal(t, 1, matcher.Match("a.b", 0))
+	assert.Equal(t, 3, toolbox.LiteralMatcher{AllowDot: true}.Match("a.b", 0))
+}
 
+func TestIdMatcher(t *testing.T) {
+	matcher := toolbox.IdMatcher{}
+	assert.Equal(t, 0, matcher.Match(" abc ", 0))
+	assert.Equal(t, 4, matcher.Match("1abc ", 0))
+	assert.Equal(t, 1, matcher.Match("a.b_c12 ", 0))
+	assert.Equal(t, 3, matcher.Match("b_c12.", 2))
+	assert.Equal(t, 7, toolbox.IdMatcher{AllowDot: true}.Match("a.b_c12 ", 0))
+	assert.Equal(t, 0, matcher.Match("_abc ", 0))
+}
+
+func TestNewLiteralMatcher(t *testing.T) {
+	var useCases = []struct {
+		description string
+		startChars  string
+		bodyChars   string
+		input       string
+		expected    int
+	}{
+		{"dollar var", "$", "_", "$var_1 = 1", 6},
+		{"dollar var not allowed", "", "_", "$var_1 = 1", 0},
+		{"private", "_", "_", "_private, x", 8},
+		{"private not allowed", "", "_", "_private, x", 0},
+		{"dot as separator", "", "", "a.b", 1},
+		{"dot as body", "", ".", "a.b", 3},
+		{"digit start", "", "", "1a", 0},
+		{"multi byte", "", "", "zażółć ", len("zażółć")},
+	}
+	for _, useCase := range useCases {
+		matcher := toolbox.NewLiteralMatcher(useCase.startChars, useCase.bodyChars)
+		assert.Equal(t, useCase.expected, matcher.Match(useCase.input, 0), useCase.description)
+	}
 }
 
 func TestEOFMatcher(t *testing.T) {
 	matcher := toolbox.EOFMatcher{}
 	assert.Equal(t, 0, matcher.Match(" abc ", 0))
-	assert.Equal(t, 1, matcher.Match(" a1bc", 4))
+	assert.Equal(t, 0, matcher.Match(" a1bc", 4))
+	assert.Equal(t, 0, matcher.Match(" a1bc", 5))
+}
+
+func TestTokenizer_EOF(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		wordToken
+		commaToken
+	)
+	matchers := map[int]toolbox.Matcher{
+		wordToken:  toolbox.LiteralMatcher{},
+		commaToken: toolbox.NewCharactersMatcher(","),
+		eofToken:   toolbox.EOFMatcher{},
+	}
+	{ //input ending at token boundary
+		tokenizer := toolbox.NewTokenizer("abc,def", invalidToken, eofToken, matchers)
+		assert.Equal(t, wordToken, tokenizer.Nexts(eofToken, wordToken).Token)
+		assert.Equal(t, commaToken, tokenizer.Nexts(eofToken, commaToken).Token)
+		assert.Equal(t, invalidToken, tokenizer.Nexts(eofToken, commaToken).Token)
+		token, err := toolbox.ExpectToken(tokenizer, "expected word", wordToken, eofToken)
+		assert.Nil(t, err)
+		assert.Equal(t, "def", token.Matched)
+		assert.Equal(t, 7, tokenizer.Index)
+		token, err = toolbox.ExpectToken(tokenizer, "expected eof", eofToken)
+		assert.Nil(t, err)
+		assert.Equal(t, eofToken, token.Token)
+		_, err = toolbox.ExpectToken(tokenizer, "expected word", wordToken)
+		assert.NotNil(t, err)
+		assert.Equal(t, eofToken, tokenizer.Next(wordToken).Token)
+	}
+	{ //empty input
+		tokenizer := toolbox.NewTokenizer("", invalidToken, eofToken, matchers)
+		assert.Equal(t, eofToken, tokenizer.Next(wordToken).Token)
+		assert.Equal(t, eofToken, tokenizer.Next(eofToken).Token)
+		assert.Equal(t, eofToken, tokenizer.Nexts(wordToken, eofToken).Token)
+		_, err := toolbox.ExpectToken(tokenizer, "expected eof", eofToken)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, tokenizer.Index)
+	}
 }
 
 func TestKeywordsMatcher(t *testing.T) {
@@ -93,6 +365,56 @@ func TestKeywordsMatcher(t *testing.T) {
 	}
 }
 
+func TestDelimitedFieldMatcher(t *testing.T) {
+	matcher := toolbox.NewDelimitedFieldMatcher(',', '"')
+	var useCases = []struct {
+		description string
+		input       string
+		offset      int
+		expected    int
+	}{
+		{"raw field", "abc,def", 0, 3},
+		{"last raw field", "abc,def", 4, 3},
+		{"empty field", "abc,,def", 4, 0},
+		{"raw field up to new line", "abc\ndef", 0, 3},
+		{"quoted field with delimiter", `"a,b",c`, 0, 5},
+		{"quoted field with escaped quote", `"a""b",c`, 0, 6},
+		{"quoted field with new line", "\"a\nb\",c", 0, 5},
+		{"unterminated quoted field", `"abc,def`, 0, 0},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expected, matcher.Match(useCase.input, useCase.offset), useCase.description)
+	}
+}
+
+func TestSplitDelimitedFields(t *testing.T) {
+	var useCases = []struct {
+		description string
+		input       string
+		expected    []string
+		hasError    bool
+	}{
+		{"raw fields", "a,b,c\n", []string{"a", "b", "c"}, false},
+		{"empty fields", ",a,,", []string{"", "a", "", ""}, false},
+		{"empty record", "", []string{""}, false},
+		{"quoted fields", `"a,b",c,"d""e"""`, []string{"a,b", "c", `d"e"`}, false},
+		{"embedded new lines", "1,\"line1\r\nline2\",3\r\n", []string{"1", "line1\r\nline2", "3"}, false},
+		{"unterminated quote", `a,"b`, nil, true},
+		{"text after quoted field", `"a"b,c`, nil, true},
+	}
+	for _, useCase := range useCases {
+		actual, err := toolbox.SplitDelimitedFields(useCase.input, ',', '"')
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expected, actual, useCase.description)
+	}
+}
+
 func TestBodyMatcher(t *testing.T) {
 	{
 		matcher := toolbox.BodyMatcher{Begin: "{", End: "}"}
@@ -108,6 +430,114 @@ func TestBodyMatcher(t *testing.T) {
 	}
 }
 
+func TestBodyMatcher_Quotes(t *testing.T) {
+	matcher := toolbox.NewBodyMatcher("(", ")", "'", `"`)
+	var text = `(a, ')', "(", (b)) c`
+	assert.Equal(t, 18, matcher.Match(text, 0))
+	assert.Equal(t, 0, matcher.Match(`(a, ')`, 0))
+}
+
+func TestExtractDelimitedFragment(t *testing.T) {
+	var useCases = []struct {
+		description string
+		input       string
+		offset      int
+		begin       string
+		end         string
+		expected    string
+		endOffset   int
+		hasError    bool
+	}{
+		{
+			description: "quoted semicolon",
+			input:       "SET x = 'a;b'; SELECT 1;",
+			offset:      4,
+			begin:       "x",
+			end:         ";",
+			expected:    " = 'a;b'",
+			endOffset:   14,
+		},
+		{
+			description: "nested parentheses with quotes",
+			input:       "INSERT INTO t VALUES (1, ';', ')', f(2)); COMMIT;",
+			offset:      21,
+			begin:       "(",
+			end:         ")",
+			expected:    "1, ';', ')', f(2)",
+			endOffset:   40,
+		},
+		{
+			description: "block with quoted terminator",
+			input:       "BEGIN SELECT 'END;' FROM dual; END; x",
+			offset:      0,
+			begin:       "BEGIN",
+			end:         "END;",
+			expected:    " SELECT 'END;' FROM dual; ",
+			endOffset:   35,
+		},
+		{
+			description: "unterminated",
+			input:       "CALL fn(1, ';'",
+			offset:      7,
+			begin:       "(",
+			end:         ")",
+			hasError:    true,
+		},
+		{
+			description: "unterminated quote",
+			input:       "CALL fn(1, ')",
+			offset:      7,
+			begin:       "(",
+			end:         ")",
+			hasError:    true,
+		},
+		{
+			description: "missing begin",
+			input:       "CALL fn(1)",
+			offset:      0,
+			begin:       "(",
+			end:         ")",
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		fragment, endOffset, err := toolbox.ExtractDelimitedFragment(useCase.input, useCase.offset, useCase.begin, useCase.end, []string{"'"})
+		if useCase.hasError {
+			if assert.NotNil(t, err, useCase.description) {
+				assert.Contains(t, err.Error(), fmt.Sprintf("at %v", useCase.offset), useCase.description)
+			}
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expected, fragment, useCase.description)
+		assert.Equal(t, useCase.endOffset, endOffset, useCase.description)
+	}
+}
+
+func TestBalancedMatcher(t *testing.T) {
+	matcher := toolbox.NewBalancedMatcher(map[string]string{"(": ")", "[": "]", "{": "}", "begin": "end"}, `"`, "'")
+	var useCases = []struct {
+		description string
+		input       string
+		offset      int
+		expected    int
+	}{
+		{"simple", "(a) b", 0, 3},
+		{"nested mixed", "x ( [a, {b}], (c) ) y", 2, 17},
+		{"nested keywords", "begin (x) begin end end;", 0, 23},
+		{"mismatch in the middle", "( [ ) ]", 0, 0},
+		{"unterminated", "( [ ]", 0, 0},
+		{"not an opener", "a(b)", 0, 0},
+		{"quoted closer", `(a, ")", ']') b`, 0, 13},
+		{"unterminated quote", `(a, ")`, 0, 0},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expected, matcher.Match(useCase.input, useCase.offset), useCase.description)
+	}
+}
+
 func TestBlockMatcher(t *testing.T) {
 	{
 		matcher := toolbox.NewBlockMatcher(false, "begin", "end;", []string{"CASE"}, []string{"END IF"})
@@ -166,3 +596,235 @@ INSERT INTO DUMMY(ID, NAME) VALUES(2, 'xyz');
 	}
 
 }
+
+func TestTokenizer_TokenOffset(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		wordToken
+		whitespaceToken
+	)
+	input := "abc  def"
+	tokenizer := toolbox.NewTokenizer(input, invalidToken, eofToken, map[int]toolbox.Matcher{
+		wordToken:       toolbox.LiteralMatcher{},
+		whitespaceToken: toolbox.NewWhitespaceMatcher(true),
+	})
+	token := tokenizer.Nexts(wordToken, whitespaceToken)
+	assert.Equal(t, &toolbox.Token{Token: wordToken, Matched: "abc", Offset: 0, Length: 3}, token)
+	token = tokenizer.Next(wordToken)
+	assert.Equal(t, &toolbox.Token{Token: invalidToken, Offset: 3}, token)
+	token, err := toolbox.ExpectTokenOptionallyFollowedBy(tokenizer, whitespaceToken, "expected word", wordToken)
+	assert.Nil(t, err)
+	assert.Equal(t, &toolbox.Token{Token: wordToken, Matched: "def", Offset: 5, Length: 3}, token)
+	assert.Equal(t, input[token.Offset:token.End()], token.Matched)
+	token = tokenizer.Next(wordToken)
+	assert.Equal(t, &toolbox.Token{Token: eofToken, Offset: 8}, token)
+}
+
+func TestExpectToken_ErrorContext(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		wordToken
+		whitespaceToken
+		commaToken
+	)
+	matchers := map[int]toolbox.Matcher{
+		wordToken:       toolbox.LiteralMatcher{},
+		whitespaceToken: toolbox.NewWhitespaceMatcher(true),
+		commaToken:      toolbox.NewCharactersMatcher(","),
+	}
+	{
+		tokenizer := toolbox.NewTokenizer("abc, def\n ghi 123", invalidToken, eofToken, matchers)
+		for i := 0; i < 4; i++ {
+			_, err := toolbox.ExpectTokenOptionallyFollowedBy(tokenizer, whitespaceToken, "expected word", wordToken, commaToken)
+			assert.Nil(t, err)
+		}
+		_, err := toolbox.ExpectTokenOptionallyFollowedBy(tokenizer, whitespaceToken, "expected word", wordToken)
+		assert.NotNil(t, err)
+		illegalTokenError, ok := err.(*toolbox.IllegalTokenError)
+		if assert.True(t, ok) {
+			assert.Equal(t, 14, illegalTokenError.Position)
+			assert.Equal(t, []int{wordToken}, illegalTokenError.Expected)
+			assert.Equal(t, "abc, def\\n ghi >>>123", illegalTokenError.Context)
+		}
+	}
+	{
+		input := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa, bbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+		tokenizer := toolbox.NewTokenizer(input, invalidToken, eofToken, matchers)
+		_, err := toolbox.ExpectToken(tokenizer, "expected word", wordToken)
+		assert.Nil(t, err)
+		_, err = toolbox.ExpectToken(tokenizer, "expected word", wordToken)
+		assert.NotNil(t, err)
+		illegalTokenError, ok := err.(*toolbox.IllegalTokenError)
+		if assert.True(t, ok) {
+			assert.Equal(t, "...aaaaaaaaaaaaaaaaaaaa>>>, bbbbbbbbbbbbbbbbbb...", illegalTokenError.Context)
+			assert.Contains(t, err.Error(), "near: ...aaaaaaaaaaaaaaaaaaaa>>>")
+		}
+	}
+	{
+		err := toolbox.NewIllegalTokenError("expected word", []int{wordToken}, 3, &toolbox.Token{Token: invalidToken})
+		assert.Equal(t, "", err.(*toolbox.IllegalTokenError).Context)
+	}
+}
+
+func TestExpectOptionalToken(t *testing.T) {
+	const (
+		invalidToken = iota
+		eofToken
+		whitespaceToken
+		letToken
+		nameToken
+		assignToken
+		intToken
+		commaToken
+		semicolonToken
+	)
+	matchers := map[int]toolbox.Matcher{
+		whitespaceToken: toolbox.NewWhitespaceMatcher(true),
+		letToken:        toolbox.KeywordMatcher{Keyword: "let", CaseSensitive: true},
+		nameToken:       toolbox.LiteralMatcher{},
+		assignToken:     toolbox.NewCharactersMatcher("="),
+		intToken:        toolbox.NewIntMatcher(),
+		commaToken:      toolbox.NewCharactersMatcher(","),
+		semicolonToken:  toolbox.NewCharactersMatcher(";"),
+	}
+
+	//declaration: [let] name [= int] {, name [= int]} ;
+	parse := func(input string) (map[string]string, error) {
+		tokenizer := toolbox.NewTokenizer(input, invalidToken, eofToken, matchers)
+		tokenizer.SetSkipTokens(whitespaceToken)
+		var result = make(map[string]string)
+		_, _ = toolbox.ExpectOptionalToken(tokenizer, letToken)
+		for {
+			if err := toolbox.AssertNextToken(tokenizer, "expected name", nameToken); err != nil {
+				return nil, err
+			}
+			name, _ := toolbox.ExpectToken(tokenizer, "expected name", nameToken)
+			result[name.Matched] = ""
+			if _, ok := toolbox.ExpectOptionalToken(tokenizer, assignToken); ok {
+				value, err := toolbox.ExpectToken(tokenizer, "expected value", intToken)
+				if err != nil {
+					return nil, err
+				}
+				result[name.Matched] = value.Matched
+			}
+			if _, ok := toolbox.ExpectOptionalToken(tokenizer, commaToken); !ok {
+				break
+			}
+		}
+		if _, err := toolbox.ExpectToken(tokenizer, "expected ;", semicolonToken); err != nil {
+			return nil, err
+		}
+		_, err := toolbox.ExpectToken(tokenizer, "expected eof", eofToken)
+		return result, err
+	}
+
+	{
+		actual, err := parse("let a = 1, b,\n c=3;")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"a": "1", "b": "", "c": "3"}, actual)
+	}
+	{
+		actual, err := parse("x;")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"x": ""}, actual)
+	}
+	{
+		_, err := parse("let a = 1, 2;")
+		if assert.NotNil(t, err) {
+			illegalTokenError, ok := err.(*toolbox.IllegalTokenError)
+			if assert.True(t, ok) {
+				assert.Equal(t, 11, illegalTokenError.Position)
+				assert.Equal(t, "expected name", illegalTokenError.Message)
+				assert.Equal(t, []int{nameToken}, illegalTokenError.Expected)
+				assert.Equal(t, "let a = 1, >>>2;", illegalTokenError.Context)
+			}
+		}
+	}
+	{
+		_, err := parse("a = ;")
+		assert.NotNil(t, err)
+	}
+	{
+		tokenizer := toolbox.NewTokenizer("  name", invalidToken, eofToken, matchers)
+		tokenizer.SetSkipTokens(whitespaceToken)
+		assert.Nil(t, toolbox.AssertNextToken(tokenizer, "expected name", nameToken))
+		assert.NotNil(t, toolbox.AssertNextToken(tokenizer, "expected int", intToken))
+		assert.Equal(t, 0, tokenizer.Index)
+		_, ok := toolbox.ExpectOptionalToken(tokenizer, intToken)
+		assert.False(t, ok)
+		assert.Equal(t, 0, tokenizer.Index)
+	}
+}
+
+func benchmarkTokenizerInput() string {
+	var fragments = []string{
+		"SELECT id, Name, created FROM Users WHERE id > 10 AND status = 'active' -- note\n",
+		"Insert Into events(id, payload) Values (1, 'a,b') ;\n",
+	}
+	var buffer = make([]byte, 0, 1024*1024+256)
+	for i := 0; len(buffer) < 1024*1024; i++ {
+		buffer = append(buffer, fragments[i%len(fragments)]...)
+	}
+	return string(buffer)
+}
+
+func BenchmarkTokenizer(b *testing.B) {
+	const (
+		invalidToken = iota
+		eofToken
+		whitespaceToken
+		keywordToken
+		selectToken
+		idToken
+		intToken
+		quotedToken
+		commentToken
+		operatorToken
+		punctuationToken
+	)
+	matchers := map[int]toolbox.Matcher{
+		whitespaceToken:  toolbox.CharactersMatcher{Chars: " \t\r\n"},
+		keywordToken:     toolbox.NewKeywordsMatcher(false, "insert", "into", "values", "from", "where", "and"),
+		selectToken:      toolbox.KeywordMatcher{Keyword: "select", CaseSensitive: false},
+		idToken:          toolbox.NewCustomIdMatcher("_"),
+		intToken:         toolbox.NewIntMatcher(),
+		quotedToken:      toolbox.NewBodyMatcher("'", "'"),
+		commentToken:     toolbox.NewSequenceMatcher("\n"),
+		operatorToken:    toolbox.NewCharactersMatcher("=<>"),
+		punctuationToken: toolbox.NewCharactersMatcher(",;()"),
+	}
+	input := benchmarkTokenizerInput()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tokenizer := toolbox.NewTokenizer(input, invalidToken, eofToken, matchers)
+		tokenizer.SetSkipTokens(whitespaceToken)
+		for {
+			token := tokenizer.Nexts(selectToken, keywordToken, idToken, intToken, quotedToken, operatorToken, punctuationToken)
+			if token.Token == invalidToken {
+				token = tokenizer.Nexts(commentToken)
+			}
+			if token.Token == eofToken || token.Token == invalidToken {
+				break
+			}
+		}
+		if tokenizer.Index != len(input) {
+			b.Fatalf("expected all input to be consumed: %v", tokenizer.Index)
+		}
+	}
+}
+
+func BenchmarkSequenceMatcher(b *testing.B) {
+	input := benchmarkTokenizerInput()
+	matcher := &toolbox.SequenceMatcher{Terminators: []string{"VALUES", "--"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for offset := 0; offset < 4096; offset += 64 {
+			matcher.Match(input, offset)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package url
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//Supported resource text charsets
+const (
+	CharsetUTF8     = "utf-8"
+	CharsetUTF16    = "utf-16"
+	CharsetUTF16LE  = "utf-16le"
+	CharsetUTF16BE  = "utf-16be"
+	CharsetISO88591 = "iso-8859-1"
+)
+
+//binaryContentThreshold represents max ratio of invalid UTF-8 bytes for content to be treated as text
+const binaryContentThreshold = 0.1
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+//charsetAliases represents charset names by alias
+var charsetAliases = map[string]string{
+	"utf8":     CharsetUTF8,
+	"utf16":    CharsetUTF16,
+	"utf16le":  CharsetUTF16LE,
+	"utf16be":  CharsetUTF16BE,
+	"iso88591": CharsetISO88591,
+	"latin1":   CharsetISO88591,
+	"l1":       CharsetISO88591,
+}
+
+//BinaryContentError represents binary content requested as text
+type BinaryContentError struct {
+	URL string //resource URL
+}
+
+//Error returns error message
+func (e *BinaryContentError) Error() string {
+	return fmt.Sprintf("content of %v appears to be binary, use Download instead of DownloadText", e.URL)
+}
+
+//WithCharset returns an option forcing resource text charset, i.e. iso-8859-1 for legacy feeds
+func WithCharset(charset string) ResourceOption {
+	return func(resource *Resource) {
+		resource.Charset = charset
+	}
+}
+
+//normalizeCharset returns canonical charset name or empty string for unsupported charset
+func normalizeCharset(charset string) string {
+	key := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(charset))
+	return charsetAliases[key]
+}
+
+//toUTF8 returns content transcoded to UTF-8, with empty charset UTF-8 and UTF-16 byte order marks are detected and stripped
+func toUTF8(content []byte, charset string) ([]byte, error) {
+	if charset == "" {
+		switch {
+		case bytes.HasPrefix(content, utf8BOM):
+			return content[len(utf8BOM):], nil
+		case bytes.HasPrefix(content, utf16LEBOM):
+			return decodeUTF16(content[len(utf16LEBOM):], binary.LittleEndian), nil
+		case bytes.HasPrefix(content, utf16BEBOM):
+			return decodeUTF16(content[len(utf16BEBOM):], binary.BigEndian), nil
+		}
+		return content, nil
+	}
+	switch normalizeCharset(charset) {
+	case CharsetUTF8:
+		return bytes.TrimPrefix(content, utf8BOM), nil
+	case CharsetUTF16:
+		if bytes.HasPrefix(content, utf16LEBOM) {
+			return decodeUTF16(content[len(utf16LEBOM):], binary.LittleEndian), nil
+		}
+		return decodeUTF16(bytes.TrimPrefix(content, utf16BEBOM), binary.BigEndian), nil
+	case CharsetUTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(content, utf16LEBOM), binary.LittleEndian), nil
+	case CharsetUTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(content, utf16BEBOM), binary.BigEndian), nil
+	case CharsetISO88591:
+		return decodeISO88591(content), nil
+	}
+	return nil, fmt.Errorf("unsupported charset: %v", charset)
+}
+
+//hasUTF16BOM returns true if content starts with UTF-16 byte order mark
+func hasUTF16BOM(content []byte) bool {
+	return bytes.HasPrefix(content, utf16LEBOM) || bytes.HasPrefix(content, utf16BEBOM)
+}
+
+func decodeUTF16(content []byte, order binary.ByteOrder) []byte {
+	var units = make([]uint16, 0, len(content)/2)
+	for i := 0; i+1 < len(content); i += 2 {
+		units = append(units, order.Uint16(content[i:]))
+	}
+	var result = bytes.NewBuffer(make([]byte, 0, len(content)))
+	for _, r := range utf16.Decode(units) {
+		result.WriteRune(r)
+	}
+	return result.Bytes()
+}
+
+func decodeISO88591(content []byte) []byte {
+	var result = bytes.NewBuffer(make([]byte, 0, len(content)))
+	for _, b := range content {
+		result.WriteRune(rune(b))
+	}
+	return result.Bytes()
+}
+
+//isBinary returns true if ratio of invalid UTF-8 bytes exceeds binaryContentThreshold, NUL bytes are counted as invalid
+func isBinary(content []byte) bool {
+	if len(content) == 0 {
+		return false
+	}
+	var invalid = 0
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRune(content[i:])
+		if r == utf8.RuneError && size == 1 || r == 0 {
+			invalid++
+		}
+		i += size
+	}
+	return float64(invalid)/float64(len(content)) > binaryContentThreshold
+}
@@ -0,0 +1,124 @@
+package url_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestResource_DownloadText_Charset(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_charset")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+
+	var useCases = []struct {
+		description string
+		charset     string
+		content     []byte
+		expect      string
+		hasError    bool
+	}{
+		{
+			description: "plain UTF-8",
+			content:     []byte("zażółć"),
+			expect:      "zażółć",
+		},
+		{
+			description: "UTF-8 BOM",
+			content:     []byte{0xEF, 0xBB, 0xBF, 'a', 'b', 'c'},
+			expect:      "abc",
+		},
+		{
+			description: "UTF-16 LE BOM",
+			content:     []byte{0xFF, 0xFE, 'h', 0x00, 'i', 0x00, 0x7C, 0x01},
+			expect:      "hiż",
+		},
+		{
+			description: "UTF-16 BE BOM",
+			content:     []byte{0xFE, 0xFF, 0x00, 'h', 0x00, 'i', 0x01, 0x7C},
+			expect:      "hiż",
+		},
+		{
+			description: "forced ISO-8859-1",
+			charset:     "ISO-8859-1",
+			content:     []byte{'c', 'a', 'f', 0xE9},
+			expect:      "café",
+		},
+		{
+			description: "forced latin1 alias",
+			charset:     "latin1",
+			content:     []byte{0xC5, 'l', 'a', 'n', 'd'},
+			expect:      "Åland",
+		},
+		{
+			description: "unsupported charset",
+			charset:     "ebcdic",
+			content:     []byte("abc"),
+			hasError:    true,
+		},
+		{
+			description: "binary content",
+			content:     []byte{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A, 0x00, 0x00, 0x00, 0x0D, 0xC8, 0xFF},
+			hasError:    true,
+		},
+	}
+	for i, useCase := range useCases {
+		filename := path.Join(parent, "text"+string(rune('a'+i))+".txt")
+		assert.Nil(t, ioutil.WriteFile(filename, useCase.content, 0644))
+		resource := url.NewResource(filename, url.WithCharset(useCase.charset))
+		actual, err := resource.DownloadText()
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expect, actual, useCase.description)
+		}
+	}
+
+	filename := path.Join(parent, "image.bin")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00, 0x01}, 0644))
+	_, err := url.NewResource(filename).DownloadText()
+	if assert.NotNil(t, err) {
+		_, ok := err.(*url.BinaryContentError)
+		assert.True(t, ok, err.Error())
+	}
+}
+
+func TestResource_Decode_Charset(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_charset_decode")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+
+	var useCases = []struct {
+		description string
+		charset     string
+		content     []byte
+	}{
+		{
+			description: "UTF-8 BOM JSON",
+			content:     append([]byte{0xEF, 0xBB, 0xBF}, []byte(`{"name":"café"}`)...),
+		},
+		{
+			description: "UTF-16 LE BOM JSON",
+			content:     []byte{0xFF, 0xFE, '{', 0, '"', 0, 'n', 0, 'a', 0, 'm', 0, 'e', 0, '"', 0, ':', 0, '"', 0, 'c', 0, 'a', 0, 'f', 0, 0xE9, 0, '"', 0, '}', 0},
+		},
+		{
+			description: "ISO-8859-1 JSON",
+			charset:     url.CharsetISO88591,
+			content:     []byte{'{', '"', 'n', 'a', 'm', 'e', '"', ':', '"', 'c', 'a', 'f', 0xE9, '"', '}'},
+		},
+	}
+	for i, useCase := range useCases {
+		filename := path.Join(parent, "doc"+string(rune('a'+i))+".json")
+		assert.Nil(t, ioutil.WriteFile(filename, useCase.content, 0644))
+		var target = make(map[string]interface{})
+		err := url.NewResource(filename, url.WithCharset(useCase.charset)).Decode(&target)
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, "café", target["name"], useCase.description)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package url
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	//ChecksumSHA256 represents sha256 checksum algorithm
+	ChecksumSHA256 = "sha256"
+	//ChecksumMD5 represents md5 checksum algorithm
+	ChecksumMD5 = "md5"
+	//SidecarExtension represents expected sha256 digest sibling URL suffix
+	SidecarExtension = ".sha256"
+)
+
+//ChecksumMismatchError represents downloaded content digest mismatch
+type ChecksumMismatchError struct {
+	URL       string //resource URL
+	Algorithm string //checksum algorithm
+	Expected  string //expected hex digest
+	Actual    string //actual content hex digest
+}
+
+//Error returns mismatch description
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%v checksum mismatch for %v: expected %v, actual %v", e.Algorithm, e.URL, e.Expected, e.Actual)
+}
+
+//WithChecksum returns an option setting expected content hex digest for sha256 or md5 algorithm
+func WithChecksum(algorithm, hexDigest string) ResourceOption {
+	return func(resource *Resource) {
+		switch strings.Replace(strings.ToLower(algorithm), "-", "", 1) {
+		case ChecksumSHA256:
+			resource.SHA256 = hexDigest
+		case ChecksumMD5:
+			resource.MD5 = hexDigest
+		default:
+			resource.optionError = fmt.Errorf("unsupported checksum algorithm: %v", algorithm)
+		}
+	}
+}
+
+//checksumVerifier computes content digests while streaming
+type checksumVerifier struct {
+	expected map[string]string
+	hashes   map[string]hash.Hash
+	writer   io.Writer
+}
+
+func (v *checksumVerifier) Write(p []byte) (int, error) {
+	return v.writer.Write(p)
+}
+
+//verify returns *ChecksumMismatchError if any computed digest does not match expected value
+func (v *checksumVerifier) verify(URL string) error {
+	var algorithms = make([]string, 0, len(v.expected))
+	for algorithm := range v.expected {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	for _, algorithm := range algorithms {
+		expected := strings.ToLower(strings.TrimSpace(v.expected[algorithm]))
+		actual := hex.EncodeToString(v.hashes[algorithm].Sum(nil))
+		if expected != actual {
+			return &ChecksumMismatchError{URL: URL, Algorithm: algorithm, Expected: expected, Actual: actual}
+		}
+	}
+	return nil
+}
+
+func newChecksumVerifier(expected map[string]string) *checksumVerifier {
+	var result = &checksumVerifier{expected: expected, hashes: make(map[string]hash.Hash)}
+	var writers = make([]io.Writer, 0, len(expected))
+	for algorithm := range expected {
+		switch algorithm {
+		case ChecksumSHA256:
+			result.hashes[algorithm] = sha256.New()
+		case ChecksumMD5:
+			result.hashes[algorithm] = md5.New()
+		}
+		writers = append(writers, result.hashes[algorithm])
+	}
+	result.writer = io.MultiWriter(writers...)
+	return result
+}
+
+//hasChecksum returns true if resource content has to be verified
+func (r *Resource) hasChecksum() bool {
+	return r.SHA256 != "" || r.MD5 != "" || r.VerifySidecar
+}
+
+//expectedChecksums returns expected digests by algorithm, sha256 is loaded from URL + ".sha256" sidecar when VerifySidecar is enabled
+func (r *Resource) expectedChecksums(ctx context.Context) (map[string]string, error) {
+	if r.optionError != nil {
+		return nil, r.optionError
+	}
+	var result = make(map[string]string)
+	if r.MD5 != "" {
+		result[ChecksumMD5] = r.MD5
+	}
+	if r.SHA256 != "" {
+		result[ChecksumSHA256] = r.SHA256
+	} else if r.VerifySidecar && !IsDataURL(r.URL) {
+		digest, err := r.sidecarDigest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result[ChecksumSHA256] = digest
+	}
+	return result, nil
+}
+
+//sidecarDigest loads sha256 hex digest from sibling sidecar, i.e. sha256sum output: <digest>  <filename>
+func (r *Resource) sidecarDigest(ctx context.Context) (string, error) {
+	sidecar := r.Clone()
+	sidecar.SHA256, sidecar.MD5, sidecar.VerifySidecar = "", "", false
+	if r.ParsedURL != nil {
+		sidecarURL := *r.ParsedURL
+		sidecarURL.Path += SidecarExtension
+		sidecarURL.RawPath = ""
+		sidecar.URL = sidecarURL.String()
+	} else {
+		sidecar.URL = r.URL + SidecarExtension
+	}
+	content, err := sidecar.download(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load checksum sidecar %v, %v", sidecar.URL, err)
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum sidecar %v was empty", sidecar.URL)
+	}
+	return fields[0], nil
+}
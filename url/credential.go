@@ -0,0 +1,241 @@
+package url
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/cred"
+	"github.com/viant/toolbox/storage"
+	"gopkg.in/yaml.v2"
+)
+
+//CredentialConfig represents structured credential loaded from resource credentials file
+type CredentialConfig struct {
+	Username          string                 //Username user name
+	Password          string                 //Password plain password, EncryptedPassword is decrypted into it
+	PrivateKeyPath    string                 //PrivateKeyPath private key file location
+	PrivateKeyPayload string                 //PrivateKeyPayload inline private key, i.e. private_key
+	Token             string                 //Token access or session token
+	Region            string                 //Region cloud region
+	Endpoint          string                 //Endpoint custom service endpoint
+	Extra             map[string]interface{} //Extra remaining credential keys with original key names
+}
+
+//IsEmpty returns true if no credential attribute was loaded
+func (c *CredentialConfig) IsEmpty() bool {
+	return c.Username == "" && c.Password == "" && c.PrivateKeyPath == "" && c.PrivateKeyPayload == "" && c.Token == "" && c.Region == "" && c.Endpoint == "" && len(c.Extra) == 0
+}
+
+//Config returns cred config, extra keys are assigned to matching cred config fields
+func (c *CredentialConfig) Config() (*cred.Config, error) {
+	var result = &cred.Config{}
+	if len(c.Extra) > 0 {
+		if err := toolbox.DefaultConverter.AssignConverted(result, c.Extra); err != nil {
+			return nil, fmt.Errorf("failed to convert credential extra keys, %v", err)
+		}
+	}
+	result.Username = c.Username
+	result.Password = c.Password
+	result.EncryptedPassword = ""
+	result.PrivateKeyPath = c.PrivateKeyPath
+	result.PrivateKey = c.PrivateKeyPayload
+	result.Token = c.Token
+	result.Region = c.Region
+	result.Endpoint = c.Endpoint
+	return result, nil
+}
+
+//set assigns known credential key or adds it to extra keys, it returns error if encrypted password can not be decoded
+func (c *CredentialConfig) set(key string, value interface{}) error {
+	switch strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key)) {
+	case "username":
+		c.Username = toolbox.AsString(value)
+	case "password":
+		if c.Password == "" {
+			c.Password = toolbox.AsString(value)
+		}
+	case "encryptedpassword":
+		encrypted := toolbox.AsString(value)
+		if encrypted == "" {
+			return nil
+		}
+		data, err := base64.StdEncoding.DecodeString(encrypted)
+		if err != nil {
+			return fmt.Errorf("invalid encrypted password, %v", err)
+		}
+		c.Password = string(cred.PasswordCipher.Decrypt(data))
+	case "privatekeypath":
+		c.PrivateKeyPath = toolbox.AsString(value)
+	case "privatekey", "privatekeypayload":
+		c.PrivateKeyPayload = toolbox.AsString(value)
+	case "token":
+		c.Token = toolbox.AsString(value)
+	case "region":
+		c.Region = toolbox.AsString(value)
+	case "endpoint":
+		c.Endpoint = toolbox.AsString(value)
+	default:
+		if c.Extra == nil {
+			c.Extra = make(map[string]interface{})
+		}
+		c.Extra[key] = value
+	}
+	return nil
+}
+
+//decodeCredentialMap decodes credential content into a map, YAML is used for .yaml and .yml extension, JSON otherwise
+func decodeCredentialMap(content []byte, ext string) (map[string]interface{}, error) {
+	var result = make(map[string]interface{})
+	if ext == ".yaml" || ext == ".yml" {
+		var data interface{}
+		if err := yaml.Unmarshal(content, &data); err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return result, nil
+		}
+		normalized, ok := normalizeYAMLKeys(data).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected credential map, but had: %T", data)
+		}
+		return normalized, nil
+	}
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return result, nil
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//expandCredentialSource expands environment variables and leading ~ in credential location
+func expandCredentialSource(location string) string {
+	location = strings.TrimSpace(os.ExpandEnv(location))
+	if location == "~" || strings.HasPrefix(location, "~/") {
+		location = os.Getenv("HOME") + location[1:]
+	}
+	return location
+}
+
+//credentialCandidates returns expanded credential locations: Credentials followed by CredentialPaths, empty locations are skipped
+func (r *Resource) credentialCandidates() []string {
+	var result = make([]string, 0, 1+len(r.CredentialPaths))
+	for _, candidate := range append([]string{r.Credentials}, r.CredentialPaths...) {
+		if candidate = expandCredentialSource(candidate); candidate != "" {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+//credentialLocation returns credential location passed to storage service provider: CredentialSource of the first valid candidate,
+//or Credentials if no candidate could be read, i.e. credential key interpreted by provider
+func (r *Resource) credentialLocation() (string, error) {
+	if len(r.credentialCandidates()) == 0 {
+		return "", nil
+	}
+	r.CredentialSource = ""
+	if _, err := r.loadCredentialConfig(false, r.CredentialKey); err != nil {
+		return "", err
+	}
+	if r.CredentialSource == "" {
+		return r.Credentials, nil
+	}
+	return r.CredentialSource, nil
+}
+
+//storageService returns storage service for resource URL created with resolved credential location
+func (r *Resource) storageService() (storage.Service, error) {
+	credentials, err := r.credentialLocation()
+	if err != nil {
+		return nil, err
+	}
+	return storage.NewServiceForURL(r.URL, credentials)
+}
+
+//LoadCredentialConfig loads structured credential from the first resource credential candidate that exists and parses, JSON or YAML format is selected by file extension,
+//chosen location is recorded as CredentialSource, encrypted envelope is decrypted with CredentialKey
+func (r *Resource) LoadCredentialConfig(errorIfEmpty bool) (*CredentialConfig, error) {
+	if r == nil {
+		return r.loadCredentialConfig(errorIfEmpty, "")
+	}
+	return r.loadCredentialConfig(errorIfEmpty, r.CredentialKey)
+}
+
+func (r *Resource) loadCredentialConfig(errorIfEmpty bool, keySource string) (*CredentialConfig, error) {
+	var candidates []string
+	if r != nil {
+		candidates = r.credentialCandidates()
+	}
+	if len(candidates) == 0 {
+		if errorIfEmpty {
+			return nil, fmt.Errorf("credentials were empty")
+		}
+		return &CredentialConfig{}, nil
+	}
+	var tried = make([]string, 0, len(candidates))
+	var malformed = false
+	for _, candidate := range candidates {
+		location := NewResource(candidate, WithExpandURL(false))
+		content, err := location.Download()
+		if err != nil {
+			tried = append(tried, fmt.Sprintf("%v (%v)", candidate, err))
+			continue
+		}
+		result, err := decodeCredentialConfig(content, strings.ToLower(path.Ext(location.ParsedURL.Path)), keySource)
+		if err != nil {
+			malformed = true
+			tried = append(tried, fmt.Sprintf("%v (%v)", candidate, err))
+			continue
+		}
+		if errorIfEmpty && result.IsEmpty() {
+			tried = append(tried, fmt.Sprintf("%v (credentials were empty)", candidate))
+			continue
+		}
+		r.CredentialSource = candidate
+		return result, nil
+	}
+	if !errorIfEmpty && !malformed {
+		return &CredentialConfig{}, nil
+	}
+	return nil, fmt.Errorf("failed to load credentials, tried: %v", strings.Join(tried, ", "))
+}
+
+//decodeCredentialConfig decodes credential content, encrypted envelope is decrypted with key from keySource
+func decodeCredentialConfig(content []byte, ext string, keySource string) (*CredentialConfig, error) {
+	if cred.IsEnvelope(content) {
+		key, err := cred.LoadKey(keySource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key for encrypted credential, %v", err)
+		}
+		config := &cred.Config{}
+		if err = config.Decrypt(content, key); err != nil {
+			return nil, err
+		}
+		config.EncryptedPassword = ""
+		if content, err = json.Marshal(config); err != nil {
+			return nil, err
+		}
+		ext = ".json"
+	}
+	values, err := decodeCredentialMap(content, ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode credentials, %v", err)
+	}
+	var result = &CredentialConfig{}
+	for key, value := range values {
+		if value == nil || value == "" {
+			continue
+		}
+		if err = result.set(key, value); err != nil {
+			return nil, fmt.Errorf("failed to decode credentials, %v", err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package url
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/viant/toolbox/storage"
+)
+
+//DataScheme represents RFC 2397 data URL scheme
+const DataScheme = "data"
+
+//defaultDataMediaType represents data URL media type when none is specified
+const defaultDataMediaType = "text/plain"
+
+//mediaTypeExtensions represents decoder extension by media type
+var mediaTypeExtensions = map[string]string{
+	"application/json":       ".json",
+	"text/json":              ".json",
+	"application/x-ndjson":   ".ndjson",
+	"application/yaml":       ".yaml",
+	"application/x-yaml":     ".yaml",
+	"text/yaml":              ".yaml",
+	"text/x-yaml":            ".yaml",
+	"text/x-java-properties": ".properties",
+	"application/toml":       ".toml",
+}
+
+//DataURL represents a parsed data URL: data:[<mediatype>][;base64],<data>
+type DataURL struct {
+	MediaType string            //media type, text/plain if not specified
+	Params    map[string]string //media type parameters, i.e. charset
+	Base64    bool              //true if payload is base64 encoded
+	Data      []byte            //decoded payload
+}
+
+//IsDataURL returns true if URL uses data scheme
+func IsDataURL(URL string) bool {
+	return len(URL) > len(DataScheme) && strings.EqualFold(URL[:len(DataScheme)+1], DataScheme+":")
+}
+
+//ParseDataURL parses data URL decoding base64 or percent-encoded payload
+func ParseDataURL(URL string) (*DataURL, error) {
+	if !IsDataURL(URL) {
+		return nil, fmt.Errorf("not a data URL: %v", abbreviate(URL))
+	}
+	index := strings.Index(URL, ",")
+	if index == -1 {
+		return nil, fmt.Errorf("invalid data URL: %v, missing ',' payload separator", abbreviate(URL))
+	}
+	result := &DataURL{MediaType: defaultDataMediaType, Params: make(map[string]string)}
+	header, payload := URL[len(DataScheme)+1:index], URL[index+1:]
+	for i, fragment := range strings.Split(header, ";") {
+		fragment = strings.TrimSpace(fragment)
+		switch {
+		case i == 0:
+			if fragment != "" {
+				result.MediaType = strings.ToLower(fragment)
+			}
+		case strings.EqualFold(fragment, "base64"):
+			result.Base64 = true
+		case strings.Contains(fragment, "="):
+			pair := strings.SplitN(fragment, "=", 2)
+			result.Params[strings.ToLower(pair[0])] = pair[1]
+		}
+	}
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data URL percent-encoded payload: %v, %v", abbreviate(URL), err)
+	}
+	if result.Base64 {
+		decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid data URL base64 payload: %v, %v", abbreviate(URL), err)
+		}
+		result.Data = decoded
+		return result, nil
+	}
+	result.Data = []byte(data)
+	return result, nil
+}
+
+//Extension returns decoder extension for data URL media type or empty string if media type is not recognized
+func (d *DataURL) Extension() string {
+	if ext, ok := mediaTypeExtensions[d.MediaType]; ok {
+		return ext
+	}
+	if strings.HasSuffix(d.MediaType, "+json") {
+		return ".json"
+	}
+	if strings.HasSuffix(d.MediaType, "+yaml") {
+		return ".yaml"
+	}
+	return ""
+}
+
+//parseURL parses resource URL, data URL keeps media type and payload as opaque part
+func parseURL(URL string) (*url.URL, error) {
+	if IsDataURL(URL) {
+		return &url.URL{Scheme: DataScheme, Opaque: URL[len(DataScheme)+1:]}, nil
+	}
+	return storage.Parse(URL)
+}
+
+//abbreviate returns URL shortened for error messages, data URL may carry large payloads
+func abbreviate(URL string) string {
+	if len(URL) > 64 {
+		return URL[:64] + "..."
+	}
+	return URL
+}
@@ -0,0 +1,66 @@
+package url_test
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/url"
+	"strings"
+	"testing"
+)
+
+func TestResource_DataURL(t *testing.T) {
+	{ //base64 JSON
+		URL := "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(`{"k":"v","n":3}`))
+		resource := url.NewResource(URL, "cred.json", "/tmp/data_url.cache")
+		assert.Equal(t, URL, resource.URL)
+		if assert.NotNil(t, resource.ParsedURL) {
+			assert.Equal(t, url.DataScheme, resource.ParsedURL.Scheme)
+			assert.Equal(t, "", resource.ParsedURL.Host)
+			assert.Equal(t, "", resource.ParsedURL.Path)
+		}
+		assert.Equal(t, "application/json", resource.MediaType())
+		assert.False(t, resource.Cachable())
+		var target = map[string]interface{}{}
+		if assert.Nil(t, resource.DecodeInto(&target)) {
+			assert.EqualValues(t, "v", target["k"])
+			assert.EqualValues(t, 3, target["n"])
+		}
+		text, err := resource.DownloadBase64()
+		assert.Nil(t, err)
+		assert.Equal(t, strings.TrimPrefix(URL, "data:application/json;base64,"), text)
+		changed, err := resource.HasChanged()
+		assert.Nil(t, err)
+		assert.False(t, changed)
+	}
+	{ //percent-encoded plain text
+		resource := url.NewResource("data:,Hello%2C%20World%21")
+		assert.Equal(t, "text/plain", resource.MediaType())
+		text, err := resource.DownloadText()
+		assert.Nil(t, err)
+		assert.Equal(t, "Hello, World!", text)
+	}
+	{ //unpadded base64 with charset parameter
+		dataURL, err := url.ParseDataURL("data:text/plain;charset=utf-8;base64,YWJjZA")
+		if assert.Nil(t, err) {
+			assert.Equal(t, "abcd", string(dataURL.Data))
+			assert.Equal(t, "utf-8", dataURL.Params["charset"])
+			assert.True(t, dataURL.Base64)
+		}
+	}
+
+	var malformed = []struct {
+		description string
+		URL         string
+		expect      string
+	}{
+		{"missing separator", "data:text/plain;base64", "missing ','"},
+		{"invalid base64", "data:application/json;base64,e30*", "base64"},
+		{"invalid percent encoding", "data:,abc%zz", "percent-encoded"},
+	}
+	for _, useCase := range malformed {
+		_, err := url.NewResource(useCase.URL).Download()
+		if assert.NotNil(t, err, useCase.description) {
+			assert.True(t, strings.Contains(err.Error(), useCase.expect), err.Error())
+		}
+	}
+}
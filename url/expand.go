@@ -0,0 +1,99 @@
+package url
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/viant/toolbox"
+)
+
+//ExpandPlaceholders expands ${key} placeholders with vars values, dotted keys navigate nested maps, environment variables are used otherwise,
+//placeholders can be nested i.e. ${DB_${ENV}}, $${literal} is an escape producing ${literal},
+//unresolved placeholders are kept or reported with error when failOnUnresolved is set
+func ExpandPlaceholders(text string, vars map[string]interface{}, failOnUnresolved bool) (string, error) {
+	var unresolved = make([]string, 0)
+	result := expandPlaceholders(text, vars, &unresolved)
+	if failOnUnresolved && len(unresolved) > 0 {
+		return "", fmt.Errorf("unresolved placeholders: %v", strings.Join(unresolved, ", "))
+	}
+	return result, nil
+}
+
+func expandPlaceholders(text string, vars map[string]interface{}, unresolved *[]string) string {
+	if !strings.Contains(text, "${") {
+		return text
+	}
+	var result = new(bytes.Buffer)
+	for i := 0; i < len(text); {
+		escaped := strings.HasPrefix(text[i:], "$${")
+		if !escaped && !strings.HasPrefix(text[i:], "${") {
+			result.WriteByte(text[i])
+			i++
+			continue
+		}
+		if escaped {
+			i++
+		}
+		end := matchingBrace(text, i+1)
+		if end == -1 {
+			result.WriteString(text[i:])
+			break
+		}
+		if escaped {
+			result.WriteString(text[i : end+1])
+			i = end + 1
+			continue
+		}
+		key := expandPlaceholders(text[i+2:end], vars, unresolved)
+		if value, ok := lookupPlaceholder(key, vars); ok {
+			result.WriteString(value)
+		} else {
+			*unresolved = append(*unresolved, "${"+key+"}")
+			result.WriteString("${" + key + "}")
+		}
+		i = end + 1
+	}
+	return result.String()
+}
+
+//matchingBrace returns index of '}' closing '{' at supplied position or -1
+func matchingBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func lookupPlaceholder(key string, vars map[string]interface{}) (string, bool) {
+	if value, ok := vars[key]; ok {
+		return toolbox.AsString(value), true
+	}
+	if strings.Contains(key, ".") && len(vars) > 0 {
+		var value interface{} = vars
+		found := true
+		for _, fragment := range strings.Split(key, ".") {
+			if value == nil || !toolbox.IsMap(value) {
+				found = false
+				break
+			}
+			if value, found = toolbox.AsMap(value)[fragment]; !found {
+				break
+			}
+		}
+		if found {
+			return toolbox.AsString(value), true
+		}
+	}
+	return os.LookupEnv(key)
+}
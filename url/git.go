@@ -0,0 +1,317 @@
+package url
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/viant/toolbox/storage"
+)
+
+//GitScheme represents git repository file URL scheme: git://host/org/repo.git?path=schemas/event.json&ref=v1.4.2
+const GitScheme = "git"
+
+//defaultGitRef represents git ref used if URL does not define one
+const defaultGitRef = "HEAD"
+
+//Git hosting kinds
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+)
+
+//GitURL represents a parsed git repository file URL
+type GitURL struct {
+	Host       string //repository host, i.e. github.com
+	Repository string //repository path without .git suffix, i.e. org/repo
+	Path       string //file path within repository
+	Ref        string //branch, tag or commit, HEAD if not specified
+}
+
+//GitRawEndpoint represents git hosting raw content endpoint
+type GitRawEndpoint struct {
+	Kind       string //github, gitlab or bitbucket
+	RawBaseURL string //raw content base URL, i.e. https://raw.githubusercontent.com, API base URL is used if empty
+	APIBaseURL string //API base URL used for ref lookup, i.e. https://api.github.com
+}
+
+//gitRawEndpoints represents raw content endpoints by git host
+var gitRawEndpoints = map[string]*GitRawEndpoint{
+	"github.com":    {Kind: GitHub, RawBaseURL: "https://raw.githubusercontent.com", APIBaseURL: "https://api.github.com"},
+	"gitlab.com":    {Kind: GitLab, APIBaseURL: "https://gitlab.com/api/v4"},
+	"bitbucket.org": {Kind: Bitbucket, APIBaseURL: "https://api.bitbucket.org/2.0"},
+}
+var gitRawEndpointsMutex = &sync.RWMutex{}
+
+//RegisterGitRawEndpoint registers raw content endpoint for git host, i.e. self-hosted gitlab
+func RegisterGitRawEndpoint(host string, endpoint *GitRawEndpoint) {
+	gitRawEndpointsMutex.Lock()
+	defer gitRawEndpointsMutex.Unlock()
+	gitRawEndpoints[strings.ToLower(host)] = endpoint
+}
+
+func lookupGitRawEndpoint(host string) *GitRawEndpoint {
+	gitRawEndpointsMutex.RLock()
+	defer gitRawEndpointsMutex.RUnlock()
+	return gitRawEndpoints[strings.ToLower(host)]
+}
+
+//GitRefNotFoundError represents git ref that does not exist in repository
+type GitRefNotFoundError struct {
+	URL string //resource URL
+	Ref string //git ref
+}
+
+//Error returns error message
+func (e *GitRefNotFoundError) Error() string {
+	return fmt.Sprintf("git ref not found: %v, %v", e.Ref, e.URL)
+}
+
+//GitPathNotFoundError represents file path that does not exist at git ref
+type GitPathNotFoundError struct {
+	URL  string //resource URL
+	Path string //file path
+	Ref  string //git ref
+}
+
+//Error returns error message
+func (e *GitPathNotFoundError) Error() string {
+	return fmt.Sprintf("git path not found: %v at %v, %v", e.Path, e.Ref, e.URL)
+}
+
+//IsGitURL returns true if URL uses git scheme
+func IsGitURL(URL string) bool {
+	return strings.HasPrefix(strings.ToLower(URL), GitScheme+"://")
+}
+
+//ParseGitURL parses git repository file URL
+func ParseGitURL(URL string) (*GitURL, error) {
+	if !IsGitURL(URL) {
+		return nil, fmt.Errorf("not a git URL: %v", URL)
+	}
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	query := parsed.Query()
+	result := &GitURL{
+		Host:       parsed.Host,
+		Repository: strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".git"),
+		Path:       strings.Trim(query.Get("path"), "/"),
+		Ref:        query.Get("ref"),
+	}
+	if result.Host == "" || result.Repository == "" {
+		return nil, fmt.Errorf("invalid git URL: %v, expected git://host/org/repo.git", URL)
+	}
+	if result.Path == "" {
+		return nil, fmt.Errorf("invalid git URL: %v, path parameter was empty", URL)
+	}
+	if result.Ref == "" {
+		result.Ref = defaultGitRef
+	}
+	return result, nil
+}
+
+//URL returns git URL
+func (g *GitURL) URL() string {
+	query := url.Values{}
+	query.Set("path", g.Path)
+	if g.Ref != defaultGitRef {
+		query.Set("ref", g.Ref)
+	}
+	return fmt.Sprintf("%v://%v/%v.git?%v", GitScheme, g.Host, g.Repository, query.Encode())
+}
+
+//escapePath returns path with each segment escaped
+func escapePath(location string) string {
+	var segments = strings.Split(location, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+//RawURL returns raw content URL for supplied endpoint
+func (g *GitURL) RawURL(endpoint *GitRawEndpoint) (string, error) {
+	baseURL := endpoint.RawBaseURL
+	if baseURL == "" {
+		baseURL = endpoint.APIBaseURL
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	switch endpoint.Kind {
+	case GitHub:
+		return fmt.Sprintf("%v/%v/%v/%v", baseURL, escapePath(g.Repository), escapePath(g.Ref), escapePath(g.Path)), nil
+	case GitLab:
+		return fmt.Sprintf("%v/projects/%v/repository/files/%v/raw?ref=%v", baseURL, url.PathEscape(g.Repository), url.PathEscape(g.Path), url.QueryEscape(g.Ref)), nil
+	case Bitbucket:
+		return fmt.Sprintf("%v/repositories/%v/src/%v/%v", baseURL, escapePath(g.Repository), url.PathEscape(g.Ref), escapePath(g.Path)), nil
+	}
+	return "", fmt.Errorf("unsupported git hosting kind: %v", endpoint.Kind)
+}
+
+//refURL returns API URL used to check ref existence
+func (g *GitURL) refURL(endpoint *GitRawEndpoint) (string, error) {
+	baseURL := strings.TrimRight(endpoint.APIBaseURL, "/")
+	switch endpoint.Kind {
+	case GitHub:
+		return fmt.Sprintf("%v/repos/%v/commits/%v", baseURL, escapePath(g.Repository), url.PathEscape(g.Ref)), nil
+	case GitLab:
+		return fmt.Sprintf("%v/projects/%v/repository/commits/%v", baseURL, url.PathEscape(g.Repository), url.PathEscape(g.Ref)), nil
+	case Bitbucket:
+		return fmt.Sprintf("%v/repositories/%v/commit/%v", baseURL, escapePath(g.Repository), url.PathEscape(g.Ref)), nil
+	}
+	return "", fmt.Errorf("unsupported git hosting kind: %v", endpoint.Kind)
+}
+
+//gitAuthHeaders returns token authentication headers for git hosting kind
+func gitAuthHeaders(kind, token string) map[string]string {
+	if token == "" {
+		return nil
+	}
+	switch kind {
+	case GitLab:
+		return map[string]string{"PRIVATE-TOKEN": token}
+	case Bitbucket:
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+	return map[string]string{"Authorization": "token " + token}
+}
+
+//NewGitResource returns a new resource for file path at ref in git repository, i.e. NewGitResource("github.com/org/repo", "schemas/event.json", "v1.4.2"),
+//params are passed to NewResource following URL
+func NewGitResource(repository, location, ref string, params ...interface{}) *Resource {
+	repository = strings.TrimPrefix(strings.TrimPrefix(repository, "https://"), GitScheme+"://")
+	gitURL := &GitURL{Repository: strings.TrimSuffix(repository, ".git"), Path: strings.Trim(location, "/"), Ref: ref}
+	if index := strings.Index(gitURL.Repository, "/"); index != -1 {
+		gitURL.Host, gitURL.Repository = gitURL.Repository[:index], gitURL.Repository[index+1:]
+	}
+	if gitURL.Ref == "" {
+		gitURL.Ref = defaultGitRef
+	}
+	return NewResource(append([]interface{}{gitURL.URL()}, params...)...)
+}
+
+//openGit returns git repository file content reader, github, gitlab and bitbucket hosts use raw content endpoint with credentials token,
+//other hosts use git archive --remote if GitArchive is enabled
+func (r *Resource) openGit(ctx context.Context) (io.ReadCloser, error) {
+	gitURL, err := ParseGitURL(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := lookupGitRawEndpoint(gitURL.Host)
+	if endpoint == nil {
+		if !r.GitArchive {
+			return nil, fmt.Errorf("unsupported git host: %v, register raw endpoint with RegisterGitRawEndpoint or enable GitArchive", gitURL.Host)
+		}
+		content, err := gitArchive(ctx, r.URL, gitURL)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	credential, err := r.LoadCredentialConfig(false)
+	if err != nil {
+		return nil, err
+	}
+	token := credential.Token
+	if token == "" {
+		token = credential.Password
+	}
+	rawURL, err := gitURL.RawURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := r.openGitEndpoint(ctx, rawURL, gitAuthHeaders(endpoint.Kind, token))
+	if err == nil {
+		return reader, nil
+	}
+	if statusError, ok := err.(*storage.HTTPStatusError); !ok || statusError.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+	refURL, refErr := gitURL.refURL(endpoint)
+	if refErr != nil {
+		return nil, err
+	}
+	refReader, refErr := r.openGitEndpoint(ctx, refURL, gitAuthHeaders(endpoint.Kind, token))
+	if refErr == nil {
+		_ = refReader.Close()
+		return nil, &GitPathNotFoundError{URL: r.URL, Path: gitURL.Path, Ref: gitURL.Ref}
+	}
+	if statusError, ok := refErr.(*storage.HTTPStatusError); ok && (statusError.StatusCode == http.StatusNotFound || statusError.StatusCode == http.StatusUnprocessableEntity) {
+		return nil, &GitRefNotFoundError{URL: r.URL, Ref: gitURL.Ref}
+	}
+	return nil, err
+}
+
+//openGitEndpoint opens raw content or API URL with resource http settings and supplied auth headers
+func (r *Resource) openGitEndpoint(ctx context.Context, URL string, headers map[string]string) (io.ReadCloser, error) {
+	endpoint := r.Clone()
+	endpoint.URL = URL
+	endpoint.ParsedURL = nil
+	endpoint.Credentials = ""
+	endpoint.CredentialPaths = nil
+	endpoint.UseCredentialAuth = false
+	endpoint.Headers = make(map[string]string)
+	for key, value := range r.Headers {
+		endpoint.Headers[key] = value
+	}
+	for key, value := range headers {
+		endpoint.Headers[key] = value
+	}
+	ctx, err := endpoint.httpContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint.open(ctx)
+}
+
+//gitArchive returns file content extracted from git archive --remote output
+func gitArchive(ctx context.Context, URL string, gitURL *GitURL) ([]byte, error) {
+	remote := fmt.Sprintf("%v://%v/%v.git", GitScheme, gitURL.Host, gitURL.Repository)
+	command := exec.CommandContext(ctx, "git", "archive", "--remote="+remote, gitURL.Ref, gitURL.Path)
+	var stderr = new(bytes.Buffer)
+	command.Stderr = stderr
+	output, err := command.Output()
+	if err != nil {
+		message := stderr.String()
+		switch {
+		case strings.Contains(message, "did not match any files"):
+			return nil, &GitPathNotFoundError{URL: URL, Path: gitURL.Path, Ref: gitURL.Ref}
+		case strings.Contains(message, "no such ref"), strings.Contains(message, "not a valid object name"), strings.Contains(message, "not a tree object"):
+			return nil, &GitRefNotFoundError{URL: URL, Ref: gitURL.Ref}
+		}
+		return nil, fmt.Errorf("failed to run git archive --remote=%v, %v %v", remote, err, strings.TrimSpace(message))
+	}
+	reader := tar.NewReader(bytes.NewReader(output))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, &GitPathNotFoundError{URL: URL, Path: gitURL.Path, Ref: gitURL.Ref}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read git archive, %v", err)
+		}
+		if header.Name == gitURL.Path {
+			return ioutil.ReadAll(reader)
+		}
+	}
+}
+
+//gitExtension returns git URL file path extension
+func (r *Resource) gitExtension() string {
+	gitURL, err := ParseGitURL(r.URL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(path.Ext(gitURL.Path))
+}
@@ -0,0 +1,177 @@
+package url_test
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestGitURL_RawURL(t *testing.T) {
+	var useCases = []struct {
+		description string
+		URL         string
+		endpoint    *url.GitRawEndpoint
+		expect      string
+	}{
+		{
+			description: "github",
+			URL:         "git://github.com/acme/schemas.git?path=schemas/event.json&ref=v1.4.2",
+			endpoint:    &url.GitRawEndpoint{Kind: url.GitHub, RawBaseURL: "https://raw.githubusercontent.com"},
+			expect:      "https://raw.githubusercontent.com/acme/schemas/v1.4.2/schemas/event.json",
+		},
+		{
+			description: "github default ref",
+			URL:         "git://github.com/acme/schemas.git?path=/schemas/event.json",
+			endpoint:    &url.GitRawEndpoint{Kind: url.GitHub, RawBaseURL: "https://raw.githubusercontent.com"},
+			expect:      "https://raw.githubusercontent.com/acme/schemas/HEAD/schemas/event.json",
+		},
+		{
+			description: "gitlab",
+			URL:         "git://gitlab.com/acme/platform/schemas.git?path=schemas/event.json&ref=release/1.4",
+			endpoint:    &url.GitRawEndpoint{Kind: url.GitLab, APIBaseURL: "https://gitlab.com/api/v4"},
+			expect:      "https://gitlab.com/api/v4/projects/acme%2Fplatform%2Fschemas/repository/files/schemas%2Fevent.json/raw?ref=release%2F1.4",
+		},
+		{
+			description: "bitbucket",
+			URL:         "git://bitbucket.org/acme/schemas.git?path=schemas/event.json&ref=v1.4.2",
+			endpoint:    &url.GitRawEndpoint{Kind: url.Bitbucket, APIBaseURL: "https://api.bitbucket.org/2.0/"},
+			expect:      "https://api.bitbucket.org/2.0/repositories/acme/schemas/src/v1.4.2/schemas/event.json",
+		},
+	}
+	for _, useCase := range useCases {
+		gitURL, err := url.ParseGitURL(useCase.URL)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		actual, err := gitURL.RawURL(useCase.endpoint)
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expect, actual, useCase.description)
+		}
+	}
+
+	for _, URL := range []string{"git://github.com/acme/schemas.git", "git://github.com?path=event.json", "https://github.com/acme/schemas.git?path=event.json"} {
+		_, err := url.ParseGitURL(URL)
+		assert.NotNil(t, err, URL)
+	}
+
+	resource := url.NewGitResource("github.com/acme/schemas.git", "schemas/event.json", "v1.4.2")
+	assert.Equal(t, "git://github.com/acme/schemas.git?path=schemas%2Fevent.json&ref=v1.4.2", resource.URL)
+}
+
+func TestResource_DownloadGit(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_git")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	credential := path.Join(parent, "token.json")
+	assert.Nil(t, ioutil.WriteFile(credential, []byte(`{"Token":"secret-token"}`), 0600))
+
+	var files = map[string]string{
+		"/raw/acme/schemas/v1.4.2/schemas/event.json": `{"name":"event"}`,
+		"/raw/acme/private/main/schemas/event.json":   `{"name":"private"}`,
+	}
+	var refs = map[string]bool{
+		"/api/repos/acme/schemas/commits/v1.4.2": true,
+		"/api/repos/acme/private/commits/main":   true,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.Contains(request.URL.Path, "/private/") && request.Header.Get("Authorization") != "token secret-token" {
+			http.NotFound(writer, request)
+			return
+		}
+		if content, ok := files[request.URL.Path]; ok {
+			_, _ = writer.Write([]byte(content))
+			return
+		}
+		if refs[request.URL.Path] {
+			_, _ = writer.Write([]byte(`{}`))
+			return
+		}
+		if strings.HasPrefix(request.URL.Path, "/api/") {
+			writer.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		http.NotFound(writer, request)
+	}))
+	defer server.Close()
+	url.RegisterGitRawEndpoint("git.test", &url.GitRawEndpoint{Kind: url.GitHub, RawBaseURL: server.URL + "/raw", APIBaseURL: server.URL + "/api"})
+
+	var useCases = []struct {
+		description string
+		URL         string
+		credential  string
+		expect      string
+		expectError string
+	}{
+		{
+			description: "public file at tag",
+			URL:         "git://git.test/acme/schemas.git?path=schemas/event.json&ref=v1.4.2",
+			expect:      `{"name":"event"}`,
+		},
+		{
+			description: "private file with token",
+			URL:         "git://git.test/acme/private.git?path=schemas/event.json&ref=main",
+			credential:  credential,
+			expect:      `{"name":"private"}`,
+		},
+		{
+			description: "ref not found",
+			URL:         "git://git.test/acme/schemas.git?path=schemas/event.json&ref=v9.9.9",
+			expectError: "ref",
+		},
+		{
+			description: "path not found",
+			URL:         "git://git.test/acme/schemas.git?path=schemas/missing.json&ref=v1.4.2",
+			expectError: "path",
+		},
+		{
+			description: "unsupported host",
+			URL:         "git://git.unknown/acme/schemas.git?path=schemas/event.json",
+			expectError: "host",
+		},
+	}
+	for _, useCase := range useCases {
+		resource := url.NewResource(useCase.URL, useCase.credential)
+		content, err := resource.Download()
+		switch useCase.expectError {
+		case "":
+			if assert.Nil(t, err, useCase.description) {
+				assert.Equal(t, useCase.expect, string(content), useCase.description)
+			}
+		case "ref":
+			_, ok := err.(*url.GitRefNotFoundError)
+			assert.True(t, ok, fmt.Sprintf("%v: %T %v", useCase.description, err, err))
+		case "path":
+			_, ok := err.(*url.GitPathNotFoundError)
+			assert.True(t, ok, fmt.Sprintf("%v: %T %v", useCase.description, err, err))
+		default:
+			if assert.NotNil(t, err, useCase.description) {
+				assert.True(t, strings.Contains(err.Error(), useCase.expectError), err.Error())
+			}
+		}
+	}
+
+	{ //encrypted token envelope
+		keyFile := path.Join(parent, "key")
+		assert.Nil(t, ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600))
+		resource := url.NewResource("git://git.test/acme/private.git?path=schemas/event.json&ref=main", path.Join(parent, "encrypted.json"))
+		assert.Nil(t, resource.StoreCredential("", "secret-token", keyFile))
+		resource.CredentialKey = keyFile
+		content, err := resource.Download()
+		if assert.Nil(t, err) {
+			assert.Equal(t, `{"name":"private"}`, string(content))
+		}
+	}
+
+	var target = map[string]interface{}{}
+	err := url.NewGitResource("git.test/acme/schemas", "schemas/event.json", "v1.4.2").Decode(&target)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "event", target["name"])
+	}
+}
@@ -0,0 +1,119 @@
+package url
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+//maxProxyClients limits number of cached proxy clients, the oldest client is evicted first
+const maxProxyClients = 64
+
+var proxyClients = make(map[string]*http.Client)
+var proxyClientKeys = make([]string, 0)
+var proxyClientsMutex = &sync.Mutex{}
+
+//hasProxyConfig returns true if resource proxy settings differ from default http client environment proxy
+func (r *Resource) hasProxyConfig() bool {
+	return r.ProxyURL != "" || len(r.NoProxy) > 0 || r.DisableEnvironmentProxy
+}
+
+//httpClient returns http client with resource TLS and proxy configuration, nil if default client applies
+func (r *Resource) httpClient() (*http.Client, error) {
+	tlsConfig := r.tlsConfig()
+	if !r.hasProxyConfig() {
+		if tlsConfig == nil {
+			return nil, nil
+		}
+		return tlsConfig.HTTPClient()
+	}
+	key := r.proxyClientKey(tlsConfig)
+	proxyClientsMutex.Lock()
+	defer proxyClientsMutex.Unlock()
+	if client, ok := proxyClients[key]; ok {
+		return client, nil
+	}
+	proxy, err := r.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	if tlsConfig != nil {
+		if transport.TLSClientConfig, err = tlsConfig.TLSClientConfig(); err != nil {
+			return nil, err
+		}
+	}
+	client := &http.Client{Transport: transport}
+	if len(proxyClientKeys) >= maxProxyClients {
+		evicted := proxyClientKeys[0]
+		proxyClientKeys = proxyClientKeys[1:]
+		proxyClients[evicted].CloseIdleConnections()
+		delete(proxyClients, evicted)
+	}
+	proxyClients[key] = client
+	proxyClientKeys = append(proxyClientKeys, key)
+	return client, nil
+}
+
+//proxyClientKey returns proxy client cache key built from proxy and TLS settings
+func (r *Resource) proxyClientKey(tlsConfig *TLSConfig) string {
+	key := fmt.Sprintf("%q|%q|%v", r.ProxyURL, strings.Join(r.NoProxy, ","), r.DisableEnvironmentProxy)
+	if tlsConfig != nil {
+		key += fmt.Sprintf("|%q|%q|%q|%v|%q", tlsConfig.CAFile, tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.InsecureSkipVerify, tlsConfig.ServerName)
+	}
+	return key
+}
+
+//proxyFunc returns transport proxy function, https requests are tunneled with CONNECT by transport
+func (r *Resource) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	var proxyURL *url.URL
+	if r.ProxyURL != "" {
+		var err error
+		if proxyURL, err = url.Parse(r.ProxyURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %v, %v", r.ProxyURL, err)
+		}
+		if proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %v, missing host", r.ProxyURL)
+		}
+	}
+	noProxy := r.NoProxy
+	useEnvironment := !r.DisableEnvironmentProxy
+	return func(request *http.Request) (*url.URL, error) {
+		if matchesNoProxy(request.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		if proxyURL != nil {
+			return proxyURL, nil
+		}
+		if useEnvironment {
+			return http.ProxyFromEnvironment(request)
+		}
+		return nil, nil
+	}, nil
+}
+
+//matchesNoProxy returns true if host matches any no proxy entry: *, host, IP or domain suffix with optional leading dot
+func matchesNoProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	for _, candidate := range noProxy {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if candidate == "" {
+			continue
+		}
+		if candidate == "*" {
+			return true
+		}
+		if candidateHost, _, err := net.SplitHostPort(candidate); err == nil {
+			candidate = candidateHost
+		}
+		domain := strings.TrimPrefix(candidate, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package url_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/url"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type proxyRecorder struct {
+	mutex         sync.Mutex
+	requests      []string
+	authorization []string
+}
+
+func (p *proxyRecorder) record(request *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.requests = append(p.requests, request.Method+" "+request.RequestURI)
+	p.authorization = append(p.authorization, request.Header.Get("Proxy-Authorization"))
+}
+
+func (p *proxyRecorder) reset() ([]string, []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	requests, authorization := p.requests, p.authorization
+	p.requests, p.authorization = nil, nil
+	return requests, authorization
+}
+
+func (p *proxyRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	p.record(request)
+	if request.Method == http.MethodConnect {
+		target, err := net.Dial("tcp", request.Host)
+		if err != nil {
+			writer.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+		conn, _, err := writer.(http.Hijacker).Hijack()
+		if err != nil {
+			_ = target.Close()
+			return
+		}
+		go func() {
+			_, _ = io.Copy(target, conn)
+			_ = target.Close()
+		}()
+		_, _ = io.Copy(conn, target)
+		_ = conn.Close()
+		return
+	}
+	_, _ = writer.Write([]byte("proxied " + request.URL.Path))
+}
+
+func TestResource_Proxy(t *testing.T) {
+	recorder := &proxyRecorder{}
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("direct " + request.URL.Path))
+	}))
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("tls " + request.URL.Path))
+	}))
+	defer tlsServer.Close()
+	proxyWithAuth := strings.Replace(proxy.URL, "http://", "http://bob:secret@", 1)
+	basicAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("bob:secret"))
+	host := strings.TrimPrefix(server.URL, "http://")
+	tlsHost := strings.TrimPrefix(tlsServer.URL, "https://")
+
+	var useCases = []struct {
+		description   string
+		URL           string
+		proxyURL      string
+		noProxy       []string
+		expect        string
+		expectRequest string
+		expectAuth    string
+	}{
+		{
+			description:   "http absolute form request",
+			URL:           server.URL + "/config.json",
+			proxyURL:      proxy.URL,
+			expect:        "proxied /config.json",
+			expectRequest: "GET " + server.URL + "/config.json",
+		},
+		{
+			description:   "http request with proxy auth",
+			URL:           server.URL + "/config.json",
+			proxyURL:      proxyWithAuth,
+			expect:        "proxied /config.json",
+			expectRequest: "GET " + server.URL + "/config.json",
+			expectAuth:    basicAuth,
+		},
+		{
+			description:   "https CONNECT tunnel",
+			URL:           tlsServer.URL + "/config.json",
+			proxyURL:      proxyWithAuth,
+			expect:        "tls /config.json",
+			expectRequest: "CONNECT " + tlsHost,
+			expectAuth:    basicAuth,
+		},
+		{
+			description: "no proxy host",
+			URL:         server.URL + "/config.json",
+			proxyURL:    proxy.URL,
+			noProxy:     []string{"example.com", host},
+			expect:      "direct /config.json",
+		},
+		{
+			description: "no proxy wildcard",
+			URL:         tlsServer.URL + "/config.json",
+			proxyURL:    proxy.URL,
+			noProxy:     []string{"*"},
+			expect:      "tls /config.json",
+		},
+	}
+	for _, useCase := range useCases {
+		resource := url.NewResource(useCase.URL, url.WithProxy(useCase.proxyURL, useCase.noProxy...))
+		resource.TLSConfig = &url.TLSConfig{InsecureSkipVerify: true}
+		content, err := resource.Download()
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, string(content), useCase.description)
+		requests, authorization := recorder.reset()
+		if useCase.expectRequest == "" {
+			assert.Equal(t, 0, len(requests), useCase.description)
+			continue
+		}
+		if assert.Equal(t, 1, len(requests), useCase.description) {
+			assert.Equal(t, useCase.expectRequest, requests[0], useCase.description)
+			assert.Equal(t, useCase.expectAuth, authorization[0], useCase.description)
+		}
+	}
+
+	resource := url.NewResource(server.URL+"/config.json", url.WithProxy(proxy.URL))
+	info, err := resource.Stat()
+	if assert.Nil(t, err) {
+		assert.True(t, info.Exists)
+	}
+	requests, _ := recorder.reset()
+	if assert.Equal(t, 1, len(requests)) {
+		assert.Equal(t, "HEAD "+server.URL+"/config.json", requests[0])
+	}
+
+	_, err = url.NewResource(server.URL, url.WithProxy("proxy:3128")).Download()
+	assert.NotNil(t, err)
+
+	for i := 0; i < 3; i++ { //proxy client is selected by TLS settings
+		resource := url.NewResource(tlsServer.URL+"/config.json", url.WithProxy(proxy.URL))
+		resource.TLSConfig = &url.TLSConfig{InsecureSkipVerify: true}
+		content, err := resource.Download()
+		if assert.Nil(t, err) {
+			assert.Equal(t, "tls /config.json", string(content))
+		}
+		resource.TLSConfig = &url.TLSConfig{ServerName: "resource.invalid"}
+		_, err = resource.Download()
+		assert.NotNil(t, err, "verified TLS settings do not reuse insecure client")
+	}
+	for i := 0; i < 100; i++ { //proxy client cache is bounded
+		resource := url.NewResource(server.URL+"/config.json", url.WithProxy(proxy.URL, fmt.Sprintf("host%v.invalid", i)))
+		_, err = resource.Download()
+		assert.Nil(t, err)
+	}
+	recorder.reset()
+}
+
+//TestResource_EnvironmentProxy runs in a child process as http.ProxyFromEnvironment reads environment once per process
+func TestResource_EnvironmentProxy(t *testing.T) {
+	if os.Getenv("RESOURCE_ENV_PROXY_CHILD") == "1" {
+		resource := &url.Resource{URL: "http://resource.invalid/config.json"}
+		content, err := resource.Download()
+		if assert.Nil(t, err, "zero value resource uses environment proxy") {
+			assert.Equal(t, "proxied /config.json", string(content))
+		}
+		resource = &url.Resource{URL: "http://resource.invalid/config.json", DisableEnvironmentProxy: true}
+		_, err = resource.Download()
+		assert.NotNil(t, err, "environment proxy is disabled")
+		return
+	}
+	recorder := &proxyRecorder{}
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+	command := exec.Command(os.Args[0], "-test.run=^TestResource_EnvironmentProxy$")
+	command.Env = append(os.Environ(), "RESOURCE_ENV_PROXY_CHILD=1", "HTTP_PROXY="+proxy.URL, "http_proxy="+proxy.URL, "NO_PROXY=", "no_proxy=")
+	output, err := command.CombinedOutput()
+	assert.Nil(t, err, string(output))
+	requests, _ := recorder.reset()
+	assert.EqualValues(t, []string{"GET http://resource.invalid/config.json"}, requests)
+}
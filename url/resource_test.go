@@ -1,15 +1,28 @@
 package url_test
 
 import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewResource(t *testing.T) {
@@ -174,3 +187,848 @@ func TestResource_DecoderFactory(t *testing.T) {
 		assert.NotNil(t, factory)
 	}
 }
+
+func TestResource_DownloadWithContext(t *testing.T) {
+	var release = make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/slow-header":
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+		case "/slow-body":
+			_, _ = writer.Write([]byte("partial"))
+			writer.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+		}
+		_, _ = writer.Write([]byte("done"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	{ //context deadline while waiting for response
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		started := time.Now()
+		_, err := url.NewResource(server.URL + "/slow-header").DownloadWithContext(ctx)
+		cancel()
+		assert.NotNil(t, err)
+		assert.True(t, time.Now().Sub(started) < 2*time.Second)
+	}
+	{ //timeout aborts body read
+		resource := url.NewResource(server.URL + "/slow-body")
+		resource.TimeoutMs = 100
+		started := time.Now()
+		_, err := resource.Download()
+		assert.NotNil(t, err)
+		assert.True(t, time.Now().Sub(started) < 2*time.Second)
+	}
+	{
+		content, err := url.NewResource(server.URL + "/fast").DownloadWithContext(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "done", string(content))
+	}
+	{ //cancelled context fails file download
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := url.NewResource("resource_test.go").DownloadWithContext(ctx)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestResource_DownloadWithRetry(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempt := atomic.AddInt32(&attempts, 1)
+		switch {
+		case request.URL.Path == "/missing.json":
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		case attempt == 1:
+			writer.Header().Set("Retry-After", "3")
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		case attempt == 2:
+			writer.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = writer.Write([]byte(`{"name":"config"}`))
+	}))
+	defer server.Close()
+
+	var elapsed time.Duration
+	policy := &storage.RetryPolicy{MaxAttempts: 4, InitialDelay: 100 * time.Millisecond, Multiplier: 2, MaxDelay: time.Second, Sleeper: func(delay time.Duration) {
+		elapsed += delay
+	}}
+	resource := url.NewResource(server.URL + "/config.json")
+	resource.Retry = policy
+	var config = make(map[string]interface{})
+	if assert.Nil(t, resource.JSONDecode(&config)) {
+		assert.Equal(t, "config", config["name"])
+	}
+	assert.EqualValues(t, 3, attempts)
+	assert.Equal(t, 3*time.Second+200*time.Millisecond, elapsed)
+
+	atomic.StoreInt32(&attempts, 10)
+	elapsed = 0
+	resource = url.NewResource(server.URL + "/missing.json")
+	resource.Retry = policy
+	_, err := resource.Download()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 11, attempts)
+	assert.EqualValues(t, 0, elapsed)
+}
+
+func TestResource_Headers(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received = request.Header
+		_, _ = writer.Write([]byte(`{"name":"config"}`))
+	}))
+	defer server.Close()
+
+	{
+		resource := url.NewResource(server.URL+"/config.json", url.WithHeader("Authorization", "Bearer abc"))
+		resource.Headers["X-Api-Key"] = "key1"
+		var config = make(map[string]interface{})
+		if assert.Nil(t, resource.JSONDecode(&config)) {
+			assert.Equal(t, "config", config["name"])
+		}
+		assert.Equal(t, "Bearer abc", received.Get("Authorization"))
+		assert.Equal(t, "key1", received.Get("X-Api-Key"))
+		text, err := resource.Clone().DownloadText()
+		assert.Nil(t, err)
+		assert.Equal(t, `{"name":"config"}`, text)
+		assert.Equal(t, "key1", received.Get("X-Api-Key"))
+	}
+	{ //headers loaded with resource config
+		resource := &url.Resource{}
+		err := json.Unmarshal([]byte(`{"URL":"`+server.URL+`/config.json","Headers":{"X-Api-Key":"key2"}}`), resource)
+		if assert.Nil(t, err) {
+			_, err = resource.Download()
+			assert.Nil(t, err)
+			assert.Equal(t, "key2", received.Get("X-Api-Key"))
+		}
+	}
+	{ //credentials basic auth
+		credentials := path.Join(os.TempDir(), "resource_headers_cred.json")
+		defer os.Remove(credentials)
+		assert.Nil(t, ioutil.WriteFile(credentials, []byte(`{"Username":"bob","Password":"secret"}`), 0600))
+		resource := url.NewResource(server.URL+"/config.json", credentials)
+		resource.UseCredentialAuth = true
+		_, err := resource.Download()
+		assert.Nil(t, err)
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("bob:secret")), received.Get("Authorization"))
+	}
+	{ //base64 download with headers
+		resource := url.NewResource(server.URL+"/config.json", url.WithHeader("X-Api-Key", "key3"))
+		text, err := resource.DownloadBase64()
+		assert.Nil(t, err)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"name":"config"}`)), text)
+		assert.Equal(t, "key3", received.Get("X-Api-Key"))
+	}
+}
+
+func TestResource_DecodeInto(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_decode_into")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+
+	var useCases = []struct {
+		description string
+		filename    string
+		content     string
+		hasError    bool
+	}{
+		{"json", "config.json", `{"name":"abc","port":8080}`, false},
+		{"yaml", "config.yaml", "name: abc\nport: 8080\n", false},
+		{"yml", "config.yml", "name: abc\nport: 8080\n", false},
+		{"properties", "config.properties", "# comment\nname = abc\n! comment\nport: 80\\\n  80\n", false},
+		{"toml without registered decoder", "config.toml", "name = \"abc\"\nport = 8080\n", true},
+		{"unknown extension", "config.xyz", "name: abc\n", true},
+		{"extensionless json", "config", "  {\"name\":\"abc\",\"port\":8080}", false},
+		{"extensionless yaml", "config_yaml", "name: abc\nport: 8080\n", false},
+	}
+	for _, useCase := range useCases {
+		filename := path.Join(parent, useCase.filename)
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(useCase.content), 0644), useCase.description)
+		var config = struct {
+			Name string
+			Port int
+		}{}
+		err := url.NewResource(filename).DecodeInto(&config)
+		if useCase.hasError {
+			if assert.NotNil(t, err, useCase.description) {
+				assert.True(t, strings.Contains(err.Error(), path.Ext(useCase.filename)), useCase.description)
+			}
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, "abc", config.Name, useCase.description)
+		assert.EqualValues(t, 8080, config.Port, useCase.description)
+	}
+
+	{ //toml with registered decoder
+		url.RegisterDecoderFactory(".toml", toolbox.NewPropertiesDecoderFactory())
+		var config = map[string]interface{}{}
+		err := url.NewResource(path.Join(parent, "config.toml")).DecodeInto(&config)
+		if assert.Nil(t, err) {
+			assert.EqualValues(t, "8080", config["port"])
+		}
+	}
+
+	{ //extensionless http URL with query parameters and JSON content type
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Type", "application/json")
+			_, _ = writer.Write([]byte(`[{"name":"abc","port":8080}]`))
+		}))
+		defer server.Close()
+		var configs = make([]map[string]interface{}, 0)
+		err := url.NewResource(server.URL + "/config?env=prod").DecodeInto(&configs)
+		if assert.Nil(t, err) && assert.Equal(t, 1, len(configs)) {
+			assert.EqualValues(t, "abc", configs[0]["name"])
+		}
+		var config = map[string]interface{}{}
+		err = url.NewResource(server.URL + "/config.yaml?env=prod").Decode(&config)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestResource_Join(t *testing.T) {
+	var useCases = []struct {
+		description string
+		base        string
+		relative    string
+		expect      string
+	}{
+		{"file sibling", "file:///etc/app/config/app.yaml", "./secrets.json", "file:///etc/app/config/secrets.json"},
+		{"file dot-dot", "file:///etc/app/config/app.yaml", "../common/base.yaml", "file:///etc/app/common/base.yaml"},
+		{"file directory base", "file:///etc/app/config", "../common/base.yaml", "file:///etc/app/common/base.yaml"},
+		{"file rooted", "file:///etc/app/config/app.yaml", "/opt/base.yaml", "file:///opt/base.yaml"},
+		{"http dot-dot", "https://user@example.com:8443/v1/config/app.json?x=1", "../../common/base.yaml", "https://user@example.com:8443/common/base.yaml"},
+		{"http rooted", "http://example.com/v1/config/app.json", "/static/base.json?v=2", "http://example.com/static/base.json?v=2"},
+		{"s3 sibling", "s3://bucket/folder/app.json", "secrets.json", "s3://bucket/folder/secrets.json"},
+		{"absolute override", "s3://bucket/folder/app.json", "gs://other/base.json", "gs://other/base.json"},
+	}
+	for _, useCase := range useCases {
+		base := url.NewResource(useCase.base, "cred.json")
+		resource, err := base.Join(useCase.relative)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, resource.URL, useCase.description)
+		assert.Equal(t, useCase.expect, resource.ParsedURL.String(), useCase.description)
+		if useCase.description == "absolute override" {
+			assert.Equal(t, "", resource.Credentials, useCase.description)
+		} else {
+			assert.Equal(t, "cred.json", resource.Credentials, useCase.description)
+		}
+	}
+	_, err := url.NewResource("data:,abc").Join("x.json")
+	assert.NotNil(t, err)
+}
+
+func TestResource_Parent(t *testing.T) {
+	assert.Equal(t, "file:///etc/app", url.NewResource("file:///etc/app/config.yaml").Parent().URL)
+	assert.Equal(t, "file:///etc", url.NewResource("file:///etc/app/").Parent().URL)
+	assert.Equal(t, "https://example.com/v1", url.NewResource("https://example.com/v1/config.json?x=1").Parent().URL)
+	assert.Equal(t, "https://example.com/", url.NewResource("https://example.com/v1").Parent().URL)
+	assert.Nil(t, url.NewResource("data:,abc").Parent())
+}
+
+func TestResource_StoreCredential(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_credential")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	keyFile := path.Join(parent, "key")
+	assert.Nil(t, ioutil.WriteFile(keyFile, []byte("0123456789abcdef0123456789abcdef"), 0600))
+	otherKeyFile := path.Join(parent, "other")
+	assert.Nil(t, ioutil.WriteFile(otherKeyFile, []byte("fedcba9876543210fedcba9876543210"), 0600))
+
+	resource := url.NewResource("https://example.com/app.json", path.Join(parent, "secret.json"))
+	assert.Nil(t, resource.StoreCredential("adrian", "abc", keyFile))
+	content, err := ioutil.ReadFile(resource.Credentials)
+	if assert.Nil(t, err) {
+		assert.False(t, strings.Contains(string(content), "adrian"))
+	}
+	username, password, err := resource.LoadCredential(keyFile)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "adrian", username)
+		assert.Equal(t, "abc", password)
+	}
+	_, _, err = resource.LoadCredential(otherKeyFile)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "authentication failed"), err.Error())
+	}
+
+	legacy := url.NewResource("https://example.com/app.json", path.Join(parent, "legacy.json"))
+	assert.Nil(t, ioutil.WriteFile(legacy.Credentials, []byte(`{"Username":"adrian","Password":"abc"}`), 0644))
+	username, password, err = legacy.LoadCredential("")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "adrian", username)
+		assert.Equal(t, "abc", password)
+	}
+}
+
+func TestResource_Checksum(t *testing.T) {
+	payload := []byte(`{"name":"abc"}`)
+	sha256Digest := fmt.Sprintf("%x", sha256.Sum256(payload))
+	md5Digest := fmt.Sprintf("%x", md5.Sum(payload))
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/app.json", "/nosidecar.json", "/corrupted.json":
+			_, _ = writer.Write(payload)
+		case "/app.json.sha256":
+			_, _ = writer.Write([]byte(sha256Digest + "  app.json\n"))
+		case "/corrupted.json.sha256":
+			_, _ = writer.Write([]byte(strings.Repeat("0", 64)))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	{ //matching digests
+		resource := url.NewResource(server.URL+"/app.json", url.WithChecksum("sha256", strings.ToUpper(sha256Digest)), url.WithChecksum("MD5", md5Digest))
+		content, err := resource.Download()
+		assert.Nil(t, err)
+		assert.Equal(t, payload, content)
+	}
+	{ //mismatching digest
+		resource := url.NewResource(server.URL+"/app.json", url.WithChecksum("md5", strings.Repeat("f", 32)))
+		_, err := resource.Download()
+		mismatch, ok := err.(*url.ChecksumMismatchError)
+		if assert.True(t, ok, fmt.Sprintf("%v", err)) {
+			assert.Equal(t, url.ChecksumMD5, mismatch.Algorithm)
+			assert.Equal(t, strings.Repeat("f", 32), mismatch.Expected)
+			assert.Equal(t, md5Digest, mismatch.Actual)
+		}
+		var target = map[string]interface{}{}
+		assert.NotNil(t, resource.DecodeInto(&target))
+		assert.Equal(t, 0, len(target))
+	}
+	{ //matching sidecar
+		resource := url.NewResource(server.URL + "/app.json")
+		resource.VerifySidecar = true
+		var target = map[string]interface{}{}
+		assert.Nil(t, resource.DecodeInto(&target))
+		assert.EqualValues(t, "abc", target["name"])
+	}
+	{ //mismatching sidecar
+		resource := url.NewResource(server.URL + "/corrupted.json")
+		resource.VerifySidecar = true
+		_, err := resource.Download()
+		_, ok := err.(*url.ChecksumMismatchError)
+		assert.True(t, ok, fmt.Sprintf("%v", err))
+	}
+	{ //missing sidecar
+		resource := url.NewResource(server.URL + "/nosidecar.json")
+		resource.VerifySidecar = true
+		_, err := resource.Download()
+		if assert.NotNil(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "nosidecar.json.sha256"), err.Error())
+		}
+	}
+	{ //unsupported algorithm
+		_, err := url.NewResource(server.URL+"/app.json", url.WithChecksum("crc32", "abc")).Download()
+		assert.NotNil(t, err)
+	}
+}
+
+func TestResource_EncodeInto(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_encode_into")
+	_ = os.RemoveAll(parent)
+	defer os.RemoveAll(parent)
+	var source = map[string]interface{}{"name": "abc", "port": 8080}
+	for _, URL := range []string{
+		path.Join(parent, "nested", "config.json"),
+		"mem:///resource_encode_into/config.json",
+	} {
+		resource := url.NewResource(URL)
+		if !assert.Nil(t, resource.EncodeInto(source), URL) {
+			continue
+		}
+		var target = map[string]interface{}{}
+		if assert.Nil(t, url.NewResource(URL).DecodeInto(&target), URL) {
+			assert.EqualValues(t, "abc", target["name"], URL)
+			assert.EqualValues(t, 8080, target["port"], URL)
+		}
+	}
+	assert.NotNil(t, url.NewResource(path.Join(parent, "config.xyz")).EncodeInto(source))
+	assert.NotNil(t, url.NewResource("data:,abc").Upload([]byte("xyz")))
+
+	var uploaded = make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPut {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, _ := ioutil.ReadAll(request.Body)
+		uploaded <- request.Header.Get("X-Token") + ":" + string(body)
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	resource := url.NewResource(server.URL+"/config.json", url.WithHeader("X-Token", "t1"))
+	if assert.Nil(t, resource.Upload([]byte("abc"))) {
+		assert.Equal(t, "t1:abc", <-uploaded)
+	}
+}
+
+func TestResource_DecodeWithExpansion(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_expansion")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	_ = os.Setenv("RESOURCE_EXPANSION_DIR", parent)
+	_ = os.Setenv("RESOURCE_EXPANSION_USER", "env_user")
+	defer os.Unsetenv("RESOURCE_EXPANSION_DIR")
+	defer os.Unsetenv("RESOURCE_EXPANSION_USER")
+
+	{ //URL expansion
+		resource := url.NewResource("file://${RESOURCE_EXPANSION_DIR}/app.yaml")
+		assert.Equal(t, "file://"+parent+"/app.yaml", resource.URL)
+		resource = url.NewResource("file://${RESOURCE_EXPANSION_DIR}/app.yaml", url.WithExpandURL(false))
+		assert.Equal(t, "file://${RESOURCE_EXPANSION_DIR}/app.yaml", resource.URL)
+		resource = url.NewResource("file:///tmp/${RESOURCE_EXPANSION_UNDEFINED}/app.yaml")
+		assert.Equal(t, "file:///tmp/${RESOURCE_EXPANSION_UNDEFINED}/app.yaml", resource.URL)
+	}
+
+	var YAML = `endpoint: "http://${db.host}:${db.port}/${DB_${env}}"
+user: ${RESOURCE_EXPANSION_USER}
+template: "$${literal}"
+`
+	filename := path.Join(parent, "app.json")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"endpoint":"http://${db.host}:${db.port}/${DB_${env}}","user":"${RESOURCE_EXPANSION_USER}","template":"$${literal}"}`), 0644))
+	var vars = map[string]interface{}{
+		"env":     "PROD",
+		"DB_PROD": "sales",
+		"db": map[string]interface{}{
+			"host": "127.0.0.1",
+			"port": 3306,
+		},
+	}
+	expanded, err := url.ExpandPlaceholders(YAML, vars, true)
+	if assert.Nil(t, err) {
+		assert.Equal(t, `endpoint: "http://127.0.0.1:3306/sales"
+user: env_user
+template: "${literal}"
+`, expanded)
+	}
+	var target = map[string]interface{}{}
+	resource := url.NewResource(filename)
+	if assert.Nil(t, resource.DecodeWithExpansion(&target, vars)) {
+		assert.EqualValues(t, "http://127.0.0.1:3306/sales", target["endpoint"])
+		assert.EqualValues(t, "env_user", target["user"])
+		assert.EqualValues(t, "${literal}", target["template"])
+	}
+
+	delete(vars, "DB_PROD")
+	target = map[string]interface{}{}
+	assert.Nil(t, resource.DecodeWithExpansion(&target, vars))
+	assert.EqualValues(t, "http://127.0.0.1:3306/${DB_PROD}", target["endpoint"])
+	resource.FailOnUnresolved = true
+	err = resource.DecodeWithExpansion(&target, vars)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "${DB_PROD}"), err.Error())
+	}
+}
+
+func TestResource_DownloadWithProvider(t *testing.T) {
+	service := storage.NewMemoryService()
+	assert.Nil(t, service.Upload("mem:///resource_provider/config.json", strings.NewReader(`{"name":"abc"}`)))
+	resource := url.NewResource("mem:///resource_provider/config.json")
+	content, err := resource.Download()
+	if assert.Nil(t, err) {
+		assert.Equal(t, `{"name":"abc"}`, string(content))
+	}
+	var target = map[string]interface{}{}
+	if assert.Nil(t, resource.DecodeInto(&target)) {
+		assert.EqualValues(t, "abc", target["name"])
+	}
+
+	_, err = url.NewResource("nosuchscheme://bucket/config.json").Download()
+	_, ok := err.(*storage.UnsupportedSchemeError)
+	assert.True(t, ok, fmt.Sprintf("%T %v", err, err))
+
+	storage.RegisterProvider("failingprovider", func(credentialFile string) (storage.Service, error) {
+		return nil, fmt.Errorf("invalid credentials: %v", credentialFile)
+	})
+	_, err = url.NewResource("failingprovider://bucket/config.json", "/tmp/secret.json").Download()
+	providerError, ok := err.(*storage.ProviderError)
+	if assert.True(t, ok, fmt.Sprintf("%T %v", err, err)) {
+		assert.Equal(t, "failingprovider", providerError.Scheme)
+		assert.True(t, strings.Contains(providerError.Err.Error(), "/tmp/secret.json"))
+	}
+}
+
+func TestResource_OpenReader(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_open_reader")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+
+	filename := path.Join(parent, "large.json")
+	file, err := os.Create(filename)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, _ = file.WriteString(`{"name":"large","items":[`)
+	item := `"` + strings.Repeat("x", 1000) + `"`
+	const itemCount = 20000
+	for i := 0; i < itemCount; i++ {
+		if i > 0 {
+			_, _ = file.WriteString(",")
+		}
+		_, _ = file.WriteString(item)
+	}
+	_, _ = file.WriteString(`]}`)
+	assert.Nil(t, file.Close())
+	fileInfo, _ := os.Stat(filename)
+
+	{ //streaming decode
+		var target = struct {
+			Name string
+		}{}
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		err = url.NewResource(filename).DecodeInto(&target)
+		runtime.ReadMemStats(&after)
+		assert.Nil(t, err)
+		assert.Equal(t, "large", target.Name)
+		allocated := after.TotalAlloc - before.TotalAlloc
+		//json decoder buffers a single value, buffered download and text copy used to allocate about 7 times the document size
+		assert.True(t, allocated < uint64(4*fileInfo.Size()), fmt.Sprintf("allocated %v for %v bytes", allocated, fileInfo.Size()))
+
+	}
+	{ //stream
+		reader, err := url.NewResource(filename).OpenReader()
+		if assert.Nil(t, err) {
+			written, err := io.Copy(ioutil.Discard, reader)
+			assert.Nil(t, err)
+			assert.Equal(t, fileInfo.Size(), written)
+			assert.Nil(t, reader.Close())
+		}
+	}
+	{ //size limit
+		reader, err := url.NewResource(filename, url.WithMaxSize(1024)).OpenReader()
+		if assert.Nil(t, err) {
+			written, err := io.Copy(ioutil.Discard, reader)
+			_, ok := err.(*url.SizeLimitExceededError)
+			assert.True(t, ok, fmt.Sprintf("%v", err))
+			assert.EqualValues(t, 1024, written)
+			_ = reader.Close()
+		}
+		_, err = url.NewResource(filename, url.WithMaxSize(1024)).Download()
+		_, ok := err.(*url.SizeLimitExceededError)
+		assert.True(t, ok, fmt.Sprintf("%v", err))
+	}
+	{ //checksum
+		reader, err := url.NewResource(filename, url.WithChecksum("md5", strings.Repeat("0", 32))).OpenReader()
+		if assert.Nil(t, err) {
+			_, err := io.Copy(ioutil.Discard, reader)
+			_, ok := err.(*url.ChecksumMismatchError)
+			assert.True(t, ok, fmt.Sprintf("%v", err))
+			_ = reader.Close()
+		}
+	}
+}
+
+func TestResource_YamlDecodeAll(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_yaml_all")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+
+	var useCases = []struct {
+		description string
+		content     string
+		expect      []string
+		hasError    bool
+	}{
+		{
+			description: "three documents",
+			content:     "name: a\n---\nname: b\nports:\n  - 80\n---\nname: c\n",
+			expect:      []string{"a", "b", "c"},
+		},
+		{
+			description: "empty middle document",
+			content:     "---\nname: a\n---\n---\nname: c\n---\n",
+			expect:      []string{"a", "c"},
+		},
+		{
+			description: "syntax error in second document",
+			content:     "name: a\n---\nname: [b\n---\nname: c\n",
+			expect:      []string{"a"},
+			hasError:    true,
+		},
+	}
+	for i, useCase := range useCases {
+		filename := path.Join(parent, fmt.Sprintf("config%v.yaml", i))
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(useCase.content), 0644))
+		var documents = make([]interface{}, 0)
+		err := url.NewResource(filename).YamlDecodeAll(&documents)
+		if useCase.hasError {
+			if assert.NotNil(t, err, useCase.description) {
+				assert.True(t, strings.Contains(err.Error(), "document 1"), err.Error())
+			}
+		} else {
+			assert.Nil(t, err, useCase.description)
+		}
+		if !assert.Equal(t, len(useCase.expect), len(documents), useCase.description) {
+			continue
+		}
+		for j, document := range documents {
+			aMap, ok := document.(map[string]interface{})
+			if assert.True(t, ok, useCase.description) {
+				assert.EqualValues(t, useCase.expect[j], aMap["name"], useCase.description)
+			}
+		}
+	}
+
+	var configs = make([]map[string]interface{}, 0)
+	err := url.NewResource(path.Join(parent, "config0.yaml")).DecodeInto(&configs)
+	if assert.Nil(t, err) && assert.Equal(t, 3, len(configs)) {
+		assert.EqualValues(t, "b", configs[1]["name"])
+		assert.EqualValues(t, []interface{}{80}, configs[1]["ports"])
+	}
+}
+
+func TestResource_JSONDecodeWithOptions(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_json_number")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	filename := path.Join(parent, "ids.json")
+	const document = `{"id":9007199254740993,"items":[{"id":18014398509481985}],"ratio":0.25}`
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(document), 0644))
+
+	{ //float64 default
+		var target = map[string]interface{}{}
+		assert.Nil(t, url.NewResource(filename).JSONDecode(&target))
+		encoded, _ := json.Marshal(target["id"])
+		assert.True(t, string(encoded) != "9007199254740993", string(encoded))
+	}
+	{ //json.Number
+		var target = map[string]interface{}{}
+		assert.Nil(t, url.NewResource(filename).JSONDecodeWithOptions(&target, &url.JSONDecodeOptions{UseNumber: true}))
+		assert.Equal(t, json.Number("9007199254740993"), target["id"])
+		encoded, err := json.Marshal(target)
+		assert.Nil(t, err)
+		assert.Equal(t, document, string(encoded))
+	}
+	{ //normalized numbers
+		var target interface{}
+		assert.Nil(t, url.NewResource(filename).JSONDecodeWithOptions(&target, &url.JSONDecodeOptions{NormalizeNumbers: true}))
+		aMap := toolbox.AsMap(target)
+		assert.Equal(t, int64(9007199254740993), aMap["id"])
+		assert.Equal(t, 0.25, aMap["ratio"])
+		item := toolbox.AsMap(toolbox.AsSlice(aMap["items"])[0])
+		assert.Equal(t, int64(18014398509481985), item["id"])
+		encoded, err := json.Marshal(target)
+		assert.Nil(t, err)
+		assert.Equal(t, document, string(encoded))
+	}
+	{ //resource UseNumber with DecodeInto
+		resource := url.NewResource(filename)
+		resource.UseNumber = true
+		var target = map[string]interface{}{}
+		assert.Nil(t, resource.DecodeInto(&target))
+		assert.Equal(t, json.Number("9007199254740993"), target["id"])
+		assert.Equal(t, 9007199254740993, toolbox.AsInt(target["id"]))
+	}
+}
+
+func TestResource_TLSConfig(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_tls")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("secure"))
+	}))
+	defer server.Close()
+	caFile := path.Join(parent, "ca.pem")
+	assert.Nil(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644))
+
+	{ //unknown authority
+		_, err := url.NewResource(server.URL + "/config.txt").Download()
+		assert.NotNil(t, err)
+	}
+	{ //custom CA
+		resource := url.NewResource(server.URL + "/config.txt")
+		resource.TLSConfig = &url.TLSConfig{CAFile: caFile}
+		content, err := resource.Download()
+		if assert.Nil(t, err) {
+			assert.Equal(t, "secure", string(content))
+		}
+	}
+	{ //insecure skip verify
+		resource := url.NewResource(server.URL + "/config.txt")
+		resource.TLSConfig = &url.TLSConfig{InsecureSkipVerify: true}
+		_, err := resource.Download()
+		assert.Nil(t, err)
+	}
+	{ //missing files
+		resource := url.NewResource(server.URL + "/config.txt")
+		resource.TLSConfig = &url.TLSConfig{CAFile: path.Join(parent, "missing.pem")}
+		_, err := resource.Download()
+		if assert.NotNil(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "missing.pem"), err.Error())
+		}
+		resource.TLSConfig = &url.TLSConfig{CertFile: path.Join(parent, "client.pem"), KeyFile: path.Join(parent, "client.key")}
+		_, err = resource.Download()
+		if assert.NotNil(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "client.pem"), err.Error())
+		}
+	}
+	{ //package default
+		url.SetDefaultTLSConfig(&url.TLSConfig{CAFile: caFile})
+		defer url.SetDefaultTLSConfig(nil)
+		content, err := url.NewResource(server.URL + "/config.txt").Download()
+		if assert.Nil(t, err) {
+			assert.Equal(t, "secure", string(content))
+		}
+	}
+}
+
+func TestResource_MaxSize(t *testing.T) {
+	var body = strings.Repeat("x", 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/chunked" {
+			flusher := writer.(http.Flusher)
+			for i := 0; i < 16; i++ {
+				_, _ = writer.Write([]byte(body[:4096]))
+				flusher.Flush()
+			}
+			return
+		}
+		writer.Header().Set("Content-Length", fmt.Sprintf("%v", len(body)))
+		_, _ = writer.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	{ //content length early reject
+		_, err := url.NewResource(server.URL+"/large.txt", url.WithMaxSize(1024)).OpenReader()
+		sizeError, ok := err.(*url.SizeLimitExceededError)
+		if assert.True(t, ok, fmt.Sprintf("%v", err)) {
+			assert.EqualValues(t, 1024, sizeError.Limit)
+			assert.EqualValues(t, len(body), sizeError.ContentLength)
+		}
+	}
+	{ //unknown content length
+		_, err := url.NewResource(server.URL+"/chunked", url.WithMaxSize(1024)).Download()
+		sizeError, ok := err.(*url.SizeLimitExceededError)
+		if assert.True(t, ok, fmt.Sprintf("%v", err)) {
+			assert.EqualValues(t, 0, sizeError.ContentLength)
+		}
+	}
+	{ //within limit
+		content, err := url.NewResource(server.URL+"/large.txt", url.WithMaxSize(int64(len(body)))).Download()
+		assert.Nil(t, err)
+		assert.Equal(t, len(body), len(content))
+	}
+}
+
+func TestResource_Redirects(t *testing.T) {
+	var authorization atomic.Value
+	authorization.Store("")
+	target := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		authorization.Store(request.Header.Get("Authorization"))
+		_, _ = writer.Write([]byte("target"))
+	}))
+	defer target.Close()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var remaining int
+		if _, err := fmt.Sscanf(request.URL.Path, "/hop/%d", &remaining); err != nil {
+			http.NotFound(writer, request)
+			return
+		}
+		if remaining == 0 {
+			http.Redirect(writer, request, target.URL+"/final", http.StatusFound)
+			return
+		}
+		http.Redirect(writer, request, fmt.Sprintf("%v/hop/%d", server.URL, remaining-1), http.StatusFound)
+	}))
+	defer server.Close()
+
+	var useCases = []struct {
+		description       string
+		hops              int
+		maxRedirects      int
+		disallowRedirects bool
+		expectStatus      int
+		expectLimit       bool
+	}{
+		{description: "chain within default limit", hops: 3},
+		{description: "chain within max redirects", hops: 3, maxRedirects: 4},
+		{description: "chain exceeding max redirects", hops: 3, maxRedirects: 2, expectLimit: true},
+		{description: "chain exceeding default limit", hops: 12, expectLimit: true},
+		{description: "disallowed redirects", hops: 0, disallowRedirects: true, expectStatus: http.StatusFound},
+	}
+	for _, useCase := range useCases {
+		resource := url.NewResource(fmt.Sprintf("%v/hop/%d", server.URL, useCase.hops))
+		resource.MaxRedirects = useCase.maxRedirects
+		resource.DisallowRedirects = useCase.disallowRedirects
+		content, err := resource.Download()
+		switch {
+		case useCase.expectLimit:
+			_, ok := err.(*storage.RedirectLimitError)
+			assert.True(t, ok, fmt.Sprintf("%v: %v", useCase.description, err))
+		case useCase.expectStatus > 0:
+			statusError, ok := err.(*storage.HTTPStatusError)
+			if assert.True(t, ok, fmt.Sprintf("%v: %v", useCase.description, err)) {
+				assert.Equal(t, useCase.expectStatus, statusError.StatusCode, useCase.description)
+			}
+		default:
+			if assert.Nil(t, err, useCase.description) {
+				assert.Equal(t, "target", string(content), useCase.description)
+			}
+		}
+	}
+
+	{ //cross host authorization
+		for _, strip := range []bool{false, true} {
+			resource := url.NewResource(server.URL+"/hop/0", url.WithHeader("Authorization", "Bearer abc"))
+			resource.StripRedirectAuth = strip
+			_, err := resource.Download()
+			assert.Nil(t, err)
+			if strip {
+				assert.Equal(t, "", authorization.Load())
+			} else {
+				assert.Equal(t, "Bearer abc", authorization.Load())
+			}
+		}
+	}
+}
+
+func TestResource_CacheExpiry(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_cache_expiry")
+	_ = os.RemoveAll(parent)
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	source := path.Join(parent, "config.json")
+	cache := path.Join(parent, "config.cache")
+	assert.Nil(t, ioutil.WriteFile(source, []byte("v1"), 0644))
+	resource := url.NewResource(source)
+	resource.Cache = cache
+	resource.CacheExpiryMs = 500
+
+	content, err := resource.Download()
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", string(content))
+	assert.Nil(t, ioutil.WriteFile(source, []byte("v2"), 0644))
+	modified := time.Now().Add(time.Hour)
+	assert.Nil(t, os.Chtimes(source, modified, modified))
+
+	content, err = resource.Download()
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", string(content), "cache is not expired")
+
+	expired := time.Now().Add(-time.Second)
+	assert.Nil(t, os.Chtimes(cache, expired, expired))
+	content, err = resource.Download()
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", string(content), "cache is expired after CacheExpiryMs milliseconds")
+}
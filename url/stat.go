@@ -0,0 +1,114 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+)
+
+//ResourceInfo represents resource metadata obtained without downloading content
+type ResourceInfo struct {
+	URL         string    //resource URL
+	Exists      bool      //false if resource was not found
+	Size        int64     //content size in bytes, -1 if unknown
+	ModTime     time.Time //modification time, zero if unknown
+	ContentType string    //content type, for files and storage objects detected by extension
+	ETag        string    //http entity tag, empty if not reported
+}
+
+//Stat returns resource metadata: HEAD for http and https, os.Stat for file and storage object info for other schemes,
+//not found resource returns info with Exists false and no error
+func (r *Resource) Stat() (*ResourceInfo, error) {
+	if r == nil {
+		return nil, fmt.Errorf("fail to stat empty resource")
+	}
+	ctx := context.Background()
+	if r.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Millisecond*time.Duration(r.TimeoutMs))
+		defer cancel()
+	}
+	return r.stat(ctx)
+}
+
+func (r *Resource) stat(ctx context.Context) (*ResourceInfo, error) {
+	if r.ParsedURL == nil {
+		var err error
+		if r.ParsedURL, err = parseURL(r.URL); err != nil {
+			return nil, err
+		}
+	}
+	result := &ResourceInfo{URL: r.URL, Size: -1}
+	switch r.ParsedURL.Scheme {
+	case DataScheme:
+		dataURL, err := ParseDataURL(r.URL)
+		if err != nil {
+			return nil, err
+		}
+		result.Exists = true
+		result.Size = int64(len(dataURL.Data))
+		result.ContentType = dataURL.MediaType
+		return result, nil
+	case "http", "https":
+		ctx, err := r.httpContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		response, err := storage.HeadWithContext(ctx, r.URL)
+		if err != nil {
+			if statusError, ok := err.(*storage.HTTPStatusError); ok && statusError.StatusCode == http.StatusNotFound {
+				return result, nil
+			}
+			return nil, err
+		}
+		result.Exists = true
+		result.Size = response.ContentLength
+		result.ContentType = response.Header.Get("Content-Type")
+		result.ETag = response.Header.Get("ETag")
+		if lastModified := response.Header.Get("Last-Modified"); lastModified != "" {
+			result.ModTime, _ = http.ParseTime(lastModified)
+		}
+		return result, nil
+	}
+	result.ContentType = mime.TypeByExtension(path.Ext(r.ParsedURL.Path))
+	if r.ParsedURL.Scheme == "file" {
+		fileInfo, err := os.Stat(toolbox.Filename(r.URL))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return result, nil
+			}
+			return nil, err
+		}
+		result.Exists = true
+		result.Size = fileInfo.Size()
+		result.ModTime = fileInfo.ModTime()
+		return result, nil
+	}
+	service, err := r.storageService()
+	if err != nil {
+		return nil, err
+	}
+	exists, err := service.Exists(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return result, nil
+	}
+	object, err := service.StorageObject(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	fileInfo := object.FileInfo()
+	result.Exists = true
+	result.Size = fileInfo.Size()
+	result.ModTime = fileInfo.ModTime()
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package url_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResource_Stat(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_stat")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	modTime := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	filename := path.Join(parent, "config.json")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"name":"abc"}`), 0644))
+	assert.Nil(t, os.Chtimes(filename, modTime, modTime))
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/config.json":
+			writer.Header().Set("Content-Type", "application/json")
+			writer.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+			writer.Header().Set("ETag", `"v1"`)
+			_, _ = writer.Write([]byte(`{"name":"abc"}`))
+		case "/error.json":
+			writer.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer server.Close()
+
+	service := storage.NewMemoryService()
+	assert.Nil(t, service.Upload("mem:///resource_stat/config.json", strings.NewReader(`{"name":"abc"}`)))
+
+	var useCases = []struct {
+		description string
+		URL         string
+		expect      *url.ResourceInfo
+		hasError    bool
+	}{
+		{
+			description: "file",
+			URL:         filename,
+			expect:      &url.ResourceInfo{Exists: true, Size: 14, ModTime: modTime, ContentType: "application/json"},
+		},
+		{
+			description: "missing file",
+			URL:         path.Join(parent, "missing.json"),
+			expect:      &url.ResourceInfo{Size: -1, ContentType: "application/json"},
+		},
+		{
+			description: "http",
+			URL:         server.URL + "/config.json",
+			expect:      &url.ResourceInfo{Exists: true, Size: 14, ModTime: modTime, ContentType: "application/json", ETag: `"v1"`},
+		},
+		{
+			description: "http not found",
+			URL:         server.URL + "/missing.json",
+			expect:      &url.ResourceInfo{Size: -1},
+		},
+		{
+			description: "http error",
+			URL:         server.URL + "/error.json",
+			hasError:    true,
+		},
+		{
+			description: "mem",
+			URL:         "mem:///resource_stat/config.json",
+			expect:      &url.ResourceInfo{Exists: true, Size: 14, ContentType: "application/json"},
+		},
+		{
+			description: "missing mem",
+			URL:         "mem:///resource_stat/missing.json",
+			expect:      &url.ResourceInfo{Size: -1, ContentType: "application/json"},
+		},
+	}
+	for _, useCase := range useCases {
+		resource := url.NewResource(useCase.URL)
+		info, err := resource.Stat()
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect.Exists, info.Exists, useCase.description)
+		assert.Equal(t, useCase.expect.Size, info.Size, useCase.description)
+		assert.Equal(t, useCase.expect.ETag, info.ETag, useCase.description)
+		assert.True(t, strings.HasPrefix(info.ContentType, useCase.expect.ContentType), useCase.description+": "+info.ContentType)
+		if !useCase.expect.ModTime.IsZero() {
+			assert.True(t, useCase.expect.ModTime.Equal(info.ModTime), useCase.description)
+		}
+	}
+}
+
+func TestResource_CacheRevalidation(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_cache_revalidation")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	var lastModified atomic.Value
+	lastModified.Store(time.Now().Add(-time.Hour))
+	var downloads int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Last-Modified", lastModified.Load().(time.Time).UTC().Format(http.TimeFormat))
+		if request.Method == http.MethodGet {
+			atomic.AddInt32(&downloads, 1)
+		}
+		_, _ = writer.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	cache := path.Join(parent, "cache.txt")
+	resource := url.NewResource(server.URL+"/content.txt", "", cache, 1)
+	content, err := resource.Download()
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&downloads))
+
+	expired := time.Now().Add(-time.Minute)
+	assert.Nil(t, os.Chtimes(cache, expired, expired))
+	content, err = resource.Download()
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&downloads), "not modified resource is served from revalidated cache")
+
+	assert.Nil(t, os.Chtimes(cache, expired, expired))
+	lastModified.Store(time.Now())
+	_, err = resource.Download()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&downloads), "modified resource is downloaded")
+}
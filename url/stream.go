@@ -0,0 +1,71 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+//streamPeekSize represents stream head size used for format sniffing and new line delimited JSON detection
+const streamPeekSize = 64 * 1024
+
+//SizeLimitExceededError represents content exceeding resource MaxSize
+type SizeLimitExceededError struct {
+	URL           string //resource URL
+	Limit         int64  //max content size in bytes
+	ContentLength int64  //reported content length for early reject, 0 if limit was exceeded while reading
+}
+
+//Error returns error message
+func (e *SizeLimitExceededError) Error() string {
+	if e.ContentLength > 0 {
+		return fmt.Sprintf("content of %v exceeds size limit: %v bytes, content length: %v", e.URL, e.Limit, e.ContentLength)
+	}
+	return fmt.Sprintf("content of %v exceeds size limit: %v bytes", e.URL, e.Limit)
+}
+
+//WithMaxSize returns an option limiting downloaded content size
+func WithMaxSize(maxSize int64) ResourceOption {
+	return func(resource *Resource) {
+		resource.MaxSize = maxSize
+	}
+}
+
+//streamReader represents content stream enforcing size limit and verifying checksums at the end of stream
+type streamReader struct {
+	io.ReadCloser
+	URL      string
+	limit    int64
+	read     int64
+	verifier *checksumVerifier
+	cancel   context.CancelFunc
+}
+
+//Read reads from underlying stream, it returns *SizeLimitExceededError once limit is exceeded and *ChecksumMismatchError instead of io.EOF on digest mismatch
+func (r *streamReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		if r.limit > 0 && r.read > r.limit {
+			return n - int(r.read-r.limit), &SizeLimitExceededError{URL: r.URL, Limit: r.limit}
+		}
+		if r.verifier != nil {
+			_, _ = r.verifier.Write(p[:n])
+		}
+	}
+	if err == io.EOF && r.verifier != nil {
+		if verifyErr := r.verifier.verify(r.URL); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+//Close closes underlying stream and releases context
+func (r *streamReader) Close() error {
+	err := r.ReadCloser.Close()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
+}
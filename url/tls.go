@@ -0,0 +1,88 @@
+package url
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+//TLSConfig represents resource http client TLS configuration, certificates are loaded with the first download
+type TLSConfig struct {
+	CAFile             string `description:"PEM encoded certificate authority file"` //CAFile custom certificate authority used to verify server certificate
+	CertFile           string `description:"PEM encoded client certificate file"`    //CertFile client certificate for mutual TLS
+	KeyFile            string `description:"PEM encoded client key file"`            //KeyFile client certificate key for mutual TLS
+	InsecureSkipVerify bool   `description:"skip server certificate verification"`   //InsecureSkipVerify disables server certificate verification
+	ServerName         string `description:"server name used for verification"`      //ServerName overrides server name used to verify certificate
+	client             *http.Client
+	mutex              sync.Mutex
+}
+
+//TLSClientConfig returns crypto tls config, load errors report file path that failed
+func (c *TLSConfig) TLSClientConfig() (*tls.Config, error) {
+	result := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		ServerName:         c.ServerName,
+	}
+	if c.CAFile != "" {
+		data, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CA file %v, %v", c.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("failed to load CA file %v, no PEM certificates found", c.CAFile)
+		}
+		result.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %v, key %v, %v", c.CertFile, c.KeyFile, err)
+		}
+		result.Certificates = []tls.Certificate{certificate}
+	}
+	return result, nil
+}
+
+//HTTPClient returns http client with TLS configuration, client is created once
+func (c *TLSConfig) HTTPClient() (*http.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	tlsConfig, err := c.TLSClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.client = &http.Client{Transport: transport}
+	return c.client, nil
+}
+
+var defaultTLSConfig *TLSConfig
+var defaultTLSConfigMutex = &sync.RWMutex{}
+
+//SetDefaultTLSConfig sets TLS configuration used by resources without own TLSConfig, nil removes default
+func SetDefaultTLSConfig(config *TLSConfig) {
+	defaultTLSConfigMutex.Lock()
+	defer defaultTLSConfigMutex.Unlock()
+	defaultTLSConfig = config
+}
+
+//tlsConfig returns resource or default TLS configuration
+func (r *Resource) tlsConfig() *TLSConfig {
+	if r.TLSConfig != nil {
+		return r.TLSConfig
+	}
+	defaultTLSConfigMutex.RLock()
+	defer defaultTLSConfigMutex.RUnlock()
+	return defaultTLSConfig
+}
@@ -0,0 +1,134 @@
+package url
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+//maxWatchBackoffShift represents max watch interval multiplier exponent applied on consecutive errors (interval * 32)
+const maxWatchBackoffShift = 5
+
+//modificationState represents resource modification validators
+type modificationState struct {
+	modTime time.Time
+	etag    string
+	size    int64
+}
+
+//isKnown returns true if state carries any modification validator
+func (s *modificationState) isKnown() bool {
+	return !s.modTime.IsZero() || s.etag != ""
+}
+
+//equals returns true if both states represent the same resource version
+func (s *modificationState) equals(other *modificationState) bool {
+	return s.modTime.Equal(other.modTime) && s.etag == other.etag && s.size == other.size
+}
+
+//modificationState returns modification validators from resource Stat info, not found resource returns an error
+func (r *Resource) modificationState(ctx context.Context) (*modificationState, error) {
+	info, err := r.stat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Exists {
+		return nil, fmt.Errorf("resource not found: %v", r.URL)
+	}
+	return &modificationState{modTime: info.ModTime, etag: info.ETag, size: info.Size}, nil
+}
+
+//Modified returns true if resource was modified after since time, http and https resources use HEAD Last-Modified,
+//ETag different than the one seen by previous call is reported as modification, resources without validators are reported as modified
+func (r *Resource) Modified(since time.Time) (bool, error) {
+	if r == nil {
+		return false, fmt.Errorf("fail to check modification on empty resource")
+	}
+	if IsDataURL(r.URL) {
+		return false, nil
+	}
+	state, err := r.modificationState(context.Background())
+	if err != nil {
+		return false, err
+	}
+	if state.etag != "" {
+		previous := r.etag
+		r.etag = state.etag
+		if previous != "" {
+			return previous != state.etag, nil
+		}
+	}
+	if !state.modTime.IsZero() {
+		return state.modTime.After(since), nil
+	}
+	return true, nil
+}
+
+//Watch polls resource every interval and invokes onChange with downloaded content once resource changed, content is downloaded only if
+//modification validators changed, resources without validators are compared by content digest, consecutive errors back off the interval up to 32 times,
+//cached content is bypassed and refreshed on change, it blocks until context is done and returns nil then
+func (r *Resource) Watch(ctx context.Context, interval time.Duration, onChange func(data []byte)) error {
+	if r == nil {
+		return fmt.Errorf("fail to watch empty resource")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid watch interval: %v", interval)
+	}
+	if onChange == nil {
+		return fmt.Errorf("onChange was nil")
+	}
+	source := r.Clone()
+	source.Cache = ""
+	var previous *modificationState
+	var digest []byte
+	var poll = func() error {
+		state, err := r.modificationState(ctx)
+		if err != nil {
+			return err
+		}
+		if previous != nil && state.isKnown() && previous.equals(state) {
+			return nil
+		}
+		if previous == nil && state.isKnown() {
+			previous = state
+			return nil
+		}
+		data, err := source.DownloadWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		recentDigest := sha256.Sum256(data)
+		initial := previous == nil
+		previous = state
+		if initial || bytes.Equal(digest, recentDigest[:]) {
+			digest = recentDigest[:]
+			return nil
+		}
+		digest = recentDigest[:]
+		if r.Cachable() {
+			_ = ioutil.WriteFile(r.Cache, data, 0666)
+		}
+		onChange(data)
+		return nil
+	}
+	var failures uint
+	for {
+		delay := interval
+		if err := poll(); err != nil && ctx.Err() == nil {
+			if failures < maxWatchBackoffShift {
+				failures++
+			}
+			delay = interval << failures
+		} else {
+			failures = 0
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package url_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResource_Modified(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_modified")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+	filename := path.Join(parent, "config.json")
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"version":1}`), 0644))
+	modTime := time.Now().Add(-time.Hour)
+	assert.Nil(t, os.Chtimes(filename, modTime, modTime))
+
+	resource := url.NewResource(filename)
+	modified, err := resource.Modified(modTime.Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.True(t, modified)
+	modified, err = resource.Modified(modTime.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.False(t, modified)
+
+	var etag atomic.Value
+	etag.Store(`"v1"`)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("ETag", etag.Load().(string))
+		_, _ = writer.Write([]byte(etag.Load().(string)))
+	}))
+	defer server.Close()
+	resource = url.NewResource(server.URL + "/config.json")
+	modified, err = resource.Modified(time.Now())
+	assert.Nil(t, err)
+	assert.True(t, modified, "unknown ETag")
+	modified, err = resource.Modified(time.Now())
+	assert.Nil(t, err)
+	assert.False(t, modified, "same ETag")
+	etag.Store(`"v2"`)
+	modified, err = resource.Modified(time.Now())
+	assert.Nil(t, err)
+	assert.True(t, modified, "flipped ETag")
+
+	_, err = url.NewResource(path.Join(parent, "missing.json")).Modified(time.Now())
+	assert.NotNil(t, err)
+}
+
+func TestResource_Watch(t *testing.T) {
+	parent := path.Join(os.TempDir(), "resource_watch")
+	_ = os.MkdirAll(parent, 0755)
+	defer os.RemoveAll(parent)
+
+	{ //file rewritten
+		filename := path.Join(parent, "config.json")
+		assert.Nil(t, ioutil.WriteFile(filename, []byte(`{"version":1}`), 0644))
+		changes := make(chan string, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		var waitGroup sync.WaitGroup
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			assert.Nil(t, url.NewResource(filename).Watch(ctx, 10*time.Millisecond, func(data []byte) {
+				changes <- string(data)
+			}))
+		}()
+		time.Sleep(50 * time.Millisecond)
+		for i := 2; i <= 3; i++ {
+			content := fmt.Sprintf(`{"version":%v}`, i)
+			assert.Nil(t, ioutil.WriteFile(filename, []byte(content), 0644))
+			modTime := time.Now().Add(time.Duration(i) * time.Second)
+			assert.Nil(t, os.Chtimes(filename, modTime, modTime))
+			select {
+			case actual := <-changes:
+				assert.Equal(t, content, actual)
+			case <-time.After(2 * time.Second):
+				assert.True(t, false, "change was not detected", content)
+			}
+		}
+		cancel()
+		waitGroup.Wait()
+		assert.Equal(t, 0, len(changes))
+	}
+
+	{ //http ETag flipping, only changed content is downloaded
+		var etag atomic.Value
+		etag.Store(`"v1"`)
+		var downloads, heads int32
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			value := etag.Load().(string)
+			writer.Header().Set("ETag", value)
+			if request.Method == http.MethodHead {
+				atomic.AddInt32(&heads, 1)
+				return
+			}
+			atomic.AddInt32(&downloads, 1)
+			_, _ = writer.Write([]byte(value))
+		}))
+		defer server.Close()
+		changes := make(chan string, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- url.NewResource(server.URL+"/config.json").Watch(ctx, 10*time.Millisecond, func(data []byte) {
+				changes <- string(data)
+			})
+		}()
+		time.Sleep(50 * time.Millisecond)
+		assert.EqualValues(t, 0, atomic.LoadInt32(&downloads))
+		etag.Store(`"v2"`)
+		select {
+		case actual := <-changes:
+			assert.Equal(t, `"v2"`, actual)
+		case <-time.After(2 * time.Second):
+			assert.True(t, false, "ETag change was not detected")
+		}
+		time.Sleep(50 * time.Millisecond)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&downloads))
+		assert.True(t, atomic.LoadInt32(&heads) > 2)
+		cancel()
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+		case <-time.After(time.Second):
+			assert.True(t, false, "watch did not stop on cancellation")
+		}
+	}
+
+	{ //consecutive errors back off
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			writer.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		err := url.NewResource(server.URL+"/config.json").Watch(ctx, 10*time.Millisecond, func(data []byte) {})
+		assert.Nil(t, err)
+		//without backoff about 30 requests would be sent: 10, 20, 40, 80, 160 ms delays
+		assert.True(t, atomic.LoadInt32(&requests) <= 7, fmt.Sprintf("requests: %v", requests))
+	}
+
+	err := url.NewResource(path.Join(parent, "config.json")).Watch(context.Background(), 0, func(data []byte) {})
+	assert.NotNil(t, err)
+}